@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +14,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/rafaelzasas/vtasker/backend/internal/api"
+	"github.com/rafaelzasas/vtasker/backend/internal/services/mail"
+	"github.com/rafaelzasas/vtasker/backend/internal/services/storage"
+	"github.com/rafaelzasas/vtasker/backend/internal/services/webhooks"
 )
 
 func main() {
@@ -62,17 +66,70 @@ func main() {
 		"Authorization",
 		"Accept",
 		"Cache-Control",
+		"Last-Event-ID",
 	}
 	configCors.ExposeHeaders = []string{
 		"Content-Length",
 		"Content-Type",
+		"Content-Disposition",
+		"ETag",
 	}
 	configCors.AllowCredentials = true
 	configCors.MaxAge = 12 * time.Hour
 	router.Use(cors.New(configCors))
 
+	// Store attachments in the configured blob store
+	blobStore, err := storage.NewBlobStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Error configuring storage: %v", err)
+	}
+	maxUploadMB, err := envInt("ATTACHMENT_MAX_UPLOAD_MB", 25)
+	if err != nil {
+		log.Fatal(err)
+	}
+	boardQuotaMB, err := envInt("ATTACHMENT_BOARD_QUOTA_MB", 1024)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Setup routes
-	api.SetupRoutes(router, pool)
+	api.SetupRoutes(router, pool, configCors.AllowOrigins, api.AttachmentConfig{
+		Store:             blobStore,
+		MaxUploadBytes:    int64(maxUploadMB) << 20,
+		DefaultQuotaBytes: int64(boardQuotaMB) << 20,
+	})
+
+	// Start delivering webhooks from the outbox
+	dispatchCtx, stopDispatcher := context.WithCancel(context.Background())
+	defer stopDispatcher()
+	go webhooks.NewDispatcher(pool).Run(dispatchCtx)
+
+	// Start emailing notifications and daily digests
+	mailer, err := mail.NewMailerFromEnv()
+	if err != nil {
+		log.Fatalf("Error configuring mail: %v", err)
+	}
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000" // Default to development port
+	}
+	digestHour := 8
+	if digestHourStr := os.Getenv("DIGEST_HOUR"); digestHourStr != "" {
+		digestHour, err = strconv.Atoi(digestHourStr)
+		if err != nil || digestHour < 0 || digestHour > 23 {
+			log.Fatalf("DIGEST_HOUR must be an hour from 0 to 23")
+		}
+	}
+	go mail.NewNotifier(pool, mailer, mail.NotifierConfig{
+		AppURL:     appURL,
+		DigestHour: digestHour,
+	}).Run(dispatchCtx)
+
+	// Start removing the blobs of deleted attachments
+	go storage.NewSweeper(pool, blobStore).Run(dispatchCtx)
+
+	// Start generating thumbnails for image attachments
+	go storage.NewThumbnailer(pool, blobStore).Run(dispatchCtx)
 
 	// Get port from environment variable or use default
 	port := os.Getenv("PORT")
@@ -84,4 +141,18 @@ func main() {
 	if err := router.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-} 
\ No newline at end of file
+}
+
+// envInt reads a positive integer from an environment variable, falling back
+// to def when it isn't set
+func envInt(name string, def int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, nil
+}
@@ -1,4 +1,4 @@
--- Seed task statuses
+-- Seed task statuses. The codes match migration 000003 and the frontend.
 INSERT INTO
     task_statuses (
         code,
@@ -12,50 +12,34 @@ VALUES
     (
         'backlog',
         'Backlog',
-        'Tasks that are planned but not yet ready to be worked on',
-        '#E2E8F0',
+        'Tasks that are not yet started',
+        '#6B7280',
         'inbox',
-        0
-    ),
-    (
-        'todo',
-        'To Do',
-        'Tasks that are ready to be worked on',
-        '#F3F4F6',
-        'list',
         1
     ),
     (
-        'in_progress',
+        'in-progress',
         'In Progress',
         'Tasks that are currently being worked on',
-        '#DBEAFE',
+        '#3B82F6',
         'clock',
         2
     ),
     (
-        'in_review',
-        'In Review',
-        'Tasks that are being reviewed',
-        '#FEF3C7',
+        'review',
+        'Review',
+        'Tasks that are ready for review',
+        '#F59E0B',
         'eye',
         3
     ),
     (
         'done',
         'Done',
-        'Tasks that have been completed',
-        '#DCFCE7',
+        'Tasks that are completed',
+        '#10B981',
         'check-circle',
         4
-    ),
-    (
-        'blocked',
-        'Blocked',
-        'Tasks that are blocked by dependencies or other issues',
-        '#FEE2E2',
-        'x-circle',
-        5
     );
 
 -- Seed task priorities
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+type ActivityHandler struct {
+	repo      *repository.ActivityRepository
+	taskRepo  *repository.TaskRepository
+	boardRepo *repository.BoardRepository
+}
+
+func NewActivityHandler(pool *pgxpool.Pool) *ActivityHandler {
+	return &ActivityHandler{
+		repo:      repository.NewActivityRepository(pool),
+		taskRepo:  repository.NewTaskRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+	}
+}
+
+// ListBoardActivity returns a page of a board's activity feed
+func (h *ActivityHandler) ListBoardActivity(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		return
+	}
+
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.repo.ListBoardActivity(c.Request.Context(), board.ID, c.Query("cursor"), limit)
+	respondActivityPage(c, page, err)
+}
+
+// ListTaskActivity returns a page of a task's activity feed
+func (h *ActivityHandler) ListTaskActivity(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	page, err := h.repo.ListTaskActivity(c.Request.Context(), task.ID, c.Query("cursor"), limit)
+	respondActivityPage(c, page, err)
+}
+
+// activityUser returns the authenticated user's ID
+func activityUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// pageLimit reads the page size from the limit query parameter
+func pageLimit(c *gin.Context) (int, bool) {
+	limit := defaultPageLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxPageLimit)})
+			return 0, false
+		}
+	}
+	return limit, true
+}
+
+// respondActivityPage writes a page of activity or the error loading it
+func respondActivityPage(c *gin.Context, page *models.ActivityPage, err error) {
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Register registers all activity routes
+func (h *ActivityHandler) Register(router *gin.RouterGroup) {
+	router.GET("/boards/:id/activity", h.ListBoardActivity)
+	router.GET("/tasks/:id/activity", h.ListTaskActivity)
+}
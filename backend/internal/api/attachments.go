@@ -0,0 +1,504 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+	"github.com/rafaelzasas/vtasker/backend/internal/services/storage"
+)
+
+// multipartOverhead is the room left in an upload request for the multipart
+// framing around the file
+const multipartOverhead = 1 << 20
+
+// maxFilenameLength caps stored filenames, in characters, to the column's size
+const maxFilenameLength = 255
+
+// AttachmentConfig configures file attachments
+type AttachmentConfig struct {
+	Store storage.BlobStore
+	// MaxUploadBytes is the largest file that can be uploaded
+	MaxUploadBytes int64
+	// DefaultQuotaBytes is how much a board can store in attachments unless
+	// it's given its own quota
+	DefaultQuotaBytes int64
+}
+
+type AttachmentHandler struct {
+	repo      *repository.AttachmentRepository
+	taskRepo  *repository.TaskRepository
+	boardRepo *repository.BoardRepository
+	config    AttachmentConfig
+}
+
+func NewAttachmentHandler(pool *pgxpool.Pool, config AttachmentConfig) *AttachmentHandler {
+	return &AttachmentHandler{
+		repo:      repository.NewAttachmentRepository(pool, config.DefaultQuotaBytes),
+		taskRepo:  repository.NewTaskRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+		config:    config,
+	}
+}
+
+// ListAttachments returns the files attached to a task
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.repo.ListAttachments(c.Request.Context(), task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	linkThumbnails(c, attachments)
+	c.JSON(http.StatusOK, attachments)
+}
+
+// UploadAttachment stores a file sent as the "file" field of a multipart
+// form and attaches it to a task
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if !canEditTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to add attachments to this task"})
+		return
+	}
+
+	// Stop reading as soon as the body is bigger than any allowed upload
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.config.MaxUploadBytes+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.respondTooLarge(c)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file is required in the \"file\" form field"})
+		return
+	}
+	if fileHeader.Size > h.config.MaxUploadBytes {
+		h.respondTooLarge(c)
+		return
+	}
+	if fileHeader.Size == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is empty"})
+		return
+	}
+
+	// Check the quota before storing anything
+	if err := h.repo.CheckQuota(c.Request.Context(), task, userID, fileHeader.Size); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read file"})
+		return
+	}
+	defer file.Close()
+
+	// Hash the content and sniff its type rather than trusting the client's
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read file"})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contentType, err := mimetype.DetectReader(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read file"})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachment := &models.Attachment{
+		ID:          uuid.New(),
+		TaskID:      task.ID,
+		Filename:    cleanFilename(fileHeader.Filename),
+		ContentType: contentType.String(),
+		Size:        fileHeader.Size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+		UploadedBy:  &userID,
+	}
+	attachment.StorageKey = "attachments/" + task.ID.String() + "/" + attachment.ID.String()
+	attachment.ThumbnailStatus = models.ThumbnailNone
+	if storage.CanThumbnail(attachment.ContentType) {
+		attachment.ThumbnailStatus = models.ThumbnailPending
+	}
+
+	err = h.config.Store.Put(c.Request.Context(), attachment.StorageKey, file, storage.BlobInfo{
+		Size:        attachment.Size,
+		ContentType: attachment.ContentType,
+		SHA256:      attachment.SHA256,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error storing file: " + err.Error()})
+		return
+	}
+
+	if err := h.repo.CreateAttachment(c.Request.Context(), task, attachment); err != nil {
+		// The file was stored but not recorded, so remove it again
+		if deleteErr := h.config.Store.Delete(c.Request.Context(), attachment.StorageKey); deleteErr != nil {
+			log.Printf("Failed to remove unrecorded blob %s: %v", attachment.StorageKey, deleteErr)
+		}
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAttachment returns an attachment's metadata
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	attachment, ok := h.loadAttachment(c, task)
+	if !ok {
+		return
+	}
+
+	linkThumbnail(c, attachment)
+	c.JSON(http.StatusOK, attachment)
+}
+
+// DownloadAttachment streams an attachment's content. Anyone who can see the
+// task's board can download it. Files are always sent as downloads so
+// uploaded HTML can't run in the API's origin.
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	attachment, ok := h.loadAttachment(c, task)
+	if !ok {
+		return
+	}
+
+	etag := `"` + attachment.SHA256 + `"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body, err := h.config.Store.Get(c.Request.Context(), attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment content not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"ETag":                   etag,
+		"Cache-Control":          "private, max-age=3600",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// GetThumbnail serves an image attachment's thumbnail. Thumbnails are
+// generated in the background, so there's none until the attachment's
+// thumbnail_status is "ready".
+func (h *AttachmentHandler) GetThumbnail(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	attachment, ok := h.loadAttachment(c, task)
+	if !ok {
+		return
+	}
+
+	if attachment.ThumbnailStatus != models.ThumbnailReady || attachment.ThumbnailKey == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":            "Attachment has no thumbnail",
+			"thumbnail_status": attachment.ThumbnailStatus,
+		})
+		return
+	}
+
+	// Thumbnails are generated once, so the original's hash identifies them
+	etag := `"` + attachment.SHA256 + `-thumbnail"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body, err := h.config.Store.Get(c.Request.Context(), *attachment.ThumbnailKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Thumbnail content not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	// Thumbnails are images encoded by the server, so they're safe to show
+	// inline
+	c.DataFromReader(http.StatusOK, -1, *attachment.ThumbnailContentType, body, map[string]string{
+		"ETag":                   etag,
+		"Cache-Control":          "private, max-age=86400",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment removes an attachment. The uploader and task admins may
+// delete an attachment.
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	attachment, ok := h.loadAttachment(c, task)
+	if !ok {
+		return
+	}
+
+	isUploader := attachment.UploadedBy != nil && *attachment.UploadedBy == userID
+	if !isUploader && !canAdminTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to delete this attachment"})
+		return
+	}
+
+	if err := h.repo.DeleteAttachment(c.Request.Context(), task.ID, attachment.ID); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetUsage returns how much of its attachment quota a board has used
+func (h *AttachmentHandler) GetUsage(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage, err := h.repo.GetUsage(c.Request.Context(), board.ID)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// SetQuota gives a board its own attachment quota (super admin only)
+func (h *AttachmentHandler) SetQuota(c *gin.Context) {
+	currentUser, ok := c.MustGet("user").(*user.User)
+	if !ok || !currentUser.IsSuperAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only super admins can change attachment quotas"})
+		return
+	}
+
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		return
+	}
+
+	var input models.AttachmentQuotaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage, err := h.repo.SetQuota(c.Request.Context(), boardID, input.QuotaBytes)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// loadAttachment fetches the attachment named by the :attachment_id route
+// parameter from the given task
+func (h *AttachmentHandler) loadAttachment(c *gin.Context, task *models.Task) (*models.Attachment, bool) {
+	attachmentID, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return nil, false
+	}
+
+	attachment, err := h.repo.GetAttachment(c.Request.Context(), task.ID, attachmentID)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return nil, false
+	}
+
+	return attachment, true
+}
+
+// respondTooLarge rejects an upload over the size limit
+func (h *AttachmentHandler) respondTooLarge(c *gin.Context) {
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":     "file is larger than the upload limit",
+		"max_bytes": h.config.MaxUploadBytes,
+	})
+}
+
+// respondAttachmentError maps an attachment repository error to a response
+func respondAttachmentError(c *gin.Context, err error) {
+	var quotaErr *repository.AttachmentQuotaError
+	switch {
+	case errors.As(err, &quotaErr):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":       quotaErr.Error(),
+			"used_bytes":  quotaErr.UsedBytes,
+			"quota_bytes": quotaErr.QuotaBytes,
+			"size":        quotaErr.Size,
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// cleanFilename keeps the last element of a client-supplied filename and
+// drops control characters, so it's safe to store and echo back
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if runes := []rune(name); len(runes) > maxFilenameLength {
+		name = string(runes[:maxFilenameLength])
+	}
+	return name
+}
+
+// linkThumbnail sets the URL of an attachment's thumbnail once it's ready,
+// under the route group the request came through
+func linkThumbnail(c *gin.Context, attachment *models.Attachment) {
+	if attachment.ThumbnailStatus != models.ThumbnailReady {
+		return
+	}
+	attachment.ThumbnailURL = apiBasePath(c) + "/tasks/" + attachment.TaskID.String() + "/attachments/" + attachment.ID.String() + "/thumbnail"
+}
+
+// linkThumbnails sets the thumbnail URLs of a list of attachments
+func linkThumbnails(c *gin.Context, attachments []models.Attachment) {
+	for i := range attachments {
+		linkThumbnail(c, &attachments[i])
+	}
+}
+
+// linkTaskThumbnails sets the thumbnail URLs of tasks' attachments, and of
+// their embedded subtasks' attachments
+func linkTaskThumbnails(c *gin.Context, tasks ...*models.Task) {
+	for _, task := range tasks {
+		if task == nil {
+			continue
+		}
+		linkThumbnails(c, task.Attachments)
+		linkTaskThumbnails(c, task.Subtasks...)
+	}
+}
+
+// Register registers all attachment routes
+func (h *AttachmentHandler) Register(router *gin.RouterGroup) {
+	attachments := router.Group("/tasks/:id/attachments")
+	{
+		attachments.GET("", h.ListAttachments)
+		attachments.POST("", h.UploadAttachment)
+		attachments.GET("/:attachment_id", h.GetAttachment)
+		attachments.GET("/:attachment_id/download", h.DownloadAttachment)
+		attachments.GET("/:attachment_id/thumbnail", h.GetThumbnail)
+		attachments.DELETE("/:attachment_id", h.DeleteAttachment)
+	}
+
+	router.GET("/boards/:id/attachments/usage", h.GetUsage)
+	router.PUT("/boards/:id/attachments/quota", h.SetQuota)
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestLinkThumbnailFollowsRouteGroup(t *testing.T) {
+	taskID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	attachmentID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	tests := []struct {
+		name   string
+		legacy bool
+		status string
+		want   string
+	}{
+		{"v1", false, models.ThumbnailReady, "/api/v1/tasks/" + taskID.String() + "/attachments/" + attachmentID.String() + "/thumbnail"},
+		{"legacy", true, models.ThumbnailReady, "/api/tasks/" + taskID.String() + "/attachments/" + attachmentID.String() + "/thumbnail"},
+		{"not ready", false, models.ThumbnailPending, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.legacy {
+				c.Set(legacyAPIKey, true)
+			}
+
+			task := &models.Task{
+				ID:          taskID,
+				Attachments: []models.Attachment{{ID: attachmentID, TaskID: taskID, ThumbnailStatus: tt.status}},
+			}
+			linkTaskThumbnails(c, &models.Task{Subtasks: []*models.Task{task}})
+
+			if got := task.Attachments[0].ThumbnailURL; got != tt.want {
+				t.Errorf("thumbnail URL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
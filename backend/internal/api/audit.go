@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type AuditHandler struct {
+	repo *repository.AuditRepository
+}
+
+func NewAuditHandler(pool *pgxpool.Pool) *AuditHandler {
+	return &AuditHandler{
+		repo: repository.NewAuditRepository(pool),
+	}
+}
+
+// ListAuditLogs returns a page of the admin audit trail (super admin only)
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	currentUser, ok := c.MustGet("user").(*user.User)
+	if !ok || !currentUser.IsSuperAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only super admins can view the audit trail"})
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		return
+	}
+
+	filter := models.AuditFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+	}
+
+	if adminIDStr := c.Query("admin_id"); adminIDStr != "" {
+		adminID, err := uuid.Parse(adminIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid admin ID"})
+			return
+		}
+		filter.AdminID = &adminID
+	}
+
+	if entityIDStr := c.Query("entity_id"); entityIDStr != "" {
+		entityID, err := uuid.Parse(entityIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity ID"})
+			return
+		}
+		filter.EntityID = &entityID
+	}
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+			return
+		}
+		filter.From = &from
+	}
+
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+			return
+		}
+		filter.To = &to
+	}
+
+	page, err := h.repo.ListAuditLogs(c.Request.Context(), filter, c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Register registers all audit routes
+func (h *AuditHandler) Register(router *gin.RouterGroup) {
+	router.GET("/admin/audit", h.ListAuditLogs)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
+)
+
+func TestListAuditLogsRejectsBadRequests(t *testing.T) {
+	superAdmin := &user.User{Role: &user.UserRole{Code: user.RoleCodeSuperAdmin}}
+	admin := &user.User{Role: &user.UserRole{Code: user.RoleCodeAdmin}}
+
+	tests := []struct {
+		name   string
+		user   *user.User
+		query  string
+		status int
+	}{
+		{"admin", admin, "", http.StatusForbidden},
+		{"user without a role", &user.User{}, "", http.StatusForbidden},
+		{"bad admin ID", superAdmin, "admin_id=nope", http.StatusBadRequest},
+		{"bad entity ID", superAdmin, "entity_id=nope", http.StatusBadRequest},
+		{"bad from", superAdmin, "from=yesterday", http.StatusBadRequest},
+		{"bad to", superAdmin, "to=2025-03-14", http.StatusBadRequest},
+		{"bad limit", superAdmin, "limit=0", http.StatusBadRequest},
+	}
+
+	// The repository is never reached, so it has no pool
+	h := NewAuditHandler(nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/admin/audit?"+tt.query, nil)
+			c.Set("user", tt.user)
+
+			h.ListAuditLogs(c)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
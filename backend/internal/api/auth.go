@@ -7,7 +7,9 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
 	"github.com/rafaelzasas/vtasker/backend/internal/services"
 )
 
@@ -114,6 +116,15 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+
+		// Browsers can't set headers on a WebSocket handshake, so upgrades may
+		// pass the token as a query parameter instead
+		if authHeader == "" && websocket.IsWebSocketUpgrade(c.Request) {
+			if token := c.Query("access_token"); token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
+
 		if authHeader == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			return
@@ -147,6 +158,13 @@ func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID.String())
 		c.Set("user_email", claims.Email)
 		c.Set("user", user)
+
+		// Let repositories attribute activity to the user
+		c.Request = c.Request.WithContext(repository.WithRequestInfo(c.Request.Context(), repository.RequestInfo{
+			ActorID:   &claims.UserID,
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
 		c.Next()
 	}
 } 
\ No newline at end of file
@@ -9,19 +9,23 @@ import (
 	"github.com/rafaelzasas/vtasker/backend/internal/models"
 	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
 	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository/postgres"
 )
 
 type BoardHandler struct {
-	repo *repository.BoardRepository
+	repo     *repository.BoardRepository
+	userRepo repository.UserRepository
 }
 
 func NewBoardHandler(pool *pgxpool.Pool) *BoardHandler {
 	return &BoardHandler{
-		repo: repository.NewBoardRepository(pool),
+		repo:     repository.NewBoardRepository(pool),
+		userRepo: postgres.NewUserRepository(pool),
 	}
 }
 
-// ListBoards returns a list of boards accessible by the user
+// ListBoards returns a page of the boards accessible by the user, or with
+// list=all every board in the system (super admin only)
 func (h *BoardHandler) ListBoards(c *gin.Context) {
 	userIDStr := c.GetString("user_id")
 	if userIDStr == "" {
@@ -35,13 +39,32 @@ func (h *BoardHandler) ListBoards(c *gin.Context) {
 		return
 	}
 
-	boards, err := h.repo.ListBoards(c.Request.Context(), userID)
+	limit, ok := listLimit(c)
+	if !ok {
+		return
+	}
+
+	var page *models.BoardPage
+	if c.Query("list") == "all" {
+		currentUser, err := h.userRepo.GetByID(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !currentUser.IsSuperAdmin() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		page, err = h.repo.ListAllBoards(c.Request.Context(), c.Query("sort"), c.Query("cursor"), limit)
+	} else {
+		page, err = h.repo.ListBoards(c.Request.Context(), userID, c.Query("sort"), c.Query("cursor"), limit)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondListError(c, err, repository.BoardSortFields)
 		return
 	}
 
-	c.JSON(http.StatusOK, boards)
+	respondPage(c, page.Items, page.NextCursor)
 }
 
 // GetBoard returns a single board by ID
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type CollaboratorHandler struct {
+	taskRepo *repository.TaskRepository
+}
+
+func NewCollaboratorHandler(pool *pgxpool.Pool) *CollaboratorHandler {
+	return &CollaboratorHandler{
+		taskRepo: repository.NewTaskRepository(pool),
+	}
+}
+
+// ListCollaborators returns the collaborators on a task
+func (h *CollaboratorHandler) ListCollaborators(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, task.Collaborators)
+}
+
+// AddCollaborator gives a user a role on a task
+func (h *CollaboratorHandler) AddCollaborator(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input models.AddCollaboratorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if !canAdminTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to manage collaborators on this task"})
+		return
+	}
+
+	// Collaborators work on a single task, they don't get access to the board.
+	// A collaborator role only counts for members of the board.
+	if board != nil && board.RoleFor(input.UserID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User isn't a member of this task's board"})
+		return
+	}
+
+	collaborator, err := h.taskRepo.AddCollaborator(c.Request.Context(), task.ID, &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case errors.Is(err, repository.ErrCollaboratorExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, collaborator)
+}
+
+// UpdateCollaborator changes a collaborator's role on a task
+func (h *CollaboratorHandler) UpdateCollaborator(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	collaboratorID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid collaborator ID"})
+		return
+	}
+
+	var input models.UpdateCollaboratorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if !canAdminTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to manage collaborators on this task"})
+		return
+	}
+
+	collaborator, err := h.taskRepo.UpdateCollaborator(c.Request.Context(), task.ID, collaboratorID, input.Role)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collaborator not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborator)
+}
+
+// RemoveCollaborator removes a user from a task. Collaborators can always
+// remove themselves.
+func (h *CollaboratorHandler) RemoveCollaborator(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	collaboratorID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid collaborator ID"})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if collaboratorID != userID && !canAdminTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to manage collaborators on this task"})
+		return
+	}
+
+	if err := h.taskRepo.RemoveCollaborator(c.Request.Context(), task.ID, collaboratorID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collaborator not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Register registers all collaborator routes
+func (h *CollaboratorHandler) Register(router *gin.RouterGroup) {
+	collaborators := router.Group("/tasks/:id/collaborators")
+	{
+		collaborators.GET("", h.ListCollaborators)
+		collaborators.POST("", h.AddCollaborator)
+		collaborators.PUT("/:user_id", h.UpdateCollaborator)
+		collaborators.DELETE("/:user_id", h.RemoveCollaborator)
+	}
+}
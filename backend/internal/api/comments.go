@@ -0,0 +1,251 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type CommentHandler struct {
+	repo     *repository.CommentRepository
+	taskRepo *repository.TaskRepository
+}
+
+func NewCommentHandler(pool *pgxpool.Pool) *CommentHandler {
+	return &CommentHandler{
+		repo:     repository.NewCommentRepository(pool),
+		taskRepo: repository.NewTaskRepository(pool),
+	}
+}
+
+// ListComments returns the comments on a task with their replies
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	comments, err := h.repo.ListComments(c.Request.Context(), task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateComment adds a comment or a reply to a task
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input models.CreateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	comment, err := h.repo.CreateComment(c.Request.Context(), task.ID, &input, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Parent comment not found"})
+		case errors.Is(err, repository.ErrNestedReply):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment edits a comment. Only the author may edit a comment.
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input models.UpdateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.loadComment(c, task)
+	if !ok {
+		return
+	}
+
+	if comment.AuthorID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit this comment"})
+		return
+	}
+
+	updated, err := h.repo.UpdateComment(c.Request.Context(), comment.ID, &input, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteComment deletes a comment and its replies. The author and board
+// admins may delete a comment.
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.loadComment(c, task)
+	if !ok {
+		return
+	}
+
+	if comment.AuthorID != userID && !canAdminTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to delete this comment"})
+		return
+	}
+
+	if err := h.repo.DeleteComment(c.Request.Context(), comment.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListCommentHistory returns the previous revisions of a comment
+func (h *CommentHandler) ListCommentHistory(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.loadComment(c, task)
+	if !ok {
+		return
+	}
+
+	edits, err := h.repo.ListCommentEdits(c.Request.Context(), comment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, edits)
+}
+
+// loadComment fetches the comment named by the :comment_id route parameter
+// and makes sure it belongs to the given task
+func (h *CommentHandler) loadComment(c *gin.Context, task *models.Task) (*models.Comment, bool) {
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return nil, false
+	}
+
+	comment, err := h.repo.GetComment(c.Request.Context(), commentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if comment.TaskID != task.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// Register registers all comment routes
+func (h *CommentHandler) Register(router *gin.RouterGroup) {
+	comments := router.Group("/tasks/:id/comments")
+	{
+		comments.GET("", h.ListComments)
+		comments.POST("", h.CreateComment)
+		comments.PUT("/:comment_id", h.UpdateComment)
+		comments.DELETE("/:comment_id", h.DeleteComment)
+		comments.GET("/:comment_id/history", h.ListCommentHistory)
+	}
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type CriteriaHandler struct {
+	taskRepo *repository.TaskRepository
+}
+
+func NewCriteriaHandler(pool *pgxpool.Pool) *CriteriaHandler {
+	return &CriteriaHandler{
+		taskRepo: repository.NewTaskRepository(pool),
+	}
+}
+
+// CreateCriterion adds an acceptance criterion to the end of a task's list
+func (h *CriteriaHandler) CreateCriterion(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input models.CreateAcceptanceCriterionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(input.Description) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "description is required"})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if !canEditTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this task"})
+		return
+	}
+
+	criterion, err := h.taskRepo.CreateAcceptanceCriterion(c.Request.Context(), task.ID, &input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWithProgress(c, http.StatusCreated, task.ID, criterion)
+}
+
+// UpdateCriterion toggles, rewords or reorders an acceptance criterion
+func (h *CriteriaHandler) UpdateCriterion(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	criterionID, err := uuid.Parse(c.Param("criterion_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid criterion ID"})
+		return
+	}
+
+	var input models.UpdateAcceptanceCriterionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Description != nil && strings.TrimSpace(*input.Description) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "description can't be empty"})
+		return
+	}
+	input.ID = criterionID
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if !canEditTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this task"})
+		return
+	}
+
+	criterion, err := h.taskRepo.UpdateAcceptanceCriterion(c.Request.Context(), task.ID, &input, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Acceptance criterion not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWithProgress(c, http.StatusOK, task.ID, criterion)
+}
+
+// DeleteCriterion removes an acceptance criterion from a task
+func (h *CriteriaHandler) DeleteCriterion(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	criterionID, err := uuid.Parse(c.Param("criterion_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid criterion ID"})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if !canEditTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this task"})
+		return
+	}
+
+	if err := h.taskRepo.DeleteAcceptanceCriterion(c.Request.Context(), task.ID, criterionID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Acceptance criterion not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondWithProgress writes a criterion along with its task's recomputed progress
+func (h *CriteriaHandler) respondWithProgress(c *gin.Context, status int, taskID uuid.UUID, criterion *models.AcceptanceCriterion) {
+	progress, err := h.taskRepo.GetTaskProgress(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(status, models.AcceptanceCriterionResponse{
+		Criterion: *criterion,
+		Progress:  progress,
+	})
+}
+
+// Register registers all acceptance criteria routes
+func (h *CriteriaHandler) Register(router *gin.RouterGroup) {
+	criteria := router.Group("/tasks/:id/criteria")
+	{
+		criteria.POST("", h.CreateCriterion)
+		criteria.PATCH("/:criterion_id", h.UpdateCriterion)
+		criteria.DELETE("/:criterion_id", h.DeleteCriterion)
+	}
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+const (
+	defaultDependencyDepth = 3
+	maxDependencyDepth     = 10
+)
+
+type DependencyHandler struct {
+	taskRepo  *repository.TaskRepository
+	boardRepo *repository.BoardRepository
+}
+
+func NewDependencyHandler(pool *pgxpool.Pool) *DependencyHandler {
+	return &DependencyHandler{
+		taskRepo:  repository.NewTaskRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+	}
+}
+
+// GetDependencies returns the transitive dependency graph around a task
+func (h *DependencyHandler) GetDependencies(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	depth := defaultDependencyDepth
+	if depthStr := c.Query("depth"); depthStr != "" {
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil || depth < 1 || depth > maxDependencyDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "depth must be between 1 and " + strconv.Itoa(maxDependencyDepth)})
+			return
+		}
+	}
+
+	task, _, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	graph, err := h.taskRepo.GetDependencyGraph(c.Request.Context(), task.ID, depth, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// AddDependency adds a "blocked by" or "blocks" edge between two tasks
+func (h *DependencyHandler) AddDependency(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input models.CreateDependencyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	blockedID, blockingID, ok := input.Edge(task.ID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of blocked_by or blocks is required"})
+		return
+	}
+
+	// The task on the other end of the edge must be visible to the user too
+	otherID := blockingID
+	if otherID == task.ID {
+		otherID = blockedID
+	}
+	other, otherBoard, ok := h.loadRelatedTask(c, task, board, otherID, userID)
+	if !ok {
+		return
+	}
+
+	// Changing what blocks a task is an edit of the blocked task
+	blockedTask, blockedBoard := task, board
+	if blockedID != task.ID {
+		blockedTask, blockedBoard = other, otherBoard
+	}
+	if !canEditTask(blockedTask, blockedBoard, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to change this task's dependencies"})
+		return
+	}
+
+	dep, err := h.taskRepo.AddDependency(c.Request.Context(), blockedID, blockingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDependencyCycle) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dep)
+}
+
+// RemoveDependency removes the edge between a task and another task
+func (h *DependencyHandler) RemoveDependency(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	otherID, err := uuid.Parse(c.Param("dependency_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dependency ID"})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	dep, err := h.taskRepo.GetDependencyBetween(c.Request.Context(), task.ID, otherID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// As when adding it, removing a blocker is an edit of the blocked task,
+	// whichever end of the edge the request names
+	blockedTask, blockedBoard := task, board
+	if dep.TaskID != task.ID {
+		blockedTask, blockedBoard, ok = h.loadRelatedTask(c, task, board, dep.TaskID, userID)
+		if !ok {
+			return
+		}
+	}
+	if !canEditTask(blockedTask, blockedBoard, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to change this task's dependencies"})
+		return
+	}
+
+	if err := h.taskRepo.RemoveDependency(c.Request.Context(), dep.TaskID, dep.DependencyID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadRelatedTask loads the task on the other end of a dependency edge and
+// its board, checking the user can see the board. task and board are the
+// task named in the route, whose board is reused when both share it.
+func (h *DependencyHandler) loadRelatedTask(c *gin.Context, task *models.Task, board *models.Board, otherID uuid.UUID, userID uuid.UUID) (*models.Task, *models.Board, bool) {
+	other, err := h.taskRepo.GetTask(c.Request.Context(), otherID.String())
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Related task not found"})
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	otherBoard := board
+	if other.BoardID != nil && (task.BoardID == nil || *other.BoardID != *task.BoardID) {
+		otherBoard, err = h.boardRepo.GetBoard(c.Request.Context(), other.BoardID.String(), userID)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to the related task's board"})
+			return nil, nil, false
+		}
+	}
+
+	return other, otherBoard, true
+}
+
+// Register registers all dependency routes
+func (h *DependencyHandler) Register(router *gin.RouterGroup) {
+	dependencies := router.Group("/tasks/:id/dependencies")
+	{
+		dependencies.GET("", h.GetDependencies)
+		dependencies.POST("", h.AddDependency)
+		dependencies.DELETE("/:dependency_id", h.RemoveDependency)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type DoneGateHandler struct {
+	repo      *repository.DoneGateRepository
+	boardRepo *repository.BoardRepository
+}
+
+func NewDoneGateHandler(pool *pgxpool.Pool) *DoneGateHandler {
+	return &DoneGateHandler{
+		repo:      repository.NewDoneGateRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+	}
+}
+
+// GetBoardGate returns the definition of done that applies to a board
+func (h *DoneGateHandler) GetBoardGate(c *gin.Context) {
+	board, ok := h.loadBoard(c, false)
+	if !ok {
+		return
+	}
+
+	gate, err := h.repo.GetGate(c.Request.Context(), &board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gate)
+}
+
+// SetBoardGate configures a board's own definition of done
+func (h *DoneGateHandler) SetBoardGate(c *gin.Context) {
+	var input models.DoneGateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	gate, err := h.repo.SetGate(c.Request.Context(), &board.ID, &input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gate)
+}
+
+// DeleteBoardGate removes a board's own gate so the global gate applies again
+func (h *DoneGateHandler) DeleteBoardGate(c *gin.Context) {
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteBoardGate(c.Request.Context(), board.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board has no done gate of its own"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListOverrides returns the recorded gate overrides on a board
+func (h *DoneGateHandler) ListOverrides(c *gin.Context) {
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.repo.ListOverrides(c.Request.Context(), board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// GetGlobalGate returns the definition of done for boards without their own gate
+func (h *DoneGateHandler) GetGlobalGate(c *gin.Context) {
+	gate, err := h.repo.GetGate(c.Request.Context(), nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gate)
+}
+
+// SetGlobalGate configures the definition of done for boards without their own gate
+func (h *DoneGateHandler) SetGlobalGate(c *gin.Context) {
+	currentUser, ok := c.MustGet("user").(*user.User)
+	if !ok || !currentUser.IsSuperAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only super admins can change the global done gate"})
+		return
+	}
+
+	var input models.DoneGateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	gate, err := h.repo.SetGate(c.Request.Context(), nil, &input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gate)
+}
+
+// loadBoard fetches the board named by the :id route parameter, optionally
+// requiring the user to be a board admin
+func (h *DoneGateHandler) loadBoard(c *gin.Context, requireAdmin bool) (*models.Board, bool) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return nil, false
+	}
+
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if requireAdmin && !board.CanUserAdmin(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only board admins can manage the done gate"})
+		return nil, false
+	}
+
+	return board, true
+}
+
+// Register registers all done gate routes
+func (h *DoneGateHandler) Register(router *gin.RouterGroup) {
+	boardGate := router.Group("/boards/:id/done-gate")
+	{
+		boardGate.GET("", h.GetBoardGate)
+		boardGate.PUT("", h.SetBoardGate)
+		boardGate.DELETE("", h.DeleteBoardGate)
+		boardGate.GET("/overrides", h.ListOverrides)
+	}
+
+	router.GET("/done-gate", h.GetGlobalGate)
+	router.PUT("/done-gate", h.SetGlobalGate)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"runtime/debug"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,9 +24,10 @@ type ErrorDetails struct {
 // DetailedErrorLogger middleware captures and logs detailed error information
 func DetailedErrorLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Read and store the request body
+		// Read and store the request body. File uploads are left alone so
+		// they're streamed rather than held in memory.
 		var bodyBytes []byte
-		if c.Request.Body != nil {
+		if c.Request.Body != nil && !strings.HasPrefix(c.ContentType(), "multipart/") {
 			bodyBytes, _ = io.ReadAll(c.Request.Body)
 			// Restore the request body for subsequent middleware/handlers
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
@@ -66,6 +68,10 @@ func DetailedErrorLogger() gin.HandlerFunc {
 	}
 }
 
+// maxLoggedBodySize caps how much of a response is kept for the error log,
+// so long-lived streams don't grow the buffer without bound
+const maxLoggedBodySize = 4096
+
 // bodyLogWriter is a custom response writer that captures the response body
 type bodyLogWriter struct {
 	gin.ResponseWriter
@@ -73,6 +79,12 @@ type bodyLogWriter struct {
 }
 
 func (w *bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	if room := maxLoggedBodySize - w.body.Len(); room > 0 {
+		if len(b) > room {
+			w.body.Write(b[:room])
+		} else {
+			w.body.Write(b)
+		}
+	}
 	return w.ResponseWriter.Write(b)
 }
\ No newline at end of file
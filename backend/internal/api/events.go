@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+	"github.com/rafaelzasas/vtasker/backend/internal/services/events"
+)
+
+const (
+	// eventBatchSize is how many events are read from the feed at a time
+	eventBatchSize = 100
+
+	// eventHeartbeat keeps idle streams from being closed by proxies
+	eventHeartbeat = 25 * time.Second
+
+	// eventRetry tells clients how long to wait before reconnecting, in ms
+	eventRetry = 3000
+)
+
+type EventHandler struct {
+	repo      *repository.ActivityRepository
+	boardRepo *repository.BoardRepository
+	broker    *events.Broker
+}
+
+func NewEventHandler(pool *pgxpool.Pool, broker *events.Broker) *EventHandler {
+	return &EventHandler{
+		repo:      repository.NewActivityRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+		broker:    broker,
+	}
+}
+
+// StreamBoardEvents streams a board's activity as Server-Sent Events. A
+// client that reconnects with Last-Event-ID gets the events it missed.
+func (h *EventHandler) StreamBoardEvents(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	board, err := h.boardRepo.GetBoard(ctx, c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Subscribe before reading where to start so nothing written in between
+	// is missed
+	wake, unsubscribe := h.broker.Subscribe(events.ActivityChannel, board.ID)
+	defer unsubscribe()
+
+	after, err := h.resumeFrom(c, board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", eventRetry)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		// Send everything new since the last event, then wait for more
+		for {
+			activities, err := h.repo.ListBoardActivityAfter(ctx, board.ID, after, eventBatchSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", jsonString(err.Error()))
+					c.Writer.Flush()
+				}
+				return
+			}
+
+			recheck := false
+			for _, activity := range activities {
+				if err := writeActivityEvent(c, activity); err != nil {
+					return
+				}
+				if activity.BoardSeq != nil {
+					after = *activity.BoardSeq
+				}
+				if activity.EntityType == models.ActivityEntityBoard {
+					recheck = true
+				}
+			}
+			c.Writer.Flush()
+
+			// Board changes can take away the user's access
+			if recheck && !h.canStillView(c, board.ID, userID) {
+				return
+			}
+
+			if len(activities) < eventBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+			// A deleted board's events lose their board ID, so a wake-up
+			// can come with nothing to send
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+			if !h.canStillView(c, board.ID, userID) {
+				return
+			}
+		}
+	}
+}
+
+// resumeFrom returns the board sequence number to stream after: that of the
+// Last-Event-ID the client sent if it's on this board, otherwise the newest
+// event's so only new activity is sent
+func (h *EventHandler) resumeFrom(c *gin.Context, boardID uuid.UUID) (int64, error) {
+	ctx := c.Request.Context()
+	if lastEventID, err := uuid.Parse(c.GetHeader("Last-Event-ID")); err == nil {
+		seq, err := h.repo.BoardActivitySeq(ctx, boardID, lastEventID)
+		if err != nil {
+			return 0, err
+		}
+		if seq != nil {
+			return *seq, nil
+		}
+	}
+	return h.repo.LatestBoardActivitySeq(ctx, boardID)
+}
+
+// canStillView checks the user can still see the board with the same rules
+// as GetBoard
+func (h *EventHandler) canStillView(c *gin.Context, boardID uuid.UUID, userID uuid.UUID) bool {
+	_, err := h.boardRepo.GetBoard(c.Request.Context(), boardID.String(), userID)
+	return err == nil
+}
+
+// writeActivityEvent writes an activity as one SSE event named
+// "<entity_type>.<action>"
+func writeActivityEvent(c *gin.Context, activity models.Activity) error {
+	data, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", activity.ID, activityEventName(activity), data)
+	return err
+}
+
+// activityEventName names the SSE event for an activity. Task updates that
+// change the task's column, board or position are sent as task.moved.
+func activityEventName(activity models.Activity) string {
+	if activity.EntityType == models.ActivityEntityTask && activity.Action == models.ActivityUpdated {
+		for _, change := range activity.Changes {
+			switch change.Field {
+			case "status_id", "board_id", "order_index":
+				return models.ActivityEntityTask + ".moved"
+			}
+		}
+	}
+	return activity.EntityType + "." + activity.Action
+}
+
+// jsonString encodes s as a JSON string
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// Register registers all event routes
+func (h *EventHandler) Register(router *gin.RouterGroup) {
+	router.GET("/boards/:id/events", h.StreamBoardEvents)
+}
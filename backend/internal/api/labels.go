@@ -0,0 +1,338 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type LabelHandler struct {
+	repo      *repository.LabelRepository
+	boardRepo *repository.BoardRepository
+	taskRepo  *repository.TaskRepository
+}
+
+func NewLabelHandler(pool *pgxpool.Pool) *LabelHandler {
+	return &LabelHandler{
+		repo:      repository.NewLabelRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+		taskRepo:  repository.NewTaskRepository(pool),
+	}
+}
+
+// ListLabels returns the labels defined on a board
+func (h *LabelHandler) ListLabels(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	labels, err := h.repo.ListBoardLabels(c.Request.Context(), board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
+
+// CreateLabel defines a new label on a board
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input models.CreateLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, ok := h.loadEditableBoard(c, userID)
+	if !ok {
+		return
+	}
+
+	label, err := h.repo.CreateLabel(c.Request.Context(), board.ID, &input)
+	if err != nil {
+		if errors.Is(err, repository.ErrLabelNameExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+// UpdateLabel renames or recolors a board label
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input models.UpdateLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, ok := h.loadEditableBoard(c, userID)
+	if !ok {
+		return
+	}
+
+	label, ok := h.loadBoardLabel(c, board)
+	if !ok {
+		return
+	}
+
+	updated, err := h.repo.UpdateLabel(c.Request.Context(), label.ID, &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		case errors.Is(err, repository.ErrLabelNameExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteLabel deletes a board label and detaches it from all tasks
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	board, ok := h.loadEditableBoard(c, userID)
+	if !ok {
+		return
+	}
+
+	label, ok := h.loadBoardLabel(c, board)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteLabel(c.Request.Context(), label.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AttachLabel attaches a board label to a task
+func (h *LabelHandler) AttachLabel(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input models.AttachLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if board == nil || !canEditTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to label this task"})
+		return
+	}
+
+	if err := h.repo.AddTaskLabel(c.Request.Context(), task, input.LabelID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		case errors.Is(err, repository.ErrLabelBoardMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	updatedTask, err := h.taskRepo.GetTask(c.Request.Context(), task.ID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	linkTaskThumbnails(c, updatedTask)
+	c.JSON(http.StatusOK, updatedTask)
+}
+
+// DetachLabel removes a label from a task
+func (h *LabelHandler) DetachLabel(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	labelID, err := uuid.Parse(c.Param("label_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid label ID"})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.taskRepo, userID)
+	if !ok {
+		return
+	}
+
+	if board == nil || !canEditTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to label this task"})
+		return
+	}
+
+	if err := h.repo.RemoveTaskLabel(c.Request.Context(), task.ID, labelID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Label is not attached to this task"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadEditableBoard fetches the board named by the :id route parameter and
+// checks that the user may change its settings
+func (h *LabelHandler) loadEditableBoard(c *gin.Context, userID uuid.UUID) (*models.Board, bool) {
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if !board.CanUserEdit(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to manage labels on this board"})
+		return nil, false
+	}
+
+	return board, true
+}
+
+// loadBoardLabel fetches the label named by the :label_id route parameter and
+// makes sure it is defined on the given board
+func (h *LabelHandler) loadBoardLabel(c *gin.Context, board *models.Board) (*models.Label, bool) {
+	labelID, err := uuid.Parse(c.Param("label_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid label ID"})
+		return nil, false
+	}
+
+	label, err := h.repo.GetLabel(c.Request.Context(), labelID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if label.BoardID != board.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		return nil, false
+	}
+
+	return label, true
+}
+
+// Register registers all label routes
+func (h *LabelHandler) Register(router *gin.RouterGroup) {
+	boardLabels := router.Group("/boards/:id/labels")
+	{
+		boardLabels.GET("", h.ListLabels)
+		boardLabels.POST("", h.CreateLabel)
+		boardLabels.PUT("/:label_id", h.UpdateLabel)
+		boardLabels.DELETE("/:label_id", h.DeleteLabel)
+	}
+
+	taskLabels := router.Group("/tasks/:id/labels")
+	{
+		taskLabels.POST("", h.AttachLabel)
+		taskLabels.DELETE("/:label_id", h.DetachLabel)
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestLabelColorBinding(t *testing.T) {
+	tests := []struct {
+		color       string
+		validCreate bool
+		validUpdate bool
+	}{
+		{"", true, true},
+		{`"#1F2937"`, true, true},
+		{`"#a1b2c3"`, true, true},
+		// Creating a label without a color gives it the default one
+		{`""`, true, false},
+		{`"#FFF"`, false, false},
+		{`"#1F2937FF"`, false, false},
+		{`"1F2937"`, false, false},
+		{`"#1G2937"`, false, false},
+		{`"red"`, false, false},
+	}
+
+	for _, tt := range tests {
+		create, update := `{"name":"bug"}`, `{}`
+		if tt.color != "" {
+			create = `{"name":"bug","color":` + tt.color + `}`
+			update = `{"color":` + tt.color + `}`
+		}
+
+		if err := bindJSON(create, &models.CreateLabelInput{}); (err == nil) != tt.validCreate {
+			t.Errorf("create label with color %s: error = %v, want valid %v", tt.color, err, tt.validCreate)
+		}
+		if err := bindJSON(update, &models.UpdateLabelInput{}); (err == nil) != tt.validUpdate {
+			t.Errorf("update label with color %s: error = %v, want valid %v", tt.color, err, tt.validUpdate)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type NotificationHandler struct {
+	repo *repository.NotificationRepository
+}
+
+func NewNotificationHandler(pool *pgxpool.Pool) *NotificationHandler {
+	return &NotificationHandler{
+		repo: repository.NewNotificationRepository(pool),
+	}
+}
+
+// ListNotifications returns a page of the user's notifications
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		return
+	}
+
+	unreadOnly := false
+	if unreadStr := c.Query("unread"); unreadStr != "" {
+		var err error
+		unreadOnly, err = strconv.ParseBool(unreadStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unread must be true or false"})
+			return
+		}
+	}
+
+	page, err := h.repo.ListNotifications(c.Request.Context(), userID, unreadOnly, c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetUnreadCount returns how many of the user's notifications are unread
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.repo.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// MarkRead marks one of the user's notifications as read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification ID"})
+		return
+	}
+
+	if err := h.repo.MarkRead(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	marked, err := h.repo.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// GetPreferences returns which notification types the user gets
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.repo.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences turns notification types, or emailing them, on or off
+// for the user
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	var input map[string]models.NotificationPreferenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for notificationType := range input {
+		if !models.IsValidNotificationType(notificationType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "unknown notification type: " + notificationType,
+				"types": models.NotificationTypes,
+			})
+			return
+		}
+	}
+
+	preferences, err := h.repo.UpdatePreferences(c.Request.Context(), userID, input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// Register registers all notification routes
+func (h *NotificationHandler) Register(router *gin.RouterGroup) {
+	notifications := router.Group("/notifications")
+	{
+		notifications.GET("", h.ListNotifications)
+		notifications.GET("/unread-count", h.GetUnreadCount)
+		notifications.POST("/read-all", h.MarkAllRead)
+		notifications.POST("/:id/read", h.MarkRead)
+		notifications.GET("/preferences", h.GetPreferences)
+		notifications.PUT("/preferences", h.UpdatePreferences)
+	}
+}
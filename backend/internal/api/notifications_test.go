@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestUpdatePreferencesRejectsBadInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"unknown type", `{"task_deleted":false}`, http.StatusBadRequest},
+		{"known and unknown types", `{"mentioned":false,"task_deleted":false}`, http.StatusBadRequest},
+		{"setting that isn't a boolean", `{"mentioned":"off"}`, http.StatusBadRequest},
+		{"not an object", `["mentioned"]`, http.StatusBadRequest},
+	}
+
+	// The repository is never reached, so it has no pool
+	h := NewNotificationHandler(nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/notifications/preferences", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", uuid.NewString())
+
+			h.UpdatePreferences(c)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+// legacyAPIKey marks requests made through the legacy /api routes
+const legacyAPIKey = "legacy_api"
+
+// LegacyAPI marks requests as made through the legacy /api routes, whose
+// list endpoints return bare arrays
+func LegacyAPI() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(legacyAPIKey, true)
+		c.Next()
+	}
+}
+
+// apiBasePath returns the path of the route group the request came through
+func apiBasePath(c *gin.Context) string {
+	if c.GetBool(legacyAPIKey) {
+		return "/api"
+	}
+	return "/api/v1"
+}
+
+// listLimit reads the page size of a list request. Legacy requests that
+// don't ask for a page get the whole list, as they always have, so the limit
+// is 0.
+func listLimit(c *gin.Context) (int, bool) {
+	if c.GetBool(legacyAPIKey) && c.Query("limit") == "" && c.Query("cursor") == "" {
+		return 0, true
+	}
+	return pageLimit(c)
+}
+
+// respondPage writes a page of a list. The next page is linked in the Link
+// header; v1 responses also carry its cursor next to the items, while legacy
+// responses are the bare array of items.
+func respondPage(c *gin.Context, items interface{}, nextCursor string) {
+	if nextCursor != "" {
+		query := c.Request.URL.Query()
+		query.Set("cursor", nextCursor)
+		next := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		c.Header("Link", "<"+next.String()+`>; rel="next"`)
+	}
+
+	if c.GetBool(legacyAPIKey) {
+		c.JSON(http.StatusOK, items)
+		return
+	}
+
+	response := gin.H{"items": items}
+	if nextCursor != "" {
+		response["next_cursor"] = nextCursor
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// respondListError writes the error loading a list, listing the fields it
+// can be sorted by when the sort was invalid
+func respondListError(c *gin.Context, err error, sortFields []string) {
+	switch {
+	case errors.Is(err, repository.ErrInvalidSort):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "invalid sort " + c.Query("sort"),
+			"fields": sortFields,
+		})
+	case errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+func newListContext(target string, legacy bool) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	if legacy {
+		c.Set(legacyAPIKey, true)
+	}
+	return c, w
+}
+
+func TestListLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		legacy bool
+		want   int
+		ok     bool
+	}{
+		{"legacy gets the whole list", "/api/tasks", true, 0, true},
+		{"legacy asking for a page", "/api/tasks?limit=10", true, 10, true},
+		{"legacy following a cursor", "/api/tasks?cursor=abc", true, defaultPageLimit, true},
+		{"v1 default", "/api/v1/tasks", false, defaultPageLimit, true},
+		{"v1 limit", "/api/v1/tasks?limit=25", false, 25, true},
+		{"v1 max", fmt.Sprintf("/api/v1/tasks?limit=%d", maxPageLimit), false, maxPageLimit, true},
+		{"over max", fmt.Sprintf("/api/v1/tasks?limit=%d", maxPageLimit+1), false, 0, false},
+		{"zero", "/api/v1/tasks?limit=0", false, 0, false},
+		{"not a number", "/api/tasks?limit=ten", true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newListContext(tt.target, tt.legacy)
+
+			got, ok := listLimit(c)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("listLimit() = %d, %v, want %d, %v", got, ok, tt.want, tt.ok)
+			}
+			if !tt.ok && w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestRespondPage(t *testing.T) {
+	items := []string{"a", "b"}
+
+	tests := []struct {
+		name       string
+		target     string
+		legacy     bool
+		nextCursor string
+		body       string
+		link       string
+	}{
+		{
+			name:   "legacy last page",
+			target: "/api/boards/1/activity",
+			legacy: true,
+			body:   `["a","b"]`,
+		},
+		{
+			name:       "legacy with next page",
+			target:     "/api/boards/1/activity?limit=2",
+			legacy:     true,
+			nextCursor: "abc",
+			body:       `["a","b"]`,
+			link:       `</api/boards/1/activity?cursor=abc&limit=2>; rel="next"`,
+		},
+		{
+			name:   "v1 last page",
+			target: "/api/v1/boards/1/activity",
+			body:   `{"items":["a","b"]}`,
+		},
+		{
+			name:       "v1 with next page",
+			target:     "/api/v1/boards/1/activity?cursor=old&sort=-name",
+			nextCursor: "new",
+			body:       `{"items":["a","b"],"next_cursor":"new"}`,
+			link:       `</api/v1/boards/1/activity?cursor=new&sort=-name>; rel="next"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newListContext(tt.target, tt.legacy)
+
+			respondPage(c, items, tt.nextCursor)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Body.String(); got != tt.body {
+				t.Errorf("body = %s, want %s", got, tt.body)
+			}
+			if got := w.Header().Get("Link"); got != tt.link {
+				t.Errorf("Link = %q, want %q", got, tt.link)
+			}
+		})
+	}
+}
+
+func TestRespondListError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		fields bool
+	}{
+		{"invalid cursor", fmt.Errorf("error listing tasks: %w", repository.ErrInvalidCursor), http.StatusBadRequest, false},
+		{"invalid sort", repository.ErrInvalidSort, http.StatusBadRequest, true},
+		{"other", errors.New("connection refused"), http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newListContext("/api/v1/tasks?sort=title", false)
+
+			respondListError(c, tt.err, []string{"name", "due_date"})
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON body: %v", err)
+			}
+			if _, ok := body["fields"]; ok != tt.fields {
+				t.Errorf("fields present = %v, want %v", ok, tt.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+	"github.com/rafaelzasas/vtasker/backend/internal/services/events"
+)
+
+const (
+	// presenceHeartbeat is how often a socket is pinged and its session
+	// marked as still connected
+	presenceHeartbeat = 30 * time.Second
+
+	// presenceStaleAfter is how long a session can go without a heartbeat
+	// before it's dropped from the board's presence
+	presenceStaleAfter = 3 * presenceHeartbeat
+
+	// editLockTTL is how long a soft lock lasts unless it's renewed
+	editLockTTL = 60 * time.Second
+
+	socketWriteWait = 10 * time.Second
+	socketReadLimit = 4096
+)
+
+type PresenceHandler struct {
+	repo      *repository.PresenceRepository
+	boardRepo *repository.BoardRepository
+	broker    *events.Broker
+	upgrader  websocket.Upgrader
+}
+
+// NewPresenceHandler creates a presence handler whose sockets can only be
+// opened from allowedOrigins, the same origins CORS allows
+func NewPresenceHandler(pool *pgxpool.Pool, broker *events.Broker, allowedOrigins []string) *PresenceHandler {
+	return &PresenceHandler{
+		repo:      repository.NewPresenceRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+		broker:    broker,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     originChecker(allowedOrigins),
+		},
+	}
+}
+
+// originChecker returns a socket origin check that only lets pages on
+// allowedOrigins open sockets. A socket can be authenticated with a token in
+// its URL, so without the check any site could open one as the user. Requests
+// without an Origin header don't come from a browser page and are allowed.
+func originChecker(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, allowed := range allowedOrigins {
+			allowed = strings.TrimSpace(allowed)
+			if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Connect upgrades to a WebSocket that shares who is viewing a board, which
+// task each of them has open, and the soft edit locks they hold
+func (h *PresenceHandler) Connect(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already responded
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	session, err := h.repo.CreateSession(ctx, board.ID, userID)
+	if err != nil {
+		closeSocket(conn, websocket.CloseInternalServerErr, "failed to join board")
+		return
+	}
+	defer func() {
+		endCtx, cancel := context.WithTimeout(context.Background(), socketWriteWait)
+		defer cancel()
+		if err := h.repo.EndSession(endCtx, session.ID); err != nil {
+			log.Printf("Failed to end board session %s: %v", session.ID, err)
+		}
+	}()
+
+	// Subscribe before the first presence read so no change is missed
+	wake, unsubscribe := h.broker.Subscribe(events.PresenceChannel, board.ID)
+	defer unsubscribe()
+
+	replies := make(chan gin.H, 16)
+	go h.readMessages(ctx, cancel, conn, session, replies)
+
+	if err := writeSocketJSON(conn, gin.H{"type": "welcome", "session_id": session.ID}); err != nil {
+		return
+	}
+	if !h.sendPresence(ctx, conn, board.ID) {
+		return
+	}
+
+	heartbeat := time.NewTicker(presenceHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			closeSocket(conn, websocket.CloseNormalClosure, "")
+			return
+		case reply := <-replies:
+			if err := writeSocketJSON(conn, reply); err != nil {
+				return
+			}
+		case <-wake:
+			if !h.sendPresence(ctx, conn, board.ID) {
+				return
+			}
+		case <-heartbeat.C:
+			if !h.heartbeat(ctx, conn, session, userID) {
+				return
+			}
+		}
+	}
+}
+
+// heartbeat keeps the session alive, clears out stale presence and checks
+// the user can still see the board. It returns false when the socket should
+// close.
+func (h *PresenceHandler) heartbeat(ctx context.Context, conn *websocket.Conn, session *models.BoardSession, userID uuid.UUID) bool {
+	if err := h.repo.TouchSession(ctx, session.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			closeSocket(conn, websocket.CloseGoingAway, "session expired")
+			return false
+		}
+		log.Printf("Failed to touch board session %s: %v", session.ID, err)
+	}
+
+	if err := h.repo.PruneBoard(ctx, session.BoardID, presenceStaleAfter); err != nil {
+		log.Printf("Failed to prune board presence: %v", err)
+	}
+
+	if _, err := h.boardRepo.GetBoard(ctx, session.BoardID.String(), userID); err != nil {
+		if err.Error() == "board not found" {
+			closeSocket(conn, websocket.ClosePolicyViolation, "board access revoked")
+			return false
+		}
+		log.Printf("Failed to check board access: %v", err)
+	}
+
+	err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteWait))
+	return err == nil
+}
+
+// sendPresence writes the board's current presence. It returns false when
+// the socket is gone.
+func (h *PresenceHandler) sendPresence(ctx context.Context, conn *websocket.Conn, boardID uuid.UUID) bool {
+	presence, err := h.repo.GetBoardPresence(ctx, boardID, presenceStaleAfter)
+	if err != nil {
+		log.Printf("Failed to get board presence: %v", err)
+		return true
+	}
+
+	err = writeSocketJSON(conn, gin.H{
+		"type":     "presence",
+		"board_id": presence.BoardID,
+		"sessions": presence.Sessions,
+		"locks":    presence.Locks,
+	})
+	return err == nil
+}
+
+// readMessages handles messages from the client until the socket closes,
+// then cancels the connection's context
+func (h *PresenceHandler) readMessages(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, session *models.BoardSession, replies chan<- gin.H) {
+	defer cancel()
+
+	conn.SetReadLimit(socketReadLimit)
+	conn.SetReadDeadline(time.Now().Add(presenceStaleAfter))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(presenceStaleAfter))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(presenceStaleAfter))
+
+		var reply gin.H
+		var msg models.PresenceClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			reply = gin.H{"type": "error", "error": "invalid message"}
+		} else {
+			reply = h.handleMessage(ctx, session, msg)
+		}
+		if reply == nil {
+			continue
+		}
+
+		select {
+		case replies <- reply:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleMessage applies a client message and returns the reply to send, if
+// any. Presence changes reach every socket through the presence channel.
+func (h *PresenceHandler) handleMessage(ctx context.Context, session *models.BoardSession, msg models.PresenceClientMessage) gin.H {
+	switch msg.Type {
+	case models.PresenceOpenTask:
+		if msg.TaskID == nil {
+			return gin.H{"type": "error", "error": "task_id is required"}
+		}
+		return presenceError(h.repo.SetSessionTask(ctx, session.ID, msg.TaskID))
+
+	case models.PresenceCloseTask:
+		return presenceError(h.repo.SetSessionTask(ctx, session.ID, nil))
+
+	case models.PresenceLock:
+		if msg.TaskID == nil || !models.IsValidLockField(msg.Field) {
+			return gin.H{"type": "error", "error": "task_id and a field of title or description are required"}
+		}
+		lock, acquired, err := h.repo.AcquireLock(ctx, session, *msg.TaskID, msg.Field, editLockTTL)
+		if err != nil {
+			return presenceError(err)
+		}
+		if !acquired {
+			// The lock is advisory: the client should warn before editing
+			// but isn't stopped from saving
+			return gin.H{"type": "lock_conflict", "lock": lock}
+		}
+		return gin.H{"type": "lock_acquired", "lock": lock}
+
+	case models.PresenceUnlock:
+		if msg.TaskID == nil || !models.IsValidLockField(msg.Field) {
+			return gin.H{"type": "error", "error": "task_id and a field of title or description are required"}
+		}
+		return presenceError(h.repo.ReleaseLock(ctx, session.ID, *msg.TaskID, msg.Field))
+
+	default:
+		return gin.H{"type": "error", "error": "unknown message type: " + msg.Type}
+	}
+}
+
+// presenceError turns a repository error into an error reply; nil stays nil
+func presenceError(err error) gin.H {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return gin.H{"type": "error", "error": "task not found on this board"}
+	}
+	log.Printf("Failed to handle presence message: %v", err)
+	return gin.H{"type": "error", "error": "internal error"}
+}
+
+// writeSocketJSON writes one JSON message to a socket
+func writeSocketJSON(conn *websocket.Conn, v any) error {
+	conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+	return conn.WriteJSON(v)
+}
+
+// closeSocket sends a close frame with a code and reason
+func closeSocket(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(socketWriteWait))
+}
+
+// Register registers all presence routes
+func (h *PresenceHandler) Register(router *gin.RouterGroup) {
+	router.GET("/boards/:id/ws", h.Connect)
+}
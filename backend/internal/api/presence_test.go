@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginChecker(t *testing.T) {
+	allowed := []string{"http://localhost:3000", " https://app.example.com/"}
+
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    bool
+	}{
+		{"allowed origin", allowed, "http://localhost:3000", true},
+		{"allowed origin listed with spaces and slash", allowed, "https://app.example.com", true},
+		{"case differs", allowed, "HTTPS://APP.EXAMPLE.COM", true},
+		{"other site", allowed, "https://evil.example", false},
+		{"other port", allowed, "http://localhost:3001", false},
+		{"scheme differs", allowed, "http://app.example.com", false},
+		{"suffix of an allowed origin", allowed, "https://app.example.com.evil.example", false},
+		{"null origin", allowed, "null", false},
+		{"no origin", allowed, "", true},
+		{"nothing allowed", nil, "http://localhost:3000", false},
+		{"any origin allowed", []string{"*"}, "https://evil.example", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/boards/1/presence", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := originChecker(tt.origins)(r); got != tt.want {
+				t.Errorf("originChecker(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
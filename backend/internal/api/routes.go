@@ -4,21 +4,40 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rafaelzasas/vtasker/backend/internal/services"
+	"github.com/rafaelzasas/vtasker/backend/internal/services/events"
 )
 
-// SetupRoutes configures all the routes for the API
-func SetupRoutes(router *gin.Engine, pool *pgxpool.Pool) {
+// SetupRoutes configures all the routes for the API. allowedOrigins are the
+// origins CORS allows, which are also the only ones that can open sockets.
+func SetupRoutes(router *gin.Engine, pool *pgxpool.Pool, allowedOrigins []string, attachments AttachmentConfig) {
 	// Add detailed error logging middleware
 	router.Use(DetailedErrorLogger())
 
 	// Create services
 	authService := services.NewAuthService(pool, "your-secret-key") // TODO: Get from env
+	eventBroker := events.NewBroker(pool)
 
 	// Create handlers
 	taskHandler := NewTaskHandler(pool)
+	commentHandler := NewCommentHandler(pool)
+	attachmentHandler := NewAttachmentHandler(pool, attachments)
+	searchHandler := NewSearchHandler(pool)
+	viewHandler := NewViewHandler(pool)
+	labelHandler := NewLabelHandler(pool)
+	dependencyHandler := NewDependencyHandler(pool)
+	collaboratorHandler := NewCollaboratorHandler(pool)
+	criteriaHandler := NewCriteriaHandler(pool)
+	doneGateHandler := NewDoneGateHandler(pool)
+	workflowHandler := NewWorkflowHandler(pool)
+	activityHandler := NewActivityHandler(pool)
+	webhookHandler := NewWebhookHandler(pool)
+	eventHandler := NewEventHandler(pool, eventBroker)
+	presenceHandler := NewPresenceHandler(pool, eventBroker, allowedOrigins)
+	notificationHandler := NewNotificationHandler(pool)
 	authHandler := NewAuthHandler(authService)
 	boardHandler := NewBoardHandler(pool)
 	userHandler := NewUserHandler(pool)
+	auditHandler := NewAuditHandler(pool)
 	healthHandler := NewHealthHandler(pool)
 
 	// Register health check routes
@@ -26,6 +45,7 @@ func SetupRoutes(router *gin.Engine, pool *pgxpool.Pool) {
 
 	// Legacy routes (for compatibility)
 	legacy := router.Group("/api")
+	legacy.Use(LegacyAPI())
 	{
 		// Auth routes
 		legacy.POST("/auth/register", authHandler.Register)
@@ -39,11 +59,59 @@ func SetupRoutes(router *gin.Engine, pool *pgxpool.Pool) {
 			// Task routes
 			taskHandler.Register(protected)
 
+			// Comment routes
+			commentHandler.Register(protected)
+
+			// Attachment routes
+			attachmentHandler.Register(protected)
+
+			// Search routes
+			searchHandler.Register(protected)
+
+			// Saved view routes
+			viewHandler.Register(protected)
+
+			// Label routes
+			labelHandler.Register(protected)
+
+			// Dependency routes
+			dependencyHandler.Register(protected)
+
+			// Collaborator routes
+			collaboratorHandler.Register(protected)
+
+			// Acceptance criteria routes
+			criteriaHandler.Register(protected)
+
+			// Done gate routes
+			doneGateHandler.Register(protected)
+
+			// Workflow routes
+			workflowHandler.Register(protected)
+
+			// Activity routes
+			activityHandler.Register(protected)
+
+			// Webhook routes
+			webhookHandler.Register(protected)
+
+			// Event routes
+			eventHandler.Register(protected)
+
+			// Presence routes
+			presenceHandler.Register(protected)
+
+			// Notification routes
+			notificationHandler.Register(protected)
+
 			// Board routes
 			boardHandler.Register(protected)
 
 			// User routes
 			userHandler.Register(protected)
+
+			// Audit routes
+			auditHandler.Register(protected)
 		}
 	}
 
@@ -65,11 +133,59 @@ func SetupRoutes(router *gin.Engine, pool *pgxpool.Pool) {
 			// Task routes
 			taskHandler.Register(protected)
 
+			// Comment routes
+			commentHandler.Register(protected)
+
+			// Attachment routes
+			attachmentHandler.Register(protected)
+
+			// Search routes
+			searchHandler.Register(protected)
+
+			// Saved view routes
+			viewHandler.Register(protected)
+
+			// Label routes
+			labelHandler.Register(protected)
+
+			// Dependency routes
+			dependencyHandler.Register(protected)
+
+			// Collaborator routes
+			collaboratorHandler.Register(protected)
+
+			// Acceptance criteria routes
+			criteriaHandler.Register(protected)
+
+			// Done gate routes
+			doneGateHandler.Register(protected)
+
+			// Workflow routes
+			workflowHandler.Register(protected)
+
+			// Activity routes
+			activityHandler.Register(protected)
+
+			// Webhook routes
+			webhookHandler.Register(protected)
+
+			// Event routes
+			eventHandler.Register(protected)
+
+			// Presence routes
+			presenceHandler.Register(protected)
+
+			// Notification routes
+			notificationHandler.Register(protected)
+
 			// Board routes
 			boardHandler.Register(protected)
 
 			// User routes
 			userHandler.Register(protected)
+
+			// Audit routes
+			auditHandler.Register(protected)
 		}
 	}
 }
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+// maxSearchQueryLength caps the length of a search query, in characters
+const maxSearchQueryLength = 256
+
+type SearchHandler struct {
+	repo *repository.SearchRepository
+}
+
+func NewSearchHandler(pool *pgxpool.Pool) *SearchHandler {
+	return &SearchHandler{
+		repo: repository.NewSearchRepository(pool),
+	}
+}
+
+// Search returns a page of the tasks matching the q query parameter, best
+// match first
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is too long"})
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		return
+	}
+
+	var boardID *uuid.UUID
+	if boardIDStr := c.Query("board_id"); boardIDStr != "" {
+		id, err := uuid.Parse(boardIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+			return
+		}
+		boardID = &id
+	}
+
+	page, err := h.repo.SearchTasks(c.Request.Context(), userID, query, boardID, c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Register registers all search routes
+func (h *SearchHandler) Register(router *gin.RouterGroup) {
+	router.GET("/search", h.Search)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+// parseTaskFields reads the fields and include query parameters, responding
+// with the problem if one is invalid
+func parseTaskFields(c *gin.Context) (repository.TaskFields, bool) {
+	fields, err := repository.ParseTaskFields(c.Query("fields"), c.Query("include"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"fields":  repository.TaskFieldNames,
+			"include": repository.TaskIncludes,
+		})
+		return repository.TaskFields{}, false
+	}
+	return fields, true
+}
+
+// sparseTasks returns tasks with only the keys fields returns. Without a
+// field list or includes, the tasks are returned as they are.
+func sparseTasks(tasks []*models.Task, fields repository.TaskFields) (interface{}, error) {
+	if !fields.IsSparse() && len(fields.Include) == 0 {
+		return tasks, nil
+	}
+
+	sparse := make([]map[string]json.RawMessage, len(tasks))
+	for i, task := range tasks {
+		var err error
+		if sparse[i], err = sparseTask(task, fields); err != nil {
+			return nil, err
+		}
+	}
+	return sparse, nil
+}
+
+// sparseTask returns a task's JSON with only the keys fields returns
+func sparseTask(task *models.Task, fields repository.TaskFields) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var full map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &full); err != nil {
+		return nil, err
+	}
+
+	sparse := make(map[string]json.RawMessage, len(full))
+	for key, value := range full {
+		if fields.Returns(key) {
+			sparse[key] = value
+		}
+	}
+
+	if content, ok := sparse["content"]; ok && !fields.ReturnsCriteria() {
+		var contentFields map[string]json.RawMessage
+		if err := json.Unmarshal(content, &contentFields); err != nil {
+			return nil, err
+		}
+		delete(contentFields, "acceptance_criteria")
+		if sparse["content"], err = json.Marshal(contentFields); err != nil {
+			return nil, err
+		}
+	}
+
+	// Subtasks have the same fields, and an empty list is still returned
+	if fields.Returns(repository.IncludeSubtasks) {
+		subtasks, err := sparseTasks(task.Subtasks, repository.TaskFields{Only: fields.Only})
+		if err != nil {
+			return nil, err
+		}
+		if task.Subtasks == nil {
+			subtasks = make([]interface{}, 0)
+		}
+		if sparse[repository.IncludeSubtasks], err = json.Marshal(subtasks); err != nil {
+			return nil, err
+		}
+	}
+
+	return sparse, nil
+}
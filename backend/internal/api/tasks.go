@@ -1,19 +1,23 @@
 package api
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rafaelzasas/vtasker/backend/internal/models"
 	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+	"github.com/rafaelzasas/vtasker/backend/internal/taskquery"
 )
 
+// maxTaskQueryLength caps the length of a task query, in characters
+const maxTaskQueryLength = 1000
+
 type TaskHandler struct {
 	repo *repository.TaskRepository
 }
@@ -42,6 +46,7 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 		Status:   models.StatusCode(c.Query("status")),
 		Priority: models.PriorityCode(c.Query("priority")),
 		Type:     models.TypeCode(c.Query("type")),
+		Label:    c.Query("label"),
 	}
 
 	// Parse board_id if provided
@@ -54,13 +59,73 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 		filters.BoardID = &boardID
 	}
 
-	tasks, err := h.repo.GetTasks(c.Request.Context(), filters, userID)
+	// Parse parent_id if provided
+	if parentIDStr := c.Query("parent_id"); parentIDStr != "" {
+		parentID, err := uuid.Parse(parentIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent ID"})
+			return
+		}
+		filters.ParentID = &parentID
+	}
+
+	// Parse the task query if provided
+	if q := c.Query("q"); q != "" {
+		query, ok := parseTaskQuery(c, q)
+		if !ok {
+			return
+		}
+		filters.Query = query
+	}
+
+	filters.Sort = c.Query("sort")
+	limit, ok := listLimit(c)
+	if !ok {
+		return
+	}
+	if filters.Fields, ok = parseTaskFields(c); !ok {
+		return
+	}
+
+	page, err := h.repo.ListTasks(c.Request.Context(), filters, userID, c.Query("cursor"), limit)
+	if err != nil {
+		respondListError(c, err, repository.TaskSortFields)
+		return
+	}
+
+	linkTaskThumbnails(c, page.Items...)
+	tasks, err := sparseTasks(page.Items, filters.Fields)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	respondPage(c, tasks, page.NextCursor)
+}
+
+// parseTaskQuery parses a task query, responding with where and why it's
+// invalid if it can't be parsed
+func parseTaskQuery(c *gin.Context, q string) (*taskquery.Query, bool) {
+	if utf8.RuneCountInString(q) > maxTaskQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is too long"})
+		return nil, false
+	}
+
+	query, err := taskquery.Parse(q)
+	if err != nil {
+		var parseErr *taskquery.ParseError
+		if errors.As(err, &parseErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":    "invalid query: " + parseErr.Message,
+				"position": parseErr.Pos,
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return query, true
 }
 
 // GetTask returns a single task by ID
@@ -77,27 +142,46 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.repo.GetTask(c.Request.Context(), c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	fields, ok := parseTaskFields(c)
+	if !ok {
 		return
 	}
-	if task == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	if !fields.IsSparse() && len(fields.Include) == 0 {
+		task, _, ok := loadTaskForUser(c, h.repo, userID)
+		if !ok {
+			return
+		}
+		linkTaskThumbnails(c, task)
+		c.JSON(http.StatusOK, task)
 		return
 	}
 
-	// Check if user has access to the board
-	if task.BoardID != nil {
-		boardRepo := repository.NewBoardRepository(h.repo.GetPool())
-		_, err := boardRepo.GetBoard(c.Request.Context(), task.BoardID.String(), userID)
-		if err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this task's board"})
+	// Only load what the fields and includes need
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	task, err := h.repo.GetTaskFields(c.Request.Context(), taskID, fields, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Tell a missing task apart from one on a board the user can't see
+			if _, _, ok := loadTaskForUser(c, h.repo, userID); ok {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			}
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	linkTaskThumbnails(c, task)
+	sparse, err := sparseTask(task, fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, task)
+	c.JSON(http.StatusOK, sparse)
 }
 
 // CreateTask creates a new task
@@ -132,10 +216,26 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 	task, err := h.repo.CreateTask(c.Request.Context(), &input, userID)
 	if err != nil {
+		var wipErr *repository.WIPLimitError
+		if errors.As(err, &wipErr) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":     wipErr.Error(),
+				"status_id": wipErr.StatusID,
+				"limit":     wipErr.Limit,
+				"count":     wipErr.Count,
+			})
+			return
+		}
+		if errors.Is(err, repository.ErrInvalidParent) || errors.Is(err, repository.ErrInvalidStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	h.setWIPLimitWarning(c, task)
+	linkTaskThumbnails(c, task)
 	c.JSON(http.StatusCreated, task)
 }
 
@@ -159,27 +259,95 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	// Check board access if board_id is being updated
-	if input.BoardID != nil {
+	current, board, ok := loadTaskForUser(c, h.repo, userID)
+	if !ok {
+		return
+	}
+
+	// Load the board the task is moving to, if it's moving
+	var target *models.Board
+	if input.BoardID != nil && (current.BoardID == nil || *current.BoardID != *input.BoardID) {
 		boardRepo := repository.NewBoardRepository(h.repo.GetPool())
-		_, err := boardRepo.GetBoard(c.Request.Context(), input.BoardID.String(), userID)
+		target, err = boardRepo.GetBoard(c.Request.Context(), input.BoardID.String(), userID)
 		if err != nil {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this board"})
 			return
 		}
 	}
 
+	if !authorizeTaskUpdate(c, current, board, target, userID) {
+		return
+	}
+
 	task, err := h.repo.UpdateTask(c.Request.Context(), c.Param("id"), &input, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondTaskUpdateError(c, err)
 		return
 	}
 
+	if input.StatusID != nil || input.BoardID != nil {
+		h.setWIPLimitWarning(c, task)
+	}
+	linkTaskThumbnails(c, task)
 	c.JSON(http.StatusOK, task)
 }
 
+// ListSubtasks returns the direct subtasks of a task
+func (h *TaskHandler) ListSubtasks(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	task, _, ok := loadTaskForUser(c, h.repo, userID)
+	if !ok {
+		return
+	}
+
+	subtasks, err := h.repo.GetTasks(c.Request.Context(), repository.TaskFilters{ParentID: &task.ID}, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if subtasks == nil {
+		subtasks = make([]*models.Task, 0)
+	}
+
+	linkTaskThumbnails(c, subtasks...)
+	c.JSON(http.StatusOK, subtasks)
+}
+
 // DeleteTask deletes a task
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	task, board, ok := loadTaskForUser(c, h.repo, userID)
+	if !ok {
+		return
+	}
+
+	if !canAdminTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to delete this task"})
+		return
+	}
+
 	if err := h.repo.DeleteTask(c.Request.Context(), c.Param("id")); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -187,9 +355,31 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// ListTaskStatuses returns all task statuses
+// ListTaskStatuses returns the global task statuses, or a board's workflow
+// statuses when board_id is given
 func (h *TaskHandler) ListTaskStatuses(c *gin.Context) {
-	statuses, err := h.repo.ListTaskStatuses(c.Request.Context())
+	var boardID *uuid.UUID
+	if boardIDStr := c.Query("board_id"); boardIDStr != "" {
+		userID, err := uuid.Parse(c.GetString("user_id"))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			return
+		}
+
+		boardRepo := repository.NewBoardRepository(h.repo.GetPool())
+		board, err := boardRepo.GetBoard(c.Request.Context(), boardIDStr, userID)
+		if err != nil {
+			if err.Error() == "board not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		boardID = &board.ID
+	}
+
+	statuses, err := h.repo.ListTaskStatuses(c.Request.Context(), boardID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -237,38 +427,32 @@ func (h *TaskHandler) MoveTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.repo.GetTask(c.Request.Context(), c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	task, board, ok := loadTaskForUser(c, h.repo, userID)
+	if !ok {
 		return
 	}
-	if task == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	if !authorizeTaskUpdate(c, task, board, nil, userID) {
 		return
 	}
 
-	// Check if user has access to the board
-	if task.BoardID != nil {
-		boardRepo := repository.NewBoardRepository(h.repo.GetPool())
-		_, err := boardRepo.GetBoard(c.Request.Context(), task.BoardID.String(), userID)
-		if err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this task's board"})
-			return
-		}
-	}
-
 	// Update task status and order
 	updateInput := models.UpdateTaskInput{
-		StatusID: &input.StatusID,
-		Order:    Int32PtrToIntPtr(&input.Order),
+		StatusID:         &input.StatusID,
+		Order:            Int32PtrToIntPtr(input.Order),
+		BeforeID:         input.BeforeID,
+		AfterID:          input.AfterID,
+		OverrideDoneGate: input.OverrideDoneGate,
+		OverrideReason:   input.OverrideReason,
 	}
 
 	updatedTask, err := h.repo.UpdateTask(c.Request.Context(), c.Param("id"), &updateInput, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondTaskUpdateError(c, err)
 		return
 	}
 
+	h.setWIPLimitWarning(c, updatedTask)
+	linkTaskThumbnails(c, updatedTask)
 	c.JSON(http.StatusOK, updatedTask)
 }
 
@@ -281,6 +465,7 @@ func (h *TaskHandler) Register(router *gin.RouterGroup) {
 		tasks.GET("/:id", h.GetTask)
 		tasks.PUT("/:id", h.UpdateTask)
 		tasks.PUT("/:id/move", h.MoveTask)
+		tasks.GET("/:id/subtasks", h.ListSubtasks)
 		tasks.DELETE("/:id", h.DeleteTask)
 	}
 
@@ -290,25 +475,116 @@ func (h *TaskHandler) Register(router *gin.RouterGroup) {
 	router.GET("/task-types", h.ListTaskTypes)
 }
 
-func (h *TaskHandler) logTaskAudit(ctx context.Context, event string, taskID string, payload interface{}) {
-	go func() {
-		details, err := json.Marshal(payload)
-		if err != nil {
-			log.Printf("Failed to marshal audit payload: %v", err)
-			details = []byte("{}")
+// loadTaskForUser fetches the task named by the :id route parameter and checks
+// that the user can access its board, the same way GetTask does. It writes
+// the error response itself and returns false when the request should stop.
+func loadTaskForUser(c *gin.Context, taskRepo *repository.TaskRepository, userID uuid.UUID) (*models.Task, *models.Board, bool) {
+	task, err := taskRepo.GetTask(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return nil, nil, false
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
 
-		auditEntry := models.AuditLog{
-			Action:     "task:" + event,
-			TaskID:     taskID,
-			Details:    string(details),
-			CreatedAt:  time.Now(),
+	var board *models.Board
+	if task.BoardID != nil {
+		boardRepo := repository.NewBoardRepository(taskRepo.GetPool())
+		board, err = boardRepo.GetBoard(c.Request.Context(), task.BoardID.String(), userID)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this task's board"})
+			return nil, nil, false
 		}
-		
-		if err := h.repo.CreateAuditLog(ctx, &auditEntry); err != nil {
-			log.Printf("Failed to write audit log: %v", err)
+	}
+
+	return task, board, true
+}
+
+// authorizeTaskUpdate checks that the user may edit a task on its current
+// board and, when target is the board the task is moving to, that they're at
+// least an editor there. Being able to see a public board isn't enough to
+// put tasks on it.
+func authorizeTaskUpdate(c *gin.Context, task *models.Task, board *models.Board, target *models.Board, userID uuid.UUID) bool {
+	if !canEditTask(task, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this task"})
+		return false
+	}
+	if target != nil && !target.RoleFor(userID).AtLeast(models.BoardRoleEditor) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to add tasks to this board"})
+		return false
+	}
+	return true
+}
+
+// respondTaskUpdateError maps an error from TaskRepository.UpdateTask to a response
+func respondTaskUpdateError(c *gin.Context, err error) {
+	var gateErr *repository.DoneGateError
+	var wipErr *repository.WIPLimitError
+	switch {
+	case errors.As(err, &wipErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     wipErr.Error(),
+			"status_id": wipErr.StatusID,
+			"limit":     wipErr.Limit,
+			"count":     wipErr.Count,
+		})
+	case errors.As(err, &gateErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":          gateErr.Error(),
+			"unmet_criteria": gateErr.Violation.UnmetCriteria,
+			"blocking_tasks": gateErr.Violation.BlockingTasks,
+		})
+	case errors.Is(err, repository.ErrDoneGateOverrideForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInvalidStatus), errors.Is(err, repository.ErrInvalidPosition):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrTransitionNotAllowed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInvalidParent):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrParentCycle):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this task"})
+	case err.Error() == "task not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// setWIPLimitWarning adds a warning header when a task's column is over its
+// soft WIP limit. Hard limits are enforced when the task is saved.
+func (h *TaskHandler) setWIPLimitWarning(c *gin.Context, task *models.Task) {
+	if task.BoardID == nil {
+		return
+	}
+
+	column, err := h.repo.GetBoardColumn(c.Request.Context(), *task.BoardID, task.StatusID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failed to check WIP limit: %v", err)
 		}
-	}()
+		return
+	}
+
+	if column.OverLimit {
+		c.Header("X-WIP-Limit-Warning", fmt.Sprintf("%s has %d tasks, over its WIP limit of %d", column.Name, column.TaskCount, *column.WIPLimit))
+	}
+}
+
+// canEditTask reports whether the user may change a task, combining task
+// ownership, collaborator roles and the user's role on the task's board,
+// which is nil for tasks without one
+func canEditTask(task *models.Task, board *models.Board, userID uuid.UUID) bool {
+	return task.CanUserEdit(userID, board.RoleFor(userID))
+}
+
+// canAdminTask reports whether the user may delete a task or manage who can work on it
+func canAdminTask(task *models.Task, board *models.Board, userID uuid.UUID) bool {
+	return task.CanUserAdmin(userID, board.RoleFor(userID))
 }
 
 func Int32PtrToIntPtr(i *int32) *int {
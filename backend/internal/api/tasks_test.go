@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+func TestAuthorizeTaskUpdate(t *testing.T) {
+	userID, ownerID := uuid.New(), uuid.New()
+	board := func(public bool, role models.BoardRole) *models.Board {
+		b := &models.Board{ID: uuid.New(), OwnerID: &ownerID, IsPublic: public}
+		if role != "" {
+			b.Members = []models.BoardMember{{BoardID: b.ID, UserID: userID, Role: role}}
+		}
+		return b
+	}
+	taskOn := func(b *models.Board, owner *uuid.UUID) *models.Task {
+		task := &models.Task{ID: uuid.New(), OwnerID: owner}
+		if b != nil {
+			task.BoardID = &b.ID
+		}
+		return task
+	}
+
+	editorBoard := board(false, models.BoardRoleEditor)
+	viewerBoard := board(false, models.BoardRoleViewer)
+	removedFrom := board(true, "")
+
+	tests := []struct {
+		name   string
+		task   *models.Task
+		board  *models.Board
+		target *models.Board
+		status int
+	}{
+		{"editor on its board", taskOn(editorBoard, nil), editorBoard, nil, http.StatusOK},
+		{"editor moving to another board they edit", taskOn(editorBoard, nil), editorBoard, board(false, models.BoardRoleAdmin), http.StatusOK},
+		{"viewer on its board", taskOn(viewerBoard, nil), viewerBoard, nil, http.StatusForbidden},
+		{"moving to a public board they aren't a member of", taskOn(editorBoard, nil), editorBoard, board(true, ""), http.StatusForbidden},
+		{"moving to a board they only view", taskOn(editorBoard, nil), editorBoard, board(false, models.BoardRoleViewer), http.StatusForbidden},
+		{"owner removed from the task's board", taskOn(removedFrom, &userID), removedFrom, nil, http.StatusForbidden},
+		{"owner removed from the board moving the task away", taskOn(removedFrom, &userID), removedFrom, editorBoard, http.StatusForbidden},
+		{"owner of a task without a board", taskOn(nil, &userID), nil, nil, http.StatusOK},
+		{"owner putting a task without a board on a board they edit", taskOn(nil, &userID), nil, editorBoard, http.StatusOK},
+		{"owner putting a task without a board on a board they view", taskOn(nil, &userID), nil, viewerBoard, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			ok := authorizeTaskUpdate(c, tt.task, tt.board, tt.target, userID)
+			if ok != (tt.status == http.StatusOK) {
+				t.Errorf("authorizeTaskUpdate() = %v, want status %d", ok, tt.status)
+			}
+			if !ok && w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestRespondTaskUpdateError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		status   int
+		wantBody map[string]interface{}
+	}{
+		{
+			name:   "hard WIP limit",
+			err:    &repository.WIPLimitError{StatusID: 2, Limit: 3, Count: 3},
+			status: http.StatusConflict,
+			wantBody: map[string]interface{}{
+				"error":     "column is at its WIP limit",
+				"status_id": float64(2),
+				"limit":     float64(3),
+				"count":     float64(3),
+			},
+		},
+		{name: "transition not allowed", err: repository.ErrTransitionNotAllowed, status: http.StatusConflict},
+		{name: "status outside the workflow", err: repository.ErrInvalidStatus, status: http.StatusBadRequest},
+		{name: "done gate", err: &repository.DoneGateError{Violation: &models.DoneGateViolation{}}, status: http.StatusUnprocessableEntity},
+		{name: "done gate override", err: repository.ErrDoneGateOverrideForbidden, status: http.StatusForbidden},
+		{name: "invalid parent", err: repository.ErrInvalidParent, status: http.StatusBadRequest},
+		{name: "parent cycle", err: repository.ErrParentCycle, status: http.StatusConflict},
+		{name: "forbidden", err: repository.ErrForbidden, status: http.StatusForbidden},
+		{name: "missing task", err: errors.New("task not found"), status: http.StatusNotFound},
+		{name: "database error", err: errors.New("error updating task: connection reset"), status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			respondTaskUpdateError(c, tt.err)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.wantBody == nil {
+				return
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if !reflect.DeepEqual(body, tt.wantBody) {
+				t.Errorf("body = %v, want %v", body, tt.wantBody)
+			}
+		})
+	}
+}
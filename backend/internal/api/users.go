@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
 	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
 	"github.com/rafaelzasas/vtasker/backend/internal/repository"
 	"github.com/rafaelzasas/vtasker/backend/internal/repository/postgres"
@@ -34,7 +35,7 @@ func (h *UserHandler) Register(router *gin.RouterGroup) {
 	}
 }
 
-// ListUsers returns a list of all users (super admin only)
+// ListUsers returns a page of all users (super admin only)
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	// Get user from context
 	userIDStr := c.GetString("user_id")
@@ -61,13 +62,18 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		return
 	}
 
-	users, err := h.repo.List(c.Request.Context(), repository.UserFilter{})
+	limit, ok := listLimit(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.repo.ListPage(c.Request.Context(), repository.UserFilter{}, c.Query("sort"), c.Query("cursor"), limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondListError(c, err, repository.UserSortFields)
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	respondPage(c, page.Items, page.NextCursor)
 }
 
 // GetUser returns a user by ID (super admin only)
@@ -303,13 +309,52 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 
 // CleanupTestUsers deletes all test users (used for testing)
 func (h *UserHandler) CleanupTestUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	tx, err := h.repo.GetPool().Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	// Delete all users with email matching test*@example.com
-	query := `DELETE FROM users WHERE email LIKE 'test%@example.com'`
-	_, err := h.repo.GetPool().Exec(c.Request.Context(), query)
+	query := `DELETE FROM users WHERE email LIKE 'test%@example.com' RETURNING id, email`
+	rows, err := tx.Query(ctx, query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	deleted := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var id uuid.UUID
+		var email string
+		if err := rows.Scan(&id, &email); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		deleted[id] = email
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for id, email := range deleted {
+		err := repository.RecordAudit(ctx, tx, models.AuditUserTestCleanup, models.AuditEntityUser, id, map[string]any{"email": email}, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.Status(http.StatusNoContent)
 }
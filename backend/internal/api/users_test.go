@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+// fakeUserRepository keeps users in memory and records what was changed
+type fakeUserRepository struct {
+	repository.UserRepository
+	users   map[uuid.UUID]*user.User
+	updated *user.User
+	deleted *uuid.UUID
+}
+
+func (r *fakeUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) Update(ctx context.Context, u *user.User) error {
+	r.updated = u
+	return nil
+}
+
+func (r *fakeUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.deleted = &id
+	return nil
+}
+
+func TestUserAdminEndpoints(t *testing.T) {
+	superAdminID, adminID, memberID, otherSuperAdminID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	newRepo := func() *fakeUserRepository {
+		return &fakeUserRepository{users: map[uuid.UUID]*user.User{
+			superAdminID:      {ID: superAdminID, Role: &user.UserRole{Code: user.RoleCodeSuperAdmin}},
+			adminID:           {ID: adminID, Role: &user.UserRole{Code: user.RoleCodeAdmin}},
+			memberID:          {ID: memberID, RoleID: 3},
+			otherSuperAdminID: {ID: otherSuperAdminID, Role: &user.UserRole{Code: user.RoleCodeSuperAdmin}},
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		handler    func(h *UserHandler) gin.HandlerFunc
+		callerID   uuid.UUID
+		targetID   string
+		body       string
+		status     int
+		wantUpdate bool
+		wantDelete bool
+	}{
+		{"admin changing a role", updateUser, adminID, memberID.String(), `{"role_id":1}`, http.StatusUnauthorized, false, false},
+		{"super admin changing a role", updateUser, superAdminID, memberID.String(), `{"role_id":1}`, http.StatusOK, true, false},
+		{"updating a missing user", updateUser, superAdminID, uuid.NewString(), `{"role_id":1}`, http.StatusNotFound, false, false},
+		{"updating with a bad ID", updateUser, superAdminID, "nope", `{"role_id":1}`, http.StatusBadRequest, false, false},
+		{"admin deleting a user", deleteUser, adminID, memberID.String(), "", http.StatusUnauthorized, false, false},
+		{"super admin deleting a user", deleteUser, superAdminID, memberID.String(), "", http.StatusNoContent, false, true},
+		{"deleting a super admin", deleteUser, superAdminID, otherSuperAdminID.String(), "", http.StatusForbidden, false, false},
+		{"deleting a missing user", deleteUser, superAdminID, uuid.NewString(), "", http.StatusNotFound, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			h := &UserHandler{repo: repo}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/users/"+tt.targetID, strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: tt.targetID}}
+			c.Set("user_id", tt.callerID.String())
+
+			tt.handler(h)(c)
+			if status := c.Writer.Status(); status != tt.status {
+				t.Errorf("status = %d, want %d: %s", status, tt.status, w.Body.String())
+			}
+			if (repo.updated != nil) != tt.wantUpdate {
+				t.Errorf("updated = %v, want %v", repo.updated != nil, tt.wantUpdate)
+			}
+			if tt.wantUpdate && repo.updated.RoleID != 1 {
+				t.Errorf("role ID = %d, want 1", repo.updated.RoleID)
+			}
+			if (repo.deleted != nil) != tt.wantDelete {
+				t.Errorf("deleted = %v, want %v", repo.deleted != nil, tt.wantDelete)
+			}
+		})
+	}
+}
+
+func updateUser(h *UserHandler) gin.HandlerFunc { return h.UpdateUser }
+func deleteUser(h *UserHandler) gin.HandlerFunc { return h.DeleteUser }
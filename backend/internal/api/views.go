@@ -0,0 +1,430 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type ViewHandler struct {
+	repo      *repository.ViewRepository
+	taskRepo  *repository.TaskRepository
+	boardRepo *repository.BoardRepository
+}
+
+func NewViewHandler(pool *pgxpool.Pool) *ViewHandler {
+	return &ViewHandler{
+		repo:      repository.NewViewRepository(pool),
+		taskRepo:  repository.NewTaskRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+	}
+}
+
+// ListViews returns the user's own views and those shared on boards they can
+// see, pinned views first
+func (h *ViewHandler) ListViews(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	var boardID *uuid.UUID
+	if boardIDStr := c.Query("board_id"); boardIDStr != "" {
+		id, err := uuid.Parse(boardIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+			return
+		}
+		boardID = &id
+	}
+
+	views, err := h.repo.ListViews(c.Request.Context(), userID, boardID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, views)
+}
+
+// CreateView saves a new view. Sharing a view with a board takes edit access
+// to the board.
+func (h *ViewHandler) CreateView(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateViewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Visibility == "" {
+		input.Visibility = models.ViewVisibilityPrivate
+	}
+	if input.Columns == nil {
+		input.Columns = make([]string, 0)
+	}
+
+	if !validateViewSettings(c, &input.Query, &input.Sort, input.Columns) {
+		return
+	}
+
+	if input.BoardID != nil {
+		board, ok := h.loadBoard(c, *input.BoardID, userID)
+		if !ok {
+			return
+		}
+		if input.Visibility == models.ViewVisibilityBoard && !board.CanUserEdit(userID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to share views on this board"})
+			return
+		}
+	} else if input.Visibility == models.ViewVisibilityBoard {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "board_id is required to share a view with a board"})
+		return
+	}
+
+	view := &models.SavedView{
+		ID:         uuid.New(),
+		OwnerID:    userID,
+		BoardID:    input.BoardID,
+		Name:       strings.TrimSpace(input.Name),
+		Query:      input.Query,
+		Sort:       input.Sort,
+		Columns:    input.Columns,
+		Visibility: input.Visibility,
+		Pinned:     input.Pinned,
+	}
+	if view.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	if err := h.repo.CreateView(c.Request.Context(), view); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, view)
+}
+
+// GetView returns a view
+func (h *ViewHandler) GetView(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.loadView(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// UpdateView changes a view. The owner can change their views, and board
+// admins can change views shared with their board.
+func (h *ViewHandler) UpdateView(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.loadView(c, userID)
+	if !ok {
+		return
+	}
+
+	var input models.UpdateViewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name can't be empty"})
+			return
+		}
+		input.Name = &name
+	}
+
+	query, sort := view.Query, view.Sort
+	if input.Query != nil {
+		query = *input.Query
+	}
+	if input.Sort != nil {
+		sort = *input.Sort
+	}
+	if !validateViewSettings(c, &query, &sort, input.Columns) {
+		return
+	}
+	input.Query, input.Sort = &query, &sort
+
+	board, ok := h.loadViewBoard(c, view, userID)
+	if !ok {
+		return
+	}
+	if !canManageView(view, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to change this view"})
+		return
+	}
+
+	if input.Visibility != nil && *input.Visibility == models.ViewVisibilityBoard && view.Visibility != models.ViewVisibilityBoard {
+		if board == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "only views with a board can be shared with it"})
+			return
+		}
+		if !board.CanUserEdit(userID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to share views on this board"})
+			return
+		}
+	}
+
+	if err := h.repo.UpdateView(c.Request.Context(), view.ID, &input); err != nil {
+		respondViewError(c, err)
+		return
+	}
+
+	view, err := h.repo.GetView(c.Request.Context(), view.ID, userID)
+	if err != nil {
+		respondViewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// DeleteView deletes a view. The owner can delete their views, and board
+// admins can delete views shared with their board.
+func (h *ViewHandler) DeleteView(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.loadView(c, userID)
+	if !ok {
+		return
+	}
+
+	board, ok := h.loadViewBoard(c, view, userID)
+	if !ok {
+		return
+	}
+	if !canManageView(view, board, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to delete this view"})
+		return
+	}
+
+	if err := h.repo.DeleteView(c.Request.Context(), view.ID); err != nil {
+		respondViewError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListViewTasks runs a view's query, a page at a time. Tasks are filtered by
+// the same access rules as GET /tasks, so a shared view only shows each user
+// the tasks they can see, and "me" in its query is whoever runs it.
+func (h *ViewHandler) ListViewTasks(c *gin.Context) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.loadView(c, userID)
+	if !ok {
+		return
+	}
+
+	filters := repository.TaskFilters{
+		BoardID: view.BoardID,
+		Sort:    view.Sort,
+	}
+	if view.Query != "" {
+		query, ok := parseTaskQuery(c, view.Query)
+		if !ok {
+			return
+		}
+		filters.Query = query
+	}
+
+	limit, ok := listLimit(c)
+	if !ok {
+		return
+	}
+	if filters.Fields, ok = parseTaskFields(c); !ok {
+		return
+	}
+
+	page, err := h.taskRepo.ListTasks(c.Request.Context(), filters, userID, c.Query("cursor"), limit)
+	if err != nil {
+		respondListError(c, err, repository.TaskSortFields)
+		return
+	}
+
+	linkTaskThumbnails(c, page.Items...)
+	tasks, err := sparseTasks(page.Items, filters.Fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondPage(c, tasks, page.NextCursor)
+}
+
+// PinView pins a view for the user
+func (h *ViewHandler) PinView(c *gin.Context) {
+	h.setPinned(c, true)
+}
+
+// UnpinView unpins a view for the user
+func (h *ViewHandler) UnpinView(c *gin.Context) {
+	h.setPinned(c, false)
+}
+
+// setPinned pins or unpins a view the user can see
+func (h *ViewHandler) setPinned(c *gin.Context, pinned bool) {
+	userID, ok := activityUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.loadView(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.SetPinned(c.Request.Context(), view.ID, userID, pinned); err != nil {
+		respondViewError(c, err)
+		return
+	}
+
+	view.Pinned = pinned
+	c.JSON(http.StatusOK, view)
+}
+
+// loadView fetches the view named by the :id route parameter, if the user
+// can see it
+func (h *ViewHandler) loadView(c *gin.Context, userID uuid.UUID) (*models.SavedView, bool) {
+	viewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid view ID"})
+		return nil, false
+	}
+
+	view, err := h.repo.GetView(c.Request.Context(), viewID, userID)
+	if err != nil {
+		respondViewError(c, err)
+		return nil, false
+	}
+
+	return view, true
+}
+
+// loadViewBoard fetches a view's board, or nil for a view without one. A
+// board the user can no longer see is also nil.
+func (h *ViewHandler) loadViewBoard(c *gin.Context, view *models.SavedView, userID uuid.UUID) (*models.Board, bool) {
+	if view.BoardID == nil {
+		return nil, true
+	}
+
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), view.BoardID.String(), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			return nil, true
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return board, true
+}
+
+// loadBoard fetches a board the user can see
+func (h *ViewHandler) loadBoard(c *gin.Context, boardID uuid.UUID, userID uuid.UUID) (*models.Board, bool) {
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), boardID.String(), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return board, true
+}
+
+// canManageView reports whether the user may change or delete a view
+func canManageView(view *models.SavedView, board *models.Board, userID uuid.UUID) bool {
+	if view.OwnerID == userID {
+		return true
+	}
+	return view.Visibility == models.ViewVisibilityBoard && board != nil && board.CanUserAdmin(userID)
+}
+
+// validateViewSettings checks a view's query, sort and columns, responding
+// with the problem if one is invalid. The query is trimmed in place.
+func validateViewSettings(c *gin.Context, query *string, sort *string, columns []string) bool {
+	*query = strings.TrimSpace(*query)
+	if *query != "" {
+		if _, ok := parseTaskQuery(c, *query); !ok {
+			return false
+		}
+	}
+
+	if err := repository.ValidateTaskSort(*sort); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "invalid sort " + *sort,
+			"fields": repository.TaskSortFields,
+		})
+		return false
+	}
+
+	for _, column := range columns {
+		if !models.IsValidViewColumn(column) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid column " + column,
+				"columns": models.ViewColumns,
+			})
+			return false
+		}
+	}
+
+	return true
+}
+
+// respondViewError maps a view repository error to a response
+func respondViewError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// Register registers all view routes
+func (h *ViewHandler) Register(router *gin.RouterGroup) {
+	views := router.Group("/views")
+	{
+		views.GET("", h.ListViews)
+		views.POST("", h.CreateView)
+		views.GET("/:id", h.GetView)
+		views.PUT("/:id", h.UpdateView)
+		views.DELETE("/:id", h.DeleteView)
+		views.GET("/:id/tasks", h.ListViewTasks)
+		views.PUT("/:id/pin", h.PinView)
+		views.DELETE("/:id/pin", h.UnpinView)
+	}
+}
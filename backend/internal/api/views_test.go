@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestCanManageView(t *testing.T) {
+	ownerID, adminID, editorID, outsiderID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	board := &models.Board{
+		ID:       uuid.New(),
+		IsPublic: true,
+		Members: []models.BoardMember{
+			{UserID: adminID, Role: models.BoardRoleAdmin},
+			{UserID: editorID, Role: models.BoardRoleEditor},
+		},
+	}
+	view := func(visibility string, b *models.Board) *models.SavedView {
+		v := &models.SavedView{ID: uuid.New(), OwnerID: ownerID, Visibility: visibility}
+		if b != nil {
+			v.BoardID = &b.ID
+		}
+		return v
+	}
+
+	tests := []struct {
+		name   string
+		view   *models.SavedView
+		board  *models.Board
+		userID uuid.UUID
+		want   bool
+	}{
+		{"owner of a private view", view(models.ViewVisibilityPrivate, nil), nil, ownerID, true},
+		{"owner of a private view on a board", view(models.ViewVisibilityPrivate, board), board, ownerID, true},
+		{"board admin and a private view", view(models.ViewVisibilityPrivate, board), board, adminID, false},
+		{"owner of a shared view", view(models.ViewVisibilityBoard, board), board, ownerID, true},
+		{"board admin and a shared view", view(models.ViewVisibilityBoard, board), board, adminID, true},
+		{"board editor and a shared view", view(models.ViewVisibilityBoard, board), board, editorID, false},
+		{"public board visitor and a shared view", view(models.ViewVisibilityBoard, board), board, outsiderID, false},
+		{"board admin after losing the board", view(models.ViewVisibilityBoard, board), nil, adminID, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canManageView(tt.view, tt.board, tt.userID); got != tt.want {
+				t.Errorf("canManageView() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateViewRejectsBadInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"sharing without a board", `{"name":"Mine","visibility":"board"}`, http.StatusBadRequest},
+		{"unknown visibility", `{"name":"Mine","visibility":"public"}`, http.StatusBadRequest},
+		{"unknown column", `{"name":"Mine","columns":["secret"]}`, http.StatusBadRequest},
+		{"unknown sort", `{"name":"Mine","sort":"color"}`, http.StatusBadRequest},
+		{"blank name", `{"name":"  "}`, http.StatusBadRequest},
+	}
+
+	// None of these reach the repositories, so they have no pool
+	h := NewViewHandler(nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/views", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", uuid.NewString())
+
+			h.CreateView(c)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
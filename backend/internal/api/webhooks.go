@@ -0,0 +1,318 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+	"github.com/rafaelzasas/vtasker/backend/internal/services/webhooks"
+)
+
+type WebhookHandler struct {
+	repo      *repository.WebhookRepository
+	boardRepo *repository.BoardRepository
+}
+
+func NewWebhookHandler(pool *pgxpool.Pool) *WebhookHandler {
+	return &WebhookHandler{
+		repo:      repository.NewWebhookRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+	}
+}
+
+// ListWebhooks returns the webhooks on a board
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	board, _, ok := h.loadBoard(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.repo.ListWebhooks(c.Request.Context(), board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook subscribes a URL to a board's events
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var input models.CreateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validWebhookEvents(c, input.Events) || !validWebhookURL(c, input.URL) {
+		return
+	}
+
+	board, userID, ok := h.loadBoard(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.repo.CreateWebhook(c.Request.Context(), board.ID, &input, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// GetWebhook returns a webhook on a board
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhookID, ok := webhookParam(c)
+	if !ok {
+		return
+	}
+
+	board, _, ok := h.loadBoard(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.repo.GetWebhook(c.Request.Context(), board.ID, webhookID)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook changes a webhook's URL, events, secret or active flag
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhookID, ok := webhookParam(c)
+	if !ok {
+		return
+	}
+
+	var input models.UpdateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validWebhookEvents(c, input.Events) {
+		return
+	}
+	if input.URL != nil && !validWebhookURL(c, *input.URL) {
+		return
+	}
+
+	board, _, ok := h.loadBoard(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.repo.UpdateWebhook(c.Request.Context(), board.ID, webhookID, &input)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhookID, ok := webhookParam(c)
+	if !ok {
+		return
+	}
+
+	board, _, ok := h.loadBoard(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteWebhook(c.Request.Context(), board.ID, webhookID); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries returns a page of a webhook's delivery log
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhookID, ok := webhookParam(c)
+	if !ok {
+		return
+	}
+
+	status := models.WebhookDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryDead:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, delivered or dead"})
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		return
+	}
+
+	board, _, ok := h.loadBoard(c)
+	if !ok {
+		return
+	}
+
+	// Make sure the webhook belongs to this board
+	if _, err := h.repo.GetWebhook(c.Request.Context(), board.ID, webhookID); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	page, err := h.repo.ListDeliveries(c.Request.Context(), webhookID, status, c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Redeliver queues another delivery of an event that was already sent or dead-lettered
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	webhookID, ok := webhookParam(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery ID"})
+		return
+	}
+
+	board, _, ok := h.loadBoard(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.GetWebhook(c.Request.Context(), board.ID, webhookID); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	delivery, err := h.repo.Redeliver(c.Request.Context(), webhookID, deliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// loadBoard fetches the board named by the :id route parameter. Webhooks
+// carry board data to other systems, so only board admins can manage them.
+func (h *WebhookHandler) loadBoard(c *gin.Context) (*models.Board, uuid.UUID, bool) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return nil, uuid.Nil, false
+	}
+
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return nil, uuid.Nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, uuid.Nil, false
+	}
+
+	if !board.CanUserAdmin(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only board admins can manage webhooks"})
+		return nil, uuid.Nil, false
+	}
+
+	return board, userID, true
+}
+
+// webhookParam parses the :webhook_id route parameter
+func webhookParam(c *gin.Context) (uuid.UUID, bool) {
+	webhookID, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return uuid.Nil, false
+	}
+	return webhookID, true
+}
+
+// validWebhookEvents checks that every event type can be subscribed to
+func validWebhookEvents(c *gin.Context, events []string) bool {
+	for _, event := range events {
+		if !models.IsValidWebhookEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "unknown event type: " + event,
+				"events": models.WebhookEventTypes,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// validWebhookURL checks that a webhook URL is http or https and doesn't
+// name an internal address outright. Hostnames are checked again by the
+// dispatcher each time it connects.
+func validWebhookURL(c *gin.Context, rawURL string) bool {
+	if !models.IsValidWebhookURL(rawURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an http or https URL"})
+		return false
+	}
+	u, _ := url.Parse(rawURL)
+	if ip := net.ParseIP(u.Hostname()); ip != nil && webhooks.IsBlockedIP(ip) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must not point to a private or internal address"})
+		return false
+	}
+	return true
+}
+
+// respondWebhookError maps a webhook repository error to a response
+func respondWebhookError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// Register registers all webhook routes
+func (h *WebhookHandler) Register(router *gin.RouterGroup) {
+	webhooks := router.Group("/boards/:id/webhooks")
+	{
+		webhooks.GET("", h.ListWebhooks)
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("/:webhook_id", h.GetWebhook)
+		webhooks.PUT("/:webhook_id", h.UpdateWebhook)
+		webhooks.DELETE("/:webhook_id", h.DeleteWebhook)
+		webhooks.GET("/:webhook_id/deliveries", h.ListDeliveries)
+		webhooks.POST("/:webhook_id/deliveries/:delivery_id/redeliver", h.Redeliver)
+	}
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+type WorkflowHandler struct {
+	repo      *repository.WorkflowRepository
+	boardRepo *repository.BoardRepository
+}
+
+func NewWorkflowHandler(pool *pgxpool.Pool) *WorkflowHandler {
+	return &WorkflowHandler{
+		repo:      repository.NewWorkflowRepository(pool),
+		boardRepo: repository.NewBoardRepository(pool),
+	}
+}
+
+// ListStatuses returns the statuses of a board's workflow
+func (h *WorkflowHandler) ListStatuses(c *gin.Context) {
+	board, ok := h.loadBoard(c, false)
+	if !ok {
+		return
+	}
+
+	statuses, err := h.repo.ListStatuses(c.Request.Context(), &board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+// CreateStatus adds a status to a board's workflow
+func (h *WorkflowHandler) CreateStatus(c *gin.Context) {
+	var input models.CreateStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	status, err := h.repo.CreateStatus(c.Request.Context(), board.ID, &input)
+	if err != nil {
+		if errors.Is(err, repository.ErrStatusCodeExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, status)
+}
+
+// UpdateStatus changes a status in a board's workflow
+func (h *WorkflowHandler) UpdateStatus(c *gin.Context) {
+	statusID, err := strconv.ParseInt(c.Param("status_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status ID"})
+		return
+	}
+
+	var input models.UpdateStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	status, err := h.repo.UpdateStatus(c.Request.Context(), board.ID, int32(statusID), &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Status not found on this board"})
+		case errors.Is(err, repository.ErrStatusCodeExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// DeleteStatus removes a status from a board's workflow
+func (h *WorkflowHandler) DeleteStatus(c *gin.Context) {
+	statusID, err := strconv.ParseInt(c.Param("status_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status ID"})
+		return
+	}
+
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteStatus(c.Request.Context(), board.ID, int32(statusID)); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Status not found on this board"})
+		case errors.Is(err, repository.ErrStatusInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListTransitions returns a board's allowed status transitions
+func (h *WorkflowHandler) ListTransitions(c *gin.Context) {
+	board, ok := h.loadBoard(c, false)
+	if !ok {
+		return
+	}
+
+	transitions, err := h.repo.ListTransitions(c.Request.Context(), board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transitions)
+}
+
+// SetTransitions replaces a board's transition matrix
+func (h *WorkflowHandler) SetTransitions(c *gin.Context) {
+	var input models.SetTransitionsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	transitions, err := h.repo.SetTransitions(c.Request.Context(), board.ID, input.Transitions)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a transition must change the status"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transitions)
+}
+
+// ListWIPLimits returns the WIP limits set on a board's columns
+func (h *WorkflowHandler) ListWIPLimits(c *gin.Context) {
+	board, ok := h.loadBoard(c, false)
+	if !ok {
+		return
+	}
+
+	limits, err := h.repo.ListWIPLimits(c.Request.Context(), board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// SetWIPLimit sets the WIP limit on one of a board's columns
+func (h *WorkflowHandler) SetWIPLimit(c *gin.Context) {
+	statusID, err := strconv.ParseInt(c.Param("status_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status ID"})
+		return
+	}
+
+	var input models.SetWIPLimitInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	limit, err := h.repo.SetWIPLimit(c.Request.Context(), board.ID, int32(statusID), &input)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, limit)
+}
+
+// DeleteWIPLimit removes the WIP limit from one of a board's columns
+func (h *WorkflowHandler) DeleteWIPLimit(c *gin.Context) {
+	statusID, err := strconv.ParseInt(c.Param("status_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status ID"})
+		return
+	}
+
+	board, ok := h.loadBoard(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteWIPLimit(c.Request.Context(), board.ID, int32(statusID)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Column has no WIP limit"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadBoard fetches the board named by the :id route parameter, optionally
+// requiring the user to be a board admin
+func (h *WorkflowHandler) loadBoard(c *gin.Context, requireAdmin bool) (*models.Board, bool) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return nil, false
+	}
+
+	board, err := h.boardRepo.GetBoard(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if requireAdmin && !board.CanUserAdmin(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only board admins can change the workflow"})
+		return nil, false
+	}
+
+	return board, true
+}
+
+// Register registers all workflow routes
+func (h *WorkflowHandler) Register(router *gin.RouterGroup) {
+	statuses := router.Group("/boards/:id/statuses")
+	{
+		statuses.GET("", h.ListStatuses)
+		statuses.POST("", h.CreateStatus)
+		statuses.PUT("/:status_id", h.UpdateStatus)
+		statuses.DELETE("/:status_id", h.DeleteStatus)
+	}
+
+	transitions := router.Group("/boards/:id/transitions")
+	{
+		transitions.GET("", h.ListTransitions)
+		transitions.PUT("", h.SetTransitions)
+	}
+
+	wipLimits := router.Group("/boards/:id/wip-limits")
+	{
+		wipLimits.GET("", h.ListWIPLimits)
+		wipLimits.PUT("/:status_id", h.SetWIPLimit)
+		wipLimits.DELETE("/:status_id", h.DeleteWIPLimit)
+	}
+}
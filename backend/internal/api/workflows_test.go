@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// bindJSON binds a JSON request body into input
+func bindJSON(body string, input interface{}) error {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	return c.ShouldBindJSON(input)
+}
+
+func TestStatusColorBinding(t *testing.T) {
+	tests := []struct {
+		color string
+		valid bool
+	}{
+		{"", true},
+		{`"#1F2937"`, true},
+		{`"#a1b2c3"`, true},
+		{`""`, false},
+		{`"#FFF"`, false},
+		{`"#1F2937FF"`, false},
+		{`"1F2937"`, false},
+		{`"#1G2937"`, false},
+		{`"red"`, false},
+	}
+
+	for _, tt := range tests {
+		create, update := `{"code":"qa","name":"QA","category":"in_progress"}`, `{}`
+		if tt.color != "" {
+			create = `{"code":"qa","name":"QA","category":"in_progress","color":` + tt.color + `}`
+			update = `{"color":` + tt.color + `}`
+		}
+
+		if err := bindJSON(create, &models.CreateStatusInput{}); (err == nil) != tt.valid {
+			t.Errorf("create status with color %s: error = %v, want valid %v", tt.color, err, tt.valid)
+		}
+		if err := bindJSON(update, &models.UpdateStatusInput{}); (err == nil) != tt.valid {
+			t.Errorf("update status with color %s: error = %v, want valid %v", tt.color, err, tt.valid)
+		}
+	}
+}
@@ -44,23 +44,23 @@ func (h *BoardHandler) ListBoards(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
-		boards, err := h.boardRepo.ListAllBoards(r.Context())
+		page, err := h.boardRepo.ListAllBoards(r.Context(), "", "", 0)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		json.NewEncoder(w).Encode(boards)
+		json.NewEncoder(w).Encode(page.Items)
 		return
 	}
 
 	// Regular user board listing
-	boards, err := h.boardRepo.ListBoards(r.Context(), user.ID)
+	page, err := h.boardRepo.ListBoards(r.Context(), user.ID, "", "", 0)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(boards)
+	json.NewEncoder(w).Encode(page.Items)
 }
 
 // ListAllBoards returns a list of all boards (super admin only)
@@ -72,13 +72,13 @@ func (h *BoardHandler) ListAllBoards(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	boards, err := h.boardRepo.ListAllBoards(r.Context())
+	page, err := h.boardRepo.ListAllBoards(r.Context(), "", "", 0)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(boards)
+	json.NewEncoder(w).Encode(page.Items)
 }
 
 // CreateBoard creates a new board
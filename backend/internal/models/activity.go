@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types recorded in the activity feed
+const (
+	ActivityEntityTask  = "task"
+	ActivityEntityBoard = "board"
+)
+
+// Actions recorded in the activity feed
+const (
+	ActivityCreated             = "created"
+	ActivityUpdated             = "updated"
+	ActivityDeleted             = "deleted"
+	ActivityCollaboratorAdded   = "collaborator_added"
+	ActivityCollaboratorUpdated = "collaborator_updated"
+	ActivityCollaboratorRemoved = "collaborator_removed"
+	ActivityCriterionAdded      = "criterion_added"
+	ActivityCriterionUpdated    = "criterion_updated"
+	ActivityCriterionRemoved    = "criterion_removed"
+	ActivityDependencyAdded     = "dependency_added"
+	ActivityDependencyRemoved   = "dependency_removed"
+	ActivityAttachmentAdded     = "attachment_added"
+	ActivityAttachmentRemoved   = "attachment_removed"
+	ActivityMemberAdded         = "member_added"
+	ActivityMemberUpdated       = "member_updated"
+	ActivityMemberRemoved       = "member_removed"
+)
+
+// Activity is a single event in a board or task activity feed
+type Activity struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	ActorID    *uuid.UUID     `json:"actor_id,omitempty" db:"actor_id"`
+	ActorName  *string        `json:"actor_name,omitempty"`
+	EntityType string         `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID      `json:"entity_id" db:"entity_id"`
+	BoardID    *uuid.UUID     `json:"board_id,omitempty" db:"board_id"`
+	TaskID     *uuid.UUID     `json:"task_id,omitempty" db:"task_id"`
+	Action     string         `json:"action" db:"action"`
+	Changes    []FieldChange  `json:"changes,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	// BoardSeq orders the board's events by commit; event streams resume from it
+	BoardSeq  *int64    `json:"-" db:"board_seq"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// FieldChange is the before and after value of one field changed by an activity
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// ActivityPage is one page of an activity feed, newest first
+type ActivityPage struct {
+	Items      []Activity `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Thumbnail statuses of an attachment
+const (
+	// ThumbnailNone means the attachment isn't an image that can have a thumbnail
+	ThumbnailNone    = "none"
+	ThumbnailPending = "pending"
+	ThumbnailReady   = "ready"
+	// ThumbnailFailed means the image couldn't be decoded, or generating its
+	// thumbnail kept failing
+	ThumbnailFailed = "failed"
+)
+
+// Attachment is a file uploaded to a task. The content lives in blob storage;
+// the content type is sniffed from the content rather than trusted from the
+// client.
+type Attachment struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	TaskID      uuid.UUID  `json:"task_id" db:"task_id"`
+	Filename    string     `json:"filename" db:"filename"`
+	ContentType string     `json:"content_type" db:"content_type"`
+	Size        int64      `json:"size" db:"size_bytes"`
+	SHA256      string     `json:"sha256" db:"sha256"`
+	UploadedBy  *uuid.UUID `json:"uploaded_by,omitempty" db:"uploaded_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	StorageKey  string     `json:"-" db:"storage_key"`
+
+	ThumbnailStatus string `json:"thumbnail_status" db:"thumbnail_status"`
+	// ThumbnailURL is the API path of the thumbnail once it's ready. It's set
+	// by the API, under the route group the request came through.
+	ThumbnailURL         string  `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth       *int    `json:"thumbnail_width,omitempty" db:"thumbnail_width"`
+	ThumbnailHeight      *int    `json:"thumbnail_height,omitempty" db:"thumbnail_height"`
+	ThumbnailKey         *string `json:"-" db:"thumbnail_key"`
+	ThumbnailContentType *string `json:"-" db:"thumbnail_content_type"`
+}
+
+// AttachmentUsage is how much of its attachment quota a board has used
+type AttachmentUsage struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+	Count      int   `json:"attachment_count"`
+	// Custom is true when the board has its own quota rather than the default
+	Custom bool `json:"custom"`
+}
+
+// AttachmentQuotaInput sets a board's attachment quota. A null quota goes back
+// to the default.
+type AttachmentQuotaInput struct {
+	QuotaBytes *int64 `json:"quota_bytes" binding:"omitempty,min=0"`
+}
+
+// ThumbnailJob is an image attachment waiting for its thumbnail
+type ThumbnailJob struct {
+	AttachmentID uuid.UUID
+	TaskID       uuid.UUID
+	StorageKey   string
+	ContentType  string
+	Size         int64
+	Attempts     int
+}
+
+// BlobDeletion is a blob queued for removal from storage
+type BlobDeletion struct {
+	ID         int64
+	StorageKey string
+	Attempts   int
+}
@@ -1,11 +1,53 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// Audited privileged actions
+const (
+	AuditUserRoleChanged = "user:role_changed"
+	AuditUserDeleted     = "user:deleted"
+	AuditUserTestCleanup = "user:test_cleanup"
+	AuditBoardDeleted    = "board:deleted"
+)
+
+// Entity types recorded in the audit trail
+const (
+	AuditEntityUser  = "user"
+	AuditEntityBoard = "board"
+)
+
+// AuditLog is one privileged action in the admin audit trail
 type AuditLog struct {
-	ID        string    `json:"id"`
-	Action    string    `json:"action"`
-	TaskID    string    `json:"task_id"`
-	Details   string    `json:"details"`
-	CreatedAt time.Time `json:"created_at"`
-} 
\ No newline at end of file
+	ID            uuid.UUID       `json:"id" db:"id"`
+	AdminID       *uuid.UUID      `json:"admin_id,omitempty" db:"admin_id"`
+	AdminName     *string         `json:"admin_name,omitempty"`
+	Action        string          `json:"action" db:"action"`
+	EntityType    string          `json:"entity_type" db:"entity_type"`
+	EntityID      uuid.UUID       `json:"entity_id" db:"entity_id"`
+	PreviousState json.RawMessage `json:"previous_state,omitempty" db:"previous_state"`
+	NewState      json.RawMessage `json:"new_state,omitempty" db:"new_state"`
+	IPAddress     *string         `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent     *string         `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter narrows the audit trail. Zero values don't filter.
+type AuditFilter struct {
+	AdminID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditPage is one page of the audit trail, newest first
+type AuditPage struct {
+	Items      []AuditLog `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -29,6 +29,13 @@ type Board struct {
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 	Members     []BoardMember `json:"members,omitempty"`
 	Tasks       []Task       `json:"tasks,omitempty"`
+	Columns     []BoardColumn `json:"columns,omitempty"`
+}
+
+// BoardPage is one page of a board list
+type BoardPage struct {
+	Items      []*Board `json:"items"`
+	NextCursor string   `json:"next_cursor,omitempty"`
 }
 
 // BoardMember represents a user's membership in a board
@@ -96,38 +103,55 @@ func GenerateSlug(s string) string {
 	return s
 }
 
-// CanUserEdit checks if a user has edit permissions for the board
-func (b *Board) CanUserEdit(userID uuid.UUID) bool {
-	// Owner has full permissions
+// RoleFor returns the role a user has on the board. The owner is an admin and
+// users who aren't members get an empty role, even on public boards. A nil
+// board, as for tasks without one, gives everyone an empty role.
+func (b *Board) RoleFor(userID uuid.UUID) BoardRole {
+	if b == nil {
+		return ""
+	}
+
 	if b.OwnerID != nil && *b.OwnerID == userID {
-		return true
+		return BoardRoleAdmin
 	}
 
-	// Check member permissions
 	for _, member := range b.Members {
 		if member.UserID == userID {
-			return member.Role == BoardRoleEditor || member.Role == BoardRoleAdmin
+			return member.Role
 		}
 	}
 
-	return false
+	return ""
 }
 
-// CanUserAdmin checks if a user has admin permissions for the board
-func (b *Board) CanUserAdmin(userID uuid.UUID) bool {
-	// Owner has full permissions
-	if b.OwnerID != nil && *b.OwnerID == userID {
-		return true
+// boardRoleRank orders roles from least to most privileged
+func boardRoleRank(role BoardRole) int {
+	switch role {
+	case BoardRoleViewer:
+		return 1
+	case BoardRoleEditor:
+		return 2
+	case BoardRoleAdmin:
+		return 3
+	default:
+		return 0
 	}
+}
 
-	// Check member permissions
-	for _, member := range b.Members {
-		if member.UserID == userID {
-			return member.Role == BoardRoleAdmin
-		}
-	}
+// AtLeast reports whether the role grants everything min does. An empty role
+// grants nothing.
+func (r BoardRole) AtLeast(min BoardRole) bool {
+	return r != "" && boardRoleRank(r) >= boardRoleRank(min)
+}
 
-	return false
+// CanUserEdit checks if a user has edit permissions for the board
+func (b *Board) CanUserEdit(userID uuid.UUID) bool {
+	return b.RoleFor(userID).AtLeast(BoardRoleEditor)
+}
+
+// CanUserAdmin checks if a user has admin permissions for the board
+func (b *Board) CanUserAdmin(userID uuid.UUID) bool {
+	return b.RoleFor(userID).AtLeast(BoardRoleAdmin)
 }
 
 // CanUserView checks if a user can view the board
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models/task"
+)
+
+func TestBoardRoleAtLeast(t *testing.T) {
+	roles := []BoardRole{"", BoardRoleViewer, BoardRoleEditor, BoardRoleAdmin}
+
+	for i, role := range roles {
+		for j, min := range roles[1:] {
+			if got, want := role.AtLeast(min), i > 0 && i >= j+1; got != want {
+				t.Errorf("%q.AtLeast(%q) = %v, want %v", role, min, got, want)
+			}
+		}
+	}
+
+	if BoardRole("owner").AtLeast(BoardRoleViewer) {
+		t.Error("unknown role grants viewer")
+	}
+}
+
+func TestBoardAndTaskRoles(t *testing.T) {
+	owner, admin, editor, viewer, outsider := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	board := &Board{
+		ID:      uuid.New(),
+		OwnerID: &owner,
+		Members: []BoardMember{
+			{UserID: admin, Role: BoardRoleAdmin},
+			{UserID: editor, Role: BoardRoleEditor},
+			{UserID: viewer, Role: BoardRoleViewer},
+		},
+	}
+	onBoard := func(task Task) *Task {
+		task.BoardID = &board.ID
+		return &task
+	}
+	collaborating := onBoard(Task{Collaborators: []task.Collaborator{
+		{UserID: viewer, Role: task.CollaboratorRoleEditor},
+		{UserID: outsider, Role: task.CollaboratorRoleAdmin},
+		// A collaborator role lower than the board role doesn't demote
+		{UserID: admin, Role: task.CollaboratorRoleViewer},
+	}})
+
+	tests := []struct {
+		name      string
+		board     *Board
+		task      *Task
+		userID    uuid.UUID
+		boardRole BoardRole
+		editTask  bool
+		adminTask bool
+	}{
+		{"owner", board, onBoard(Task{}), owner, BoardRoleAdmin, true, true},
+		{"admin", board, onBoard(Task{}), admin, BoardRoleAdmin, true, true},
+		{"editor", board, onBoard(Task{}), editor, BoardRoleEditor, true, false},
+		{"viewer", board, onBoard(Task{}), viewer, BoardRoleViewer, false, false},
+		{"outsider", board, onBoard(Task{}), outsider, "", false, false},
+		{"viewer promoted by collaborator role", board, collaborating, viewer, BoardRoleViewer, true, false},
+		{"outsider collaborating", board, collaborating, outsider, "", false, false},
+		{"admin collaborating as viewer", board, collaborating, admin, BoardRoleAdmin, true, true},
+		{"viewer owning task", board, onBoard(Task{OwnerID: &viewer}), viewer, BoardRoleViewer, true, true},
+		{"task owner removed from board", board, onBoard(Task{OwnerID: &outsider}), outsider, "", false, false},
+		{"no board", nil, &Task{}, editor, "", false, false},
+		{"no board task owner", nil, &Task{OwnerID: &editor}, editor, "", true, true},
+		{"no board collaborator", nil, &Task{Collaborators: []task.Collaborator{{UserID: editor, Role: task.CollaboratorRoleEditor}}}, editor, "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boardRole := tt.board.RoleFor(tt.userID)
+			if boardRole != tt.boardRole {
+				t.Errorf("board role = %q, want %q", boardRole, tt.boardRole)
+			}
+			if tt.board != nil {
+				if got := tt.board.CanUserEdit(tt.userID); got != boardRole.AtLeast(BoardRoleEditor) {
+					t.Errorf("Board.CanUserEdit() = %v for role %q", got, boardRole)
+				}
+				if got := tt.board.CanUserAdmin(tt.userID); got != (boardRole == BoardRoleAdmin) {
+					t.Errorf("Board.CanUserAdmin() = %v for role %q", got, boardRole)
+				}
+			}
+			if got := tt.task.CanUserEdit(tt.userID, boardRole); got != tt.editTask {
+				t.Errorf("Task.CanUserEdit() = %v, want %v", got, tt.editTask)
+			}
+			if got := tt.task.CanUserAdmin(tt.userID, boardRole); got != tt.adminTask {
+				t.Errorf("Task.CanUserAdmin() = %v, want %v", got, tt.adminTask)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment represents a comment on a task. Top-level comments may carry one
+// level of replies; replies themselves cannot be replied to.
+type Comment struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	TaskID    uuid.UUID  `json:"task_id" db:"task_id"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty" db:"parent_id"`
+	AuthorID  uuid.UUID  `json:"author_id" db:"author_id"`
+	Body      string     `json:"body" db:"body"`
+	EditedAt  *time.Time `json:"edited_at,omitempty" db:"edited_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	Author    *User      `json:"author,omitempty"`
+	Mentions  []Mention  `json:"mentions"`
+	Replies   []Comment  `json:"replies,omitempty"`
+}
+
+// CommentEdit represents a previous revision of an edited comment
+type CommentEdit struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	CommentID    uuid.UUID  `json:"comment_id" db:"comment_id"`
+	PreviousBody string     `json:"previous_body" db:"previous_body"`
+	EditedBy     *uuid.UUID `json:"edited_by,omitempty" db:"edited_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateCommentInput represents the input for creating a comment
+type CreateCommentInput struct {
+	Body     string     `json:"body" binding:"required"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// UpdateCommentInput represents the input for editing a comment
+type UpdateCommentInput struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// NewComment creates a new comment from input
+func NewComment(input CreateCommentInput, taskID uuid.UUID, authorID uuid.UUID) *Comment {
+	now := time.Now().UTC()
+	return &Comment{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		ParentID:  input.ParentID,
+		AuthorID:  authorID,
+		Body:      input.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// IsReply reports whether the comment is a reply to another comment
+func (c *Comment) IsReply() bool {
+	return c.ParentID != nil
+}
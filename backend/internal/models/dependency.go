@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Dependency directions relative to the task a graph was requested for
+const (
+	DependencyDirectionUpstream   = "blocked_by"
+	DependencyDirectionDownstream = "blocks"
+)
+
+// TaskDependency represents a "blocked by" edge: TaskID is blocked by DependencyID
+type TaskDependency struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	TaskID       uuid.UUID `json:"task_id" db:"task_id"`
+	DependencyID uuid.UUID `json:"dependency_id" db:"dependency_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateDependencyInput represents the input for adding a dependency edge.
+// Exactly one of BlockedBy or Blocks must be set.
+type CreateDependencyInput struct {
+	BlockedBy *uuid.UUID `json:"blocked_by,omitempty"`
+	Blocks    *uuid.UUID `json:"blocks,omitempty"`
+}
+
+// DependencyNode is a task reached while walking the dependency graph
+type DependencyNode struct {
+	ID        uuid.UUID  `json:"id"`
+	Title     string     `json:"title"`
+	StatusID  int32      `json:"status_id"`
+	BoardID   *uuid.UUID `json:"board_id,omitempty"`
+	Direction string     `json:"direction"`
+	Depth     int        `json:"depth"`
+}
+
+// DependencyGraph is the transitive dependency graph around a task
+type DependencyGraph struct {
+	TaskID uuid.UUID        `json:"task_id"`
+	Depth  int              `json:"depth"`
+	Nodes  []DependencyNode `json:"nodes"`
+	Edges  []TaskDependency `json:"edges"`
+}
+
+// Edge returns the (task, dependency) pair described by the input relative to taskID
+func (i CreateDependencyInput) Edge(taskID uuid.UUID) (blocked uuid.UUID, blocking uuid.UUID, ok bool) {
+	switch {
+	case i.BlockedBy != nil && i.Blocks == nil:
+		return taskID, *i.BlockedBy, true
+	case i.Blocks != nil && i.BlockedBy == nil:
+		return *i.Blocks, taskID, true
+	default:
+		return uuid.Nil, uuid.Nil, false
+	}
+}
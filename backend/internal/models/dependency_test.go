@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateDependencyInputEdge(t *testing.T) {
+	taskID, otherID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name         string
+		input        CreateDependencyInput
+		wantBlocked  uuid.UUID
+		wantBlocking uuid.UUID
+		wantOK       bool
+	}{
+		{"blocked by", CreateDependencyInput{BlockedBy: &otherID}, taskID, otherID, true},
+		{"blocks", CreateDependencyInput{Blocks: &otherID}, otherID, taskID, true},
+		{"neither", CreateDependencyInput{}, uuid.Nil, uuid.Nil, false},
+		{"both", CreateDependencyInput{BlockedBy: &otherID, Blocks: &otherID}, uuid.Nil, uuid.Nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked, blocking, ok := tt.input.Edge(taskID)
+			if blocked != tt.wantBlocked || blocking != tt.wantBlocking || ok != tt.wantOK {
+				t.Errorf("Edge() = (%v, %v, %v), want (%v, %v, %v)", blocked, blocking, ok, tt.wantBlocked, tt.wantBlocking, tt.wantOK)
+			}
+		})
+	}
+}
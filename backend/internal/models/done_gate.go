@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DoneGate is the definition of done a task must meet before it can move into
+// a done-category status. A gate without a board is the global default.
+type DoneGate struct {
+	ID                  *uuid.UUID `json:"id,omitempty" db:"id"`
+	BoardID             *uuid.UUID `json:"board_id,omitempty" db:"board_id"`
+	Enabled             bool       `json:"enabled" db:"enabled"`
+	RequireCriteria     bool       `json:"require_criteria" db:"require_criteria"`
+	RequireDependencies bool       `json:"require_dependencies" db:"require_dependencies"`
+	CreatedAt           *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// DefaultDoneGate is used when neither the board nor the global gate is configured
+func DefaultDoneGate() *DoneGate {
+	return &DoneGate{
+		Enabled:             false,
+		RequireCriteria:     true,
+		RequireDependencies: true,
+	}
+}
+
+// DoneGateInput represents the input for configuring a done gate
+type DoneGateInput struct {
+	Enabled             bool `json:"enabled"`
+	RequireCriteria     bool `json:"require_criteria"`
+	RequireDependencies bool `json:"require_dependencies"`
+}
+
+// BlockingTask is an unfinished task that another task depends on
+type BlockingTask struct {
+	ID       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	StatusID int32     `json:"status_id"`
+}
+
+// DoneGateViolation lists what keeps a task from meeting the definition of done
+type DoneGateViolation struct {
+	UnmetCriteria []AcceptanceCriterion `json:"unmet_criteria"`
+	BlockingTasks []BlockingTask        `json:"blocking_tasks"`
+}
+
+// IsEmpty reports whether the task meets the definition of done
+func (v *DoneGateViolation) IsEmpty() bool {
+	return len(v.UnmetCriteria) == 0 && len(v.BlockingTasks) == 0
+}
+
+// DoneGateOverride records a move into done that skipped an unmet gate
+type DoneGateOverride struct {
+	ID            uuid.UUID             `json:"id" db:"id"`
+	TaskID        uuid.UUID             `json:"task_id" db:"task_id"`
+	BoardID       *uuid.UUID            `json:"board_id,omitempty" db:"board_id"`
+	UserID        *uuid.UUID            `json:"user_id,omitempty" db:"user_id"`
+	FromStatusID  *int32                `json:"from_status_id,omitempty" db:"from_status_id"`
+	ToStatusID    *int32                `json:"to_status_id,omitempty" db:"to_status_id"`
+	Reason        *string               `json:"reason,omitempty" db:"reason"`
+	UnmetCriteria []AcceptanceCriterion `json:"unmet_criteria" db:"unmet_criteria"`
+	BlockingTasks []BlockingTask        `json:"blocking_tasks" db:"blocking_tasks"`
+	CreatedAt     time.Time             `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultLabelColor is used when a label is created without a color
+const DefaultLabelColor = "#6B7280"
+
+// Label represents a label defined on a board that can be attached to its tasks
+type Label struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	BoardID   uuid.UUID `json:"board_id" db:"board_id"`
+	Name      string    `json:"name" db:"name"`
+	Color     string    `json:"color" db:"color"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateLabelInput represents the input for creating a label
+type CreateLabelInput struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color,omitempty" binding:"omitempty,hexcolor,len=7"`
+}
+
+// UpdateLabelInput represents the input for updating a label
+type UpdateLabelInput struct {
+	Name  *string `json:"name,omitempty" binding:"omitempty,min=1,max=50"`
+	Color *string `json:"color,omitempty" binding:"omitnil,hexcolor,len=7"`
+}
+
+// AttachLabelInput represents the input for attaching a label to a task
+type AttachLabelInput struct {
+	LabelID uuid.UUID `json:"label_id" binding:"required"`
+}
+
+// NewLabel creates a new label from input
+func NewLabel(input CreateLabelInput, boardID uuid.UUID) *Label {
+	now := time.Now().UTC()
+	color := input.Color
+	if color == "" {
+		color = DefaultLabelColor
+	}
+	return &Label{
+		ID:        uuid.New(),
+		BoardID:   boardID,
+		Name:      input.Name,
+		Color:     color,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
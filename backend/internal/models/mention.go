@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Fields that @mentions are resolved in
+const (
+	MentionFieldDescription           = "description"
+	MentionFieldNotes                 = "notes"
+	MentionFieldImplementationDetails = "implementation_details"
+	MentionFieldComment               = "comment"
+)
+
+// Mention is an @email or @handle in a task's text that was resolved to a
+// user. Start and End are offsets in Unicode code points into the field's
+// text; the span covers the leading @.
+type Mention struct {
+	Field     string     `json:"field" db:"field"`
+	CommentID *uuid.UUID `json:"comment_id,omitempty" db:"comment_id"`
+	Start     int        `json:"start" db:"start_offset"`
+	End       int        `json:"end" db:"end_offset"`
+	Text      string     `json:"text" db:"mention_text"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	Handle    string     `json:"handle"`
+	FullName  string     `json:"full_name"`
+}
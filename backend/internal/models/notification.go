@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types
+const (
+	NotificationTaskAssigned      = "task_assigned"
+	NotificationMentioned         = "mentioned"
+	NotificationBoardMemberAdded  = "board_member_added"
+	NotificationTaskStatusChanged = "task_status_changed"
+	NotificationTaskDueSoon       = "task_due_soon"
+	// NotificationDailyDigest is only sent by email, as a summary of the
+	// user's overdue and upcoming tasks
+	NotificationDailyDigest = "daily_digest"
+)
+
+// NotificationTypes lists the notification types users can turn on or off
+var NotificationTypes = []string{
+	NotificationTaskAssigned,
+	NotificationMentioned,
+	NotificationBoardMemberAdded,
+	NotificationTaskStatusChanged,
+	NotificationTaskDueSoon,
+	NotificationDailyDigest,
+}
+
+// NotificationEmailTypes lists the notification types that are also emailed
+var NotificationEmailTypes = []string{
+	NotificationTaskAssigned,
+	NotificationMentioned,
+	NotificationTaskDueSoon,
+}
+
+// IsEmailNotification reports whether notificationType is also emailed
+func IsEmailNotification(notificationType string) bool {
+	for _, emailed := range NotificationEmailTypes {
+		if notificationType == emailed {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidNotificationType reports whether notificationType is a known type
+func IsValidNotificationType(notificationType string) bool {
+	for _, valid := range NotificationTypes {
+		if notificationType == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification tells a user about something that happened to them or their work
+type Notification struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	UserID    uuid.UUID       `json:"user_id" db:"user_id"`
+	Type      string          `json:"type" db:"type"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty" db:"actor_id"`
+	ActorName *string         `json:"actor_name,omitempty"`
+	BoardID   *uuid.UUID      `json:"board_id,omitempty" db:"board_id"`
+	TaskID    *uuid.UUID      `json:"task_id,omitempty" db:"task_id"`
+	Data      json.RawMessage `json:"data" db:"data"`
+	ReadAt    *time.Time      `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// NotificationPage is one page of a user's notifications, newest first
+type NotificationPage struct {
+	Items      []Notification `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// NotificationPreference is whether a user gets one type of notification,
+// and whether they also get it by email
+type NotificationPreference struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+	Email   bool   `json:"email"`
+}
+
+// NotificationPreferenceInput changes a user's preference for one type.
+// Settings that aren't given are left alone. A bare boolean sets Enabled.
+type NotificationPreferenceInput struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	Email   *bool `json:"email,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (p *NotificationPreferenceInput) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		p.Enabled = &enabled
+		p.Email = nil
+		return nil
+	}
+
+	type Alias NotificationPreferenceInput
+	return json.Unmarshal(data, (*Alias)(p))
+}
+
+// PendingNotificationEmail is a notification that's due to be emailed, with
+// what's needed to write the email
+type PendingNotificationEmail struct {
+	ID        uuid.UUID
+	Type      string
+	Data      json.RawMessage
+	TaskID    *uuid.UUID
+	BoardSlug *string
+	ActorName *string
+	Attempts  int
+	Email     string
+	FullName  string
+}
+
+// DigestTask is a task listed in a daily digest
+type DigestTask struct {
+	ID         uuid.UUID `json:"id"`
+	Title      string    `json:"title"`
+	BoardName  string    `json:"board_name"`
+	BoardSlug  string    `json:"board_slug"`
+	StatusName string    `json:"status_name"`
+	DueDate    time.Time `json:"due_date"`
+}
+
+// Digest summarizes a user's overdue tasks and those due in the coming week
+// across the boards they belong to. The lists are capped; the counts are
+// the full totals.
+type Digest struct {
+	UserID           uuid.UUID
+	Email            string
+	FullName         string
+	Overdue          []DigestTask
+	DueThisWeek      []DigestTask
+	OverdueCount     int
+	DueThisWeekCount int
+}
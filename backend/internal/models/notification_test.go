@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNotificationPreferenceInputUnmarshal(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantEnabled *bool
+		wantEmail   *bool
+		wantErr     bool
+	}{
+		{name: "bare true", data: `true`, wantEnabled: boolPtr(true)},
+		{name: "bare false", data: `false`, wantEnabled: boolPtr(false)},
+		{name: "both settings", data: `{"enabled":true,"email":false}`, wantEnabled: boolPtr(true), wantEmail: boolPtr(false)},
+		{name: "email only", data: `{"email":false}`, wantEmail: boolPtr(false)},
+		{name: "empty object", data: `{}`},
+		{name: "string", data: `"on"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input NotificationPreferenceInput
+			err := json.Unmarshal([]byte(tt.data), &input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !equalBoolPtr(input.Enabled, tt.wantEnabled) || !equalBoolPtr(input.Email, tt.wantEmail) {
+				t.Errorf("input = {Enabled: %v, Email: %v}, want {Enabled: %v, Email: %v}", input.Enabled, input.Email, tt.wantEnabled, tt.wantEmail)
+			}
+		})
+	}
+
+	t.Run("preferences keyed by type", func(t *testing.T) {
+		var input map[string]NotificationPreferenceInput
+		if err := json.Unmarshal([]byte(`{"mentioned":false,"task_assigned":{"email":false}}`), &input); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if mentioned := input["mentioned"]; !equalBoolPtr(mentioned.Enabled, boolPtr(false)) || mentioned.Email != nil {
+			t.Errorf("mentioned = %+v, want only Enabled false", mentioned)
+		}
+		if assigned := input["task_assigned"]; assigned.Enabled != nil || !equalBoolPtr(assigned.Email, boolPtr(false)) {
+			t.Errorf("task_assigned = %+v, want only Email false", assigned)
+		}
+	})
+}
+
+func TestNotificationTypes(t *testing.T) {
+	for _, notificationType := range NotificationTypes {
+		if !IsValidNotificationType(notificationType) {
+			t.Errorf("IsValidNotificationType(%q) = false", notificationType)
+		}
+	}
+	for _, emailed := range NotificationEmailTypes {
+		if !IsValidNotificationType(emailed) {
+			t.Errorf("emailed type %q can't be turned off", emailed)
+		}
+	}
+
+	if IsValidNotificationType("task_deleted") {
+		t.Error("unknown type is valid")
+	}
+	if IsEmailNotification(NotificationTaskStatusChanged) {
+		t.Error("status changes are emailed")
+	}
+	if !IsEmailNotification(NotificationTaskAssigned) {
+		t.Error("assignments aren't emailed")
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func equalBoolPtr(a, b *bool) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
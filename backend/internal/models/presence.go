@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fields that can be soft-locked for editing
+const (
+	LockFieldTitle       = "title"
+	LockFieldDescription = "description"
+)
+
+// IsValidLockField reports whether field can be soft-locked
+func IsValidLockField(field string) bool {
+	return field == LockFieldTitle || field == LockFieldDescription
+}
+
+// BoardSession is one user's open collaboration socket on a board
+type BoardSession struct {
+	ID          uuid.UUID  `json:"session_id" db:"id"`
+	BoardID     uuid.UUID  `json:"-" db:"board_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	FullName    *string    `json:"full_name,omitempty"`
+	TaskID      *uuid.UUID `json:"task_id,omitempty" db:"task_id"`
+	ConnectedAt time.Time  `json:"connected_at" db:"connected_at"`
+}
+
+// TaskEditLock is a soft lock a session holds on one field of a task
+type TaskEditLock struct {
+	TaskID     uuid.UUID `json:"task_id" db:"task_id"`
+	Field      string    `json:"field" db:"field"`
+	SessionID  uuid.UUID `json:"session_id" db:"session_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	FullName   *string   `json:"full_name,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at" db:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// BoardPresence is everyone connected to a board and the locks they hold
+type BoardPresence struct {
+	BoardID  uuid.UUID      `json:"board_id"`
+	Sessions []BoardSession `json:"sessions"`
+	Locks    []TaskEditLock `json:"locks"`
+}
+
+// Presence message types a client can send over a board socket
+const (
+	PresenceOpenTask  = "open_task"
+	PresenceCloseTask = "close_task"
+	PresenceLock      = "lock"
+	PresenceUnlock    = "unlock"
+)
+
+// PresenceClientMessage is a message a client sends over a board socket
+type PresenceClientMessage struct {
+	Type   string     `json:"type"`
+	TaskID *uuid.UUID `json:"task_id,omitempty"`
+	Field  string     `json:"field,omitempty"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fields a search can match in, reported in TaskSearchResult.Matches
+const (
+	SearchFieldTitle                 = "title"
+	SearchFieldDescription           = "description"
+	SearchFieldNotes                 = "notes"
+	SearchFieldImplementationDetails = "implementation_details"
+	SearchFieldAcceptanceCriteria    = "acceptance_criteria"
+	SearchFieldComments              = "comments"
+)
+
+// TaskSearchResult is a task matching a full-text search. Highlights are
+// HTML-escaped with the matched words wrapped in <mark>.
+type TaskSearchResult struct {
+	TaskID   uuid.UUID  `json:"task_id"`
+	BoardID  *uuid.UUID `json:"board_id,omitempty"`
+	Title    string     `json:"title"`
+	StatusID int32      `json:"status_id"`
+	Rank     float32    `json:"rank"`
+	// TitleHighlight is the title with its matches marked
+	TitleHighlight string `json:"title_highlight"`
+	// Snippet is the best matching passages of the task's other text
+	Snippet string `json:"snippet"`
+	// Matches lists the fields the search matched in
+	Matches   []string  `json:"matches"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskSearchPage is one page of search results, best match first
+type TaskSearchPage struct {
+	Items      []TaskSearchResult `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models/task"
 )
 
 // Task represents a task in the system
@@ -17,8 +18,23 @@ type Task struct {
 	OwnerID     *uuid.UUID   `json:"owner_id,omitempty" db:"owner_id"`
 	ParentID    *uuid.UUID   `json:"parent_id,omitempty" db:"parent_id"`
 	BoardID     *uuid.UUID   `json:"board_id,omitempty" db:"board_id"`
-	OrderIndex  int32        `json:"order_index" db:"order_index"`
+	// OrderIndex ranks the task within its column. Ranks are spaced out so a
+	// move only has to rewrite the moved task.
+	OrderIndex  float64      `json:"order_index" db:"order_index"`
 	Content     TaskContent  `json:"content"`
+	Labels      []Label      `json:"labels"`
+	Collaborators []task.Collaborator `json:"collaborators"`
+	Progress    *TaskProgress `json:"progress,omitempty"`
+	// Mentions are the resolved @mentions in the task's description, notes
+	// and implementation details
+	Mentions    []Mention    `json:"mentions"`
+	// Attachments are the task's files, with thumbnails for images
+	Attachments []Attachment `json:"attachments"`
+	// Owner, Assignee, Status and Subtasks are embedded on request
+	Owner       *UserResponse `json:"owner,omitempty"`
+	Assignee    *UserResponse `json:"assignee,omitempty"`
+	Status      *TaskStatus   `json:"status,omitempty"`
+	Subtasks    []*Task       `json:"subtasks,omitempty"`
 	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
 }
@@ -31,6 +47,7 @@ type CreateTaskInput struct {
 	PriorityID  int32                 `json:"priority_id" validate:"required"`
 	TypeID      int32                 `json:"type_id" validate:"required"`
 	BoardID     *uuid.UUID            `json:"board_id,omitempty"`
+	ParentID    *uuid.UUID            `json:"parent_id,omitempty"`
 	Content     CreateTaskContentInput `json:"content" validate:"required"`
 }
 
@@ -42,16 +59,43 @@ type UpdateTaskInput struct {
 	PriorityID  *int32                  `json:"priority_id,omitempty"`
 	TypeID      *int32                  `json:"type_id,omitempty"`
 	BoardID     *uuid.UUID              `json:"board_id,omitempty"`
+	ParentID    *uuid.UUID              `json:"parent_id,omitempty"`
+	// RemoveParent turns a subtask back into a top-level task
+	RemoveParent bool                   `json:"remove_parent,omitempty"`
+	// Order is the task's zero-based position in its column. BeforeID and
+	// AfterID place it directly before or after another task in the column
+	// instead.
 	Order       *int                    `json:"order,omitempty"`
+	BeforeID    *uuid.UUID              `json:"before_id,omitempty"`
+	AfterID     *uuid.UUID              `json:"after_id,omitempty"`
 	Content     *UpdateTaskContentInput `json:"content,omitempty"`
+	// OverrideDoneGate lets a board admin move the task into done even if it
+	// doesn't meet the definition of done. Overrides are recorded.
+	OverrideDoneGate bool               `json:"override_done_gate,omitempty"`
+	OverrideReason   string             `json:"override_reason,omitempty"`
 }
 
 // TaskMoveInput represents the input for moving a task
 type TaskMoveInput struct {
-	StatusID int32  `json:"status_id" binding:"required"`
-	Order    int32  `json:"order" binding:"required"`
+	StatusID int32      `json:"status_id" binding:"required"`
+	Order    *int32     `json:"order,omitempty"`
+	BeforeID *uuid.UUID `json:"before_id,omitempty"`
+	AfterID  *uuid.UUID `json:"after_id,omitempty"`
 	Comment  string `json:"comment,omitempty"`
 	Type     string `json:"type,omitempty"`
+	OverrideDoneGate bool   `json:"override_done_gate,omitempty"`
+	OverrideReason   string `json:"override_reason,omitempty"`
+}
+
+// AddCollaboratorInput represents the input for adding a collaborator to a task
+type AddCollaboratorInput struct {
+	UserID uuid.UUID             `json:"user_id" binding:"required"`
+	Role   task.CollaboratorRole `json:"role" binding:"required,oneof=viewer editor admin"`
+}
+
+// UpdateCollaboratorInput represents the input for changing a collaborator's role
+type UpdateCollaboratorInput struct {
+	Role task.CollaboratorRole `json:"role" binding:"required,oneof=viewer editor admin"`
 }
 
 // NewTask creates a new task from input
@@ -66,18 +110,50 @@ func NewTask(input CreateTaskInput, ownerID uuid.UUID) *Task {
 		TypeID:      input.TypeID,
 		OwnerID:     &ownerID,
 		BoardID:     input.BoardID,
+		ParentID:    input.ParentID,
 		Content:     TaskContent{},  // Will be populated separately
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 }
 
+// RoleFor returns the effective role a user has on the task given their role
+// on the task's board. The task owner is an admin; everyone else gets the
+// higher of their collaborator role and their board role. Ownership and
+// collaborator roles don't outlast board membership, so on a board's task a
+// user without a board role has no role. An empty role means the user has no
+// role on the task.
+func (t *Task) RoleFor(userID uuid.UUID, boardRole BoardRole) BoardRole {
+	if t.BoardID != nil && boardRole == "" {
+		return ""
+	}
+
+	if t.OwnerID != nil && *t.OwnerID == userID {
+		return BoardRoleAdmin
+	}
+
+	role := boardRole
+	for _, collab := range t.Collaborators {
+		if collab.UserID == userID && !role.AtLeast(BoardRole(collab.Role)) {
+			role = BoardRole(collab.Role)
+		}
+	}
+
+	return role
+}
+
 // CanUserEdit checks if a user has edit permissions for the task
-func (t *Task) CanUserEdit(userID uuid.UUID) bool {
-	return t.OwnerID != nil && *t.OwnerID == userID
+func (t *Task) CanUserEdit(userID uuid.UUID, boardRole BoardRole) bool {
+	return t.RoleFor(userID, boardRole).AtLeast(BoardRoleEditor)
 }
 
 // CanUserAdmin checks if a user has admin permissions for the task
-func (t *Task) CanUserAdmin(userID uuid.UUID) bool {
-	return t.OwnerID != nil && *t.OwnerID == userID
-}
\ No newline at end of file
+func (t *Task) CanUserAdmin(userID uuid.UUID, boardRole BoardRole) bool {
+	return t.RoleFor(userID, boardRole).AtLeast(BoardRoleAdmin)
+}
+
+// TaskPage is one page of a task list
+type TaskPage struct {
+	Items      []*Task `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
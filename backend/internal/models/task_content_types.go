@@ -68,6 +68,14 @@ type UpdateAcceptanceCriterionInput struct {
 	Category    *string   `json:"category,omitempty"`
 	Notes       *string   `json:"notes,omitempty"`
 	Order       *int      `json:"order,omitempty"`
+	Completed   *bool     `json:"completed,omitempty"`
+}
+
+// AcceptanceCriterionResponse is returned by the acceptance criteria endpoints
+// together with the task's recomputed progress
+type AcceptanceCriterionResponse struct {
+	Criterion AcceptanceCriterion `json:"criterion"`
+	Progress  *TaskProgress       `json:"progress"`
 }
 
 // NewAcceptanceCriterion creates a new acceptance criterion from input
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Status codes for tasks
@@ -9,12 +11,20 @@ type StatusCode string
 
 const (
 	StatusBacklog    StatusCode = "backlog"
-	StatusTodo       StatusCode = "todo"
-	StatusInProgress StatusCode = "in_progress"
-	StatusBlocked    StatusCode = "blocked"
+	StatusInProgress StatusCode = "in-progress"
+	StatusReview     StatusCode = "review"
 	StatusDone       StatusCode = "done"
 )
 
+// StatusCategory groups statuses by how far along the work is
+type StatusCategory string
+
+const (
+	StatusCategoryTodo       StatusCategory = "todo"
+	StatusCategoryInProgress StatusCategory = "in_progress"
+	StatusCategoryDone       StatusCategory = "done"
+)
+
 // Priority codes for tasks
 type PriorityCode string
 
@@ -37,13 +47,17 @@ const (
 
 // TaskStatus represents a task status
 type TaskStatus struct {
-	ID           int32     `json:"id" db:"id"`
-	Code         string    `json:"code" db:"code"`
-	Name         string    `json:"name" db:"name"`
-	Description  *string   `json:"description,omitempty" db:"description"`
-	DisplayOrder int32     `json:"display_order" db:"display_order"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID           int32          `json:"id" db:"id"`
+	BoardID      *uuid.UUID     `json:"board_id,omitempty" db:"board_id"`
+	Code         string         `json:"code" db:"code"`
+	Name         string         `json:"name" db:"name"`
+	Description  *string        `json:"description,omitempty" db:"description"`
+	Color        *string        `json:"color,omitempty" db:"color"`
+	Icon         *string        `json:"icon,omitempty" db:"icon"`
+	Category     StatusCategory `json:"category" db:"category"`
+	DisplayOrder int32          `json:"display_order" db:"display_order"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
 }
 
 // TaskPriority represents a task priority
@@ -72,15 +86,33 @@ type TaskMetadata struct {
 	Column    *string   `json:"column,omitempty" db:"column"`
 }
 
-// TaskProgress represents the completion metrics of a task
+// TaskProgress represents the completion metrics of a task, rolled up across
+// the task and all of its subtasks. Percentage is based on acceptance criteria,
+// or on completed subtasks when the subtree has no criteria.
 type TaskProgress struct {
 	AcceptanceCriteria struct {
 		Total     int `json:"total"`
 		Completed int `json:"completed"`
 	} `json:"acceptance_criteria"`
+	Subtasks struct {
+		Total     int `json:"total"`
+		Completed int `json:"completed"`
+	} `json:"subtasks"`
 	Percentage int `json:"percentage"`
 }
 
+// CalculatePercentage sets Percentage from the criteria and subtask counts
+func (p *TaskProgress) CalculatePercentage() {
+	switch {
+	case p.AcceptanceCriteria.Total > 0:
+		p.Percentage = p.AcceptanceCriteria.Completed * 100 / p.AcceptanceCriteria.Total
+	case p.Subtasks.Total > 0:
+		p.Percentage = p.Subtasks.Completed * 100 / p.Subtasks.Total
+	default:
+		p.Percentage = 0
+	}
+}
+
 // TaskRelationships represents task relationships
 type TaskRelationships struct {
 	Parent       *string   `json:"parent,omitempty" db:"parent"`
@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestTaskProgressCalculatePercentage(t *testing.T) {
+	progress := func(criteria, criteriaDone, subtasks, subtasksDone int) *TaskProgress {
+		p := &TaskProgress{}
+		p.AcceptanceCriteria.Total, p.AcceptanceCriteria.Completed = criteria, criteriaDone
+		p.Subtasks.Total, p.Subtasks.Completed = subtasks, subtasksDone
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		progress *TaskProgress
+		want     int
+	}{
+		{"nothing to track", progress(0, 0, 0, 0), 0},
+		{"criteria", progress(4, 1, 0, 0), 25},
+		{"criteria round down", progress(3, 2, 0, 0), 66},
+		{"all criteria", progress(2, 2, 0, 0), 100},
+		{"criteria win over subtasks", progress(2, 0, 2, 2), 0},
+		{"subtasks without criteria", progress(0, 0, 4, 3), 75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.progress.CalculatePercentage()
+			if tt.progress.Percentage != tt.want {
+				t.Errorf("Percentage = %d, want %d", tt.progress.Percentage, tt.want)
+			}
+		})
+	}
+}
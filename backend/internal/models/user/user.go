@@ -32,6 +32,9 @@ type User struct {
 	Email        string     `json:"email" db:"email"`
 	PasswordHash string     `json:"-" db:"password_hash"`
 	FullName     string     `json:"full_name" db:"full_name"`
+	// Handle is how the user is @mentioned. It's derived from their email
+	// when they sign up.
+	Handle       string     `json:"handle" db:"handle"`
 	AvatarURL    string     `json:"avatar_url,omitempty" db:"avatar_url"`
 	RoleID       int        `json:"-" db:"role_id"`
 	Role         *UserRole  `json:"role" db:"-"`
@@ -40,6 +43,12 @@ type User struct {
 	LastLoginAt  *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
 }
 
+// UserPage is one page of a user list
+type UserPage struct {
+	Items      []*User `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
 // MarshalJSON implements the json.Marshaler interface
 func (u *User) MarshalJSON() ([]byte, error) {
 	type Alias User
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// View visibilities
+const (
+	// ViewVisibilityPrivate views are seen only by their owner
+	ViewVisibilityPrivate = "private"
+	// ViewVisibilityBoard views are shared with everyone who can see the board
+	ViewVisibilityBoard = "board"
+)
+
+// ViewColumns are the task fields a view can show as columns
+var ViewColumns = []string{
+	"title",
+	"status",
+	"priority",
+	"type",
+	"assignee",
+	"owner",
+	"due_date",
+	"labels",
+	"progress",
+	"created_at",
+	"updated_at",
+}
+
+// SavedView is a named task query with a sort and the columns to show
+type SavedView struct {
+	ID      uuid.UUID  `json:"id" db:"id"`
+	OwnerID uuid.UUID  `json:"owner_id" db:"owner_id"`
+	BoardID *uuid.UUID `json:"board_id,omitempty" db:"board_id"`
+	Name    string     `json:"name" db:"name"`
+	// Query is in the task query language used by GET /tasks?q=
+	Query      string   `json:"query" db:"query"`
+	Sort       string   `json:"sort" db:"sort"`
+	Columns    []string `json:"columns" db:"columns"`
+	Visibility string   `json:"visibility" db:"visibility"`
+	// Pinned is whether the requesting user has pinned the view
+	Pinned    bool      `json:"pinned"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateViewInput represents the input for creating a saved view. A view
+// with a board only shows tasks on that board.
+type CreateViewInput struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	BoardID    *uuid.UUID `json:"board_id,omitempty"`
+	Query      string     `json:"query"`
+	Sort       string     `json:"sort"`
+	Columns    []string   `json:"columns"`
+	Visibility string     `json:"visibility" binding:"omitempty,oneof=private board"`
+	Pinned     bool       `json:"pinned"`
+}
+
+// UpdateViewInput represents the input for updating a saved view. The board
+// can't be changed.
+type UpdateViewInput struct {
+	Name       *string  `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Query      *string  `json:"query,omitempty"`
+	Sort       *string  `json:"sort,omitempty"`
+	Columns    []string `json:"columns,omitempty"`
+	Visibility *string  `json:"visibility,omitempty" binding:"omitempty,oneof=private board"`
+}
+
+// IsValidViewColumn reports whether a view can show the column
+func IsValidViewColumn(column string) bool {
+	for _, c := range ViewColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
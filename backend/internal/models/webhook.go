@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook event types
+const (
+	WebhookTaskCreated = "task.created"
+	WebhookTaskUpdated = "task.updated"
+	WebhookTaskMoved   = "task.moved"
+	WebhookTaskDeleted = "task.deleted"
+)
+
+// WebhookEventTypes lists the event types a webhook can subscribe to
+var WebhookEventTypes = []string{
+	WebhookTaskCreated,
+	WebhookTaskUpdated,
+	WebhookTaskMoved,
+	WebhookTaskDeleted,
+}
+
+// IsValidWebhookEvent reports whether eventType is one webhooks can subscribe to
+func IsValidWebhookEvent(eventType string) bool {
+	for _, valid := range WebhookEventTypes {
+		if eventType == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidWebhookURL reports whether rawURL is an absolute http or https URL
+// with a host. Whether the host resolves to an address deliveries may be
+// sent to is checked by the dispatcher when it connects.
+func IsValidWebhookURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Hostname() != "" && u.User == nil
+}
+
+// WebhookDeliveryStatus is where a delivery is in its retry lifecycle
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending deliveries are waiting for their next attempt
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered deliveries got a 2xx response
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead deliveries ran out of attempts and won't be retried
+	// unless they're redelivered
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// Webhook subscribes a URL to events on a board. The secret is only
+// returned when the webhook is created.
+type Webhook struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	BoardID   uuid.UUID  `json:"board_id" db:"board_id"`
+	URL       string     `json:"url" db:"url"`
+	Secret    string     `json:"secret,omitempty" db:"secret"`
+	Events    []string   `json:"events" db:"events"`
+	IsActive  bool       `json:"is_active" db:"is_active"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CreateWebhookInput represents the input for creating a webhook. A secret
+// is generated when none is given.
+type CreateWebhookInput struct {
+	URL      string   `json:"url" binding:"required,url"`
+	Events   []string `json:"events" binding:"required,min=1"`
+	Secret   *string  `json:"secret,omitempty" binding:"omitempty,min=16,max=255"`
+	IsActive *bool    `json:"is_active,omitempty"`
+}
+
+// UpdateWebhookInput represents the input for updating a webhook
+type UpdateWebhookInput struct {
+	URL      *string  `json:"url,omitempty" binding:"omitempty,url"`
+	Events   []string `json:"events,omitempty" binding:"omitempty,min=1"`
+	Secret   *string  `json:"secret,omitempty" binding:"omitempty,min=16,max=255"`
+	IsActive *bool    `json:"is_active,omitempty"`
+}
+
+// WebhookDelivery is one attempt-tracked send of an event to a webhook
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id" db:"id"`
+	WebhookID      uuid.UUID             `json:"webhook_id" db:"webhook_id"`
+	EventID        uuid.UUID             `json:"event_id" db:"event_id"`
+	EventType      string                `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+	ResponseStatus *int                  `json:"response_status,omitempty" db:"response_status"`
+	LastError      *string               `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+}
+
+// WebhookDeliveryPage is one page of a webhook's delivery log, newest first
+type WebhookDeliveryPage struct {
+	Items      []WebhookDelivery `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// WebhookEvent is the body POSTed to a webhook
+type WebhookEvent struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	BoardID   uuid.UUID `json:"board_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// PendingWebhookDelivery is a due delivery along with where to send it
+type PendingWebhookDelivery struct {
+	ID        uuid.UUID
+	WebhookID uuid.UUID
+	EventID   uuid.UUID
+	EventType string
+	Payload   []byte
+	Attempts  int
+	URL       string
+	Secret    string
+}
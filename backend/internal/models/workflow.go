@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusTransition allows tasks on a board to move from one status to another
+type StatusTransition struct {
+	FromStatusID int32 `json:"from_status_id" binding:"required"`
+	ToStatusID   int32 `json:"to_status_id" binding:"required"`
+}
+
+// CreateStatusInput represents the input for adding a status to a board's workflow
+type CreateStatusInput struct {
+	Code         string         `json:"code" binding:"required,max=50"`
+	Name         string         `json:"name" binding:"required,max=100"`
+	Description  *string        `json:"description,omitempty"`
+	Color        *string        `json:"color,omitempty" binding:"omitnil,hexcolor,len=7"`
+	Icon         *string        `json:"icon,omitempty" binding:"omitempty,max=50"`
+	Category     StatusCategory `json:"category" binding:"required,oneof=todo in_progress done"`
+	DisplayOrder *int32         `json:"display_order,omitempty"`
+}
+
+// UpdateStatusInput represents the input for updating a status in a board's workflow
+type UpdateStatusInput struct {
+	Code         *string         `json:"code,omitempty" binding:"omitempty,min=1,max=50"`
+	Name         *string         `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Description  *string         `json:"description,omitempty"`
+	Color        *string         `json:"color,omitempty" binding:"omitnil,hexcolor,len=7"`
+	Icon         *string         `json:"icon,omitempty" binding:"omitempty,max=50"`
+	Category     *StatusCategory `json:"category,omitempty" binding:"omitempty,oneof=todo in_progress done"`
+	DisplayOrder *int32          `json:"display_order,omitempty"`
+}
+
+// SetTransitionsInput replaces a board's transition matrix. An empty list
+// lets tasks move between any two statuses.
+type SetTransitionsInput struct {
+	Transitions []StatusTransition `json:"transitions" binding:"dive"`
+}
+
+// WIPLimitMode controls what happens when a column reaches its WIP limit
+type WIPLimitMode string
+
+const (
+	// WIPLimitSoft lets tasks into a full column but warns about it
+	WIPLimitSoft WIPLimitMode = "soft"
+	// WIPLimitHard keeps tasks out of a full column
+	WIPLimitHard WIPLimitMode = "hard"
+)
+
+// WIPLimit caps the number of tasks in one status column of a board
+type WIPLimit struct {
+	BoardID   uuid.UUID    `json:"board_id" db:"board_id"`
+	StatusID  int32        `json:"status_id" db:"status_id"`
+	Limit     int32        `json:"limit" db:"limit_count"`
+	Mode      WIPLimitMode `json:"mode" db:"mode"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// SetWIPLimitInput represents the input for setting a column's WIP limit
+type SetWIPLimitInput struct {
+	Limit int32        `json:"limit" binding:"required,min=1"`
+	Mode  WIPLimitMode `json:"mode,omitempty" binding:"omitempty,oneof=soft hard"`
+}
+
+// BoardColumn is a status column on a board with its task count and WIP limit
+type BoardColumn struct {
+	StatusID  int32          `json:"status_id"`
+	Code      string         `json:"code"`
+	Name      string         `json:"name"`
+	Category  StatusCategory `json:"category"`
+	TaskCount int32          `json:"task_count"`
+	WIPLimit  *int32         `json:"wip_limit,omitempty"`
+	WIPMode   *WIPLimitMode  `json:"wip_mode,omitempty"`
+	OverLimit bool           `json:"over_limit"`
+}
@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+const acceptanceCriterionColumns = `
+	id,
+	description,
+	completed,
+	completed_at,
+	completed_by,
+	order_index,
+	category,
+	notes,
+	created_at,
+	updated_at`
+
+// CreateAcceptanceCriterion adds an acceptance criterion to the end of a task's list
+func (r *TaskRepository) CreateAcceptanceCriterion(ctx context.Context, taskID uuid.UUID, input *models.CreateAcceptanceCriterionInput) (*models.AcceptanceCriterion, error) {
+	ac := models.NewAcceptanceCriterion(*input)
+
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, `
+		INSERT INTO acceptance_criteria (
+			id,
+			task_id,
+			description,
+			completed,
+			order_index,
+			category,
+			notes,
+			created_at,
+			updated_at
+		)
+		SELECT $1, $2, $3, false, COALESCE(MAX(order_index) + 1, 0), $4, $5, $6, $7
+		FROM acceptance_criteria
+		WHERE task_id = $2
+		RETURNING `+acceptanceCriterionColumns,
+		ac.ID,
+		taskID,
+		ac.Description,
+		ac.Category,
+		ac.Notes,
+		ac.CreatedAt,
+		ac.UpdatedAt,
+	)
+	created, err := scanAcceptanceCriterion(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordTaskActivity(ctx, tx, taskID, models.ActivityCriterionAdded, nil, map[string]any{
+		"criterion_id": created.ID,
+		"description":  created.Description,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return created, nil
+}
+
+// UpdateAcceptanceCriterion rewords, toggles or moves an acceptance criterion.
+// Completing a criterion records who completed it and when; Order is the new
+// zero-based position in the task's list.
+func (r *TaskRepository) UpdateAcceptanceCriterion(ctx context.Context, taskID uuid.UUID, input *models.UpdateAcceptanceCriterionInput, userID uuid.UUID) (*models.AcceptanceCriterion, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := scanAcceptanceCriterion(tx.QueryRow(ctx, `
+		SELECT `+acceptanceCriterionColumns+`
+		FROM acceptance_criteria
+		WHERE task_id = $1 AND id = $2
+		FOR UPDATE
+	`, taskID, input.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	completed, completedAt, completedBy := criterionCompletion(current, input.Completed, userID)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE acceptance_criteria
+		SET
+			description = COALESCE($1, description),
+			category = COALESCE($2, category),
+			notes = COALESCE($3, notes),
+			completed = $4,
+			completed_at = CASE WHEN $4 THEN COALESCE($5, CURRENT_TIMESTAMP) END,
+			completed_by = $6,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+	`, input.Description, input.Category, input.Notes, completed, completedAt, completedBy, input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating acceptance criterion: %v", err)
+	}
+
+	if input.Order != nil {
+		if err := moveAcceptanceCriterion(ctx, tx, taskID, input.ID, *input.Order); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := scanAcceptanceCriterion(tx.QueryRow(ctx, `
+		SELECT `+acceptanceCriterionColumns+`
+		FROM acceptance_criteria
+		WHERE id = $1
+	`, input.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	var changes fieldChanges
+	changes.add("description", current.Description, updated.Description)
+	changes.add("completed", current.Completed, updated.Completed)
+	changes.add("order", current.Order, updated.Order)
+	changes.add("category", current.Category, updated.Category)
+	changes.add("notes", current.Notes, updated.Notes)
+	if len(changes) > 0 {
+		if err := recordTaskActivity(ctx, tx, taskID, models.ActivityCriterionUpdated, changes, map[string]any{"criterion_id": updated.ID}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteAcceptanceCriterion removes an acceptance criterion and closes the gap
+// it leaves in the task's ordering
+func (r *TaskRepository) DeleteAcceptanceCriterion(ctx context.Context, taskID uuid.UUID, id uuid.UUID) error {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var description string
+	err = tx.QueryRow(ctx, `
+		DELETE FROM acceptance_criteria
+		WHERE task_id = $1 AND id = $2
+		RETURNING description
+	`, taskID, id).Scan(&description)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("error deleting acceptance criterion: %v", err)
+	}
+
+	if err := recordTaskActivity(ctx, tx, taskID, models.ActivityCriterionRemoved, nil, map[string]any{
+		"criterion_id": id,
+		"description":  description,
+	}); err != nil {
+		return err
+	}
+
+	ids, err := orderedCriterionIDs(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+	if err := renumberAcceptanceCriteria(ctx, tx, ids); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// GetTaskProgress computes the completion rollup for a single task
+func (r *TaskRepository) GetTaskProgress(ctx context.Context, taskID uuid.UUID) (*models.TaskProgress, error) {
+	progress, err := progressForTasks(ctx, r.db, []uuid.UUID{taskID})
+	if err != nil {
+		return nil, err
+	}
+
+	if progress[taskID] == nil {
+		return &models.TaskProgress{}, nil
+	}
+	return progress[taskID], nil
+}
+
+// moveAcceptanceCriterion moves a criterion to a zero-based position in its
+// task's list and renumbers the rest around it
+func moveAcceptanceCriterion(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, id uuid.UUID, position int) error {
+	ids, err := orderedCriterionIDs(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+
+	return renumberAcceptanceCriteria(ctx, tx, moveToPosition(ids, id, position))
+}
+
+// moveToPosition returns ids with id moved to a zero-based position, clamped
+// to the list
+func moveToPosition(ids []uuid.UUID, id uuid.UUID, position int) []uuid.UUID {
+	others := make([]uuid.UUID, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			others = append(others, other)
+		}
+	}
+
+	if position < 0 {
+		position = 0
+	}
+	if position > len(others) {
+		position = len(others)
+	}
+
+	ordered := make([]uuid.UUID, 0, len(others)+1)
+	ordered = append(ordered, others[:position]...)
+	ordered = append(ordered, id)
+	ordered = append(ordered, others[position:]...)
+	return ordered
+}
+
+// criterionCompletion returns a criterion's completion columns after an
+// optional toggle. Completing stamps the user, and the time is left for the
+// database to fill in; a toggle to the current state keeps the old stamp.
+func criterionCompletion(current *models.AcceptanceCriterion, toggle *bool, userID uuid.UUID) (bool, *time.Time, *uuid.UUID) {
+	if toggle == nil || *toggle == current.Completed {
+		return current.Completed, current.CompletedAt, current.CompletedBy
+	}
+	if *toggle {
+		return true, nil, &userID
+	}
+	return false, nil, nil
+}
+
+// orderedCriterionIDs returns the IDs of a task's criteria in their current order
+func orderedCriterionIDs(ctx context.Context, tx pgx.Tx, taskID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id
+		FROM acceptance_criteria
+		WHERE task_id = $1
+		ORDER BY order_index, created_at
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying acceptance criteria order: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning acceptance criterion ID: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating acceptance criteria order: %v", err)
+	}
+
+	return ids, nil
+}
+
+// renumberAcceptanceCriteria sets order_index to each criterion's position in ids
+func renumberAcceptanceCriteria(ctx context.Context, tx pgx.Tx, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE acceptance_criteria ac
+		SET order_index = (v.position - 1)::int
+		FROM unnest($1::uuid[]) WITH ORDINALITY AS v(id, position)
+		WHERE ac.id = v.id AND ac.order_index <> (v.position - 1)::int
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("error reordering acceptance criteria: %v", err)
+	}
+
+	return nil
+}
+
+// criteriaForTasks loads the acceptance criteria of the given tasks, in order
+func criteriaForTasks(ctx context.Context, db *pgxpool.Pool, taskIDs []uuid.UUID) (map[uuid.UUID][]models.AcceptanceCriterion, error) {
+	rows, err := db.Query(ctx, `
+		SELECT task_id, `+acceptanceCriterionColumns+`
+		FROM acceptance_criteria
+		WHERE task_id = ANY($1)
+		ORDER BY task_id, order_index
+	`, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying acceptance criteria: %v", err)
+	}
+	defer rows.Close()
+
+	criteria := make(map[uuid.UUID][]models.AcceptanceCriterion)
+	for rows.Next() {
+		var taskID uuid.UUID
+		var ac models.AcceptanceCriterion
+		err := rows.Scan(
+			&taskID,
+			&ac.ID,
+			&ac.Description,
+			&ac.Completed,
+			&ac.CompletedAt,
+			&ac.CompletedBy,
+			&ac.Order,
+			&ac.Category,
+			&ac.Notes,
+			&ac.CreatedAt,
+			&ac.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning acceptance criterion row: %v", err)
+		}
+		criteria[taskID] = append(criteria[taskID], ac)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating acceptance criteria rows: %v", err)
+	}
+
+	return criteria, nil
+}
+
+// scanAcceptanceCriterion scans a row selected with acceptanceCriterionColumns
+func scanAcceptanceCriterion(row pgx.Row) (*models.AcceptanceCriterion, error) {
+	var ac models.AcceptanceCriterion
+	err := row.Scan(
+		&ac.ID,
+		&ac.Description,
+		&ac.Completed,
+		&ac.CompletedAt,
+		&ac.CompletedBy,
+		&ac.Order,
+		&ac.Category,
+		&ac.Notes,
+		&ac.CreatedAt,
+		&ac.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error scanning acceptance criterion: %v", err)
+	}
+
+	return &ac, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestMoveToPosition(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	ids := []uuid.UUID{a, b, c}
+
+	tests := []struct {
+		name     string
+		id       uuid.UUID
+		position int
+		want     []uuid.UUID
+	}{
+		{"to the front", c, 0, []uuid.UUID{c, a, b}},
+		{"to the end", a, 2, []uuid.UUID{b, c, a}},
+		{"into the middle", a, 1, []uuid.UUID{b, a, c}},
+		{"to where it is", b, 1, ids},
+		{"before the front", b, -3, []uuid.UUID{b, a, c}},
+		{"past the end", a, 10, []uuid.UUID{b, c, a}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := moveToPosition(ids, tt.id, tt.position); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moveToPosition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCriterionCompletion(t *testing.T) {
+	userID, otherID := uuid.New(), uuid.New()
+	completedAt := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	boolPtr := func(b bool) *bool { return &b }
+
+	open := &models.AcceptanceCriterion{}
+	done := &models.AcceptanceCriterion{Completed: true, CompletedAt: &completedAt, CompletedBy: &otherID}
+
+	tests := []struct {
+		name            string
+		current         *models.AcceptanceCriterion
+		toggle          *bool
+		wantCompleted   bool
+		wantCompletedAt *time.Time
+		wantCompletedBy *uuid.UUID
+	}{
+		{"completing stamps the user", open, boolPtr(true), true, nil, &userID},
+		{"reopening clears the stamp", done, boolPtr(false), false, nil, nil},
+		{"completing again keeps the stamp", done, boolPtr(true), true, &completedAt, &otherID},
+		{"reopening an open criterion", open, boolPtr(false), false, nil, nil},
+		{"no toggle keeps the stamp", done, nil, true, &completedAt, &otherID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completed, at, by := criterionCompletion(tt.current, tt.toggle, userID)
+			if completed != tt.wantCompleted || !reflect.DeepEqual(at, tt.wantCompletedAt) || !reflect.DeepEqual(by, tt.wantCompletedBy) {
+				t.Errorf("criterionCompletion() = (%v, %v, %v), want (%v, %v, %v)", completed, at, by, tt.wantCompleted, tt.wantCompletedAt, tt.wantCompletedBy)
+			}
+		})
+	}
+}
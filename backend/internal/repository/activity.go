@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// RequestInfo identifies who is making a change and from where, so
+// repositories can attribute activity without every method taking an actor
+type RequestInfo struct {
+	ActorID   *uuid.UUID
+	IPAddress string
+	UserAgent string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo returns a context carrying the request info for activity events
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// requestInfoFrom returns the request info on ctx, or an empty one
+func requestInfoFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
+
+// clientColumns returns the IP address and user agent as nullable column
+// values, trimming the user agent to fit its column
+func (info RequestInfo) clientColumns() (*string, *string) {
+	var ipAddress, userAgent *string
+	if info.IPAddress != "" {
+		ipAddress = &info.IPAddress
+	}
+	if info.UserAgent != "" {
+		ua := info.UserAgent
+		if len(ua) > 255 {
+			ua = ua[:255]
+		}
+		userAgent = &ua
+	}
+	return ipAddress, userAgent
+}
+
+// ActivityRepository handles reading board and task activity feeds
+type ActivityRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewActivityRepository creates a new activity repository
+func NewActivityRepository(db *pgxpool.Pool) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+// ListBoardActivity retrieves a page of a board's activity, newest first
+func (r *ActivityRepository) ListBoardActivity(ctx context.Context, boardID uuid.UUID, cursor string, limit int) (*models.ActivityPage, error) {
+	return r.listActivity(ctx, "a.board_id", boardID, cursor, limit)
+}
+
+// ListTaskActivity retrieves a page of a task's activity, newest first
+func (r *ActivityRepository) ListTaskActivity(ctx context.Context, taskID uuid.UUID, cursor string, limit int) (*models.ActivityPage, error) {
+	return r.listActivity(ctx, "a.task_id", taskID, cursor, limit)
+}
+
+// listActivity pages through the events whose scope column matches id
+func (r *ActivityRepository) listActivity(ctx context.Context, scope string, id uuid.UUID, cursor string, limit int) (*models.ActivityPage, error) {
+	keyset := newestFirst("a.created_at", "a.id")
+	query := activitySelect + `
+		WHERE ` + scope + ` = $1`
+	args := []interface{}{id}
+
+	if cursor != "" {
+		key, err := keyset.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, key)
+		query += " AND " + keyset.After(len(args))
+	}
+
+	// Fetch one extra row to know whether there's another page
+	query += keyset.OrderBy() + fmt.Sprintf(" LIMIT %d", limit+1)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing activity: %v", err)
+	}
+	defer rows.Close()
+
+	items, err := scanActivities(rows, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ActivityPage{Items: items}
+	if len(page.Items) > limit {
+		last := page.Items[limit-1]
+		page.Items = page.Items[:limit]
+		page.NextCursor = keyset.CursorAt(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// LatestBoardActivitySeq returns the sequence number of the newest event on
+// a board's feed, or 0 when the feed is empty
+func (r *ActivityRepository) LatestBoardActivitySeq(ctx context.Context, boardID uuid.UUID) (int64, error) {
+	var seq int64
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(MAX(board_seq), 0)
+		FROM activity_logs
+		WHERE board_id = $1
+	`, boardID).Scan(&seq)
+	if err != nil {
+		return 0, fmt.Errorf("error getting latest activity: %v", err)
+	}
+	return seq, nil
+}
+
+// BoardActivitySeq returns the sequence number of event id on a board's
+// feed, or nil when it isn't on the feed
+func (r *ActivityRepository) BoardActivitySeq(ctx context.Context, boardID uuid.UUID, id uuid.UUID) (*int64, error) {
+	var seq int64
+	err := r.db.QueryRow(ctx, `
+		SELECT board_seq
+		FROM activity_logs
+		WHERE board_id = $1 AND id = $2
+	`, boardID, id).Scan(&seq)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error checking activity: %v", err)
+	}
+	return &seq, nil
+}
+
+// ListBoardActivityAfter retrieves up to limit of a board's events numbered
+// after afterSeq, in commit order. An afterSeq of 0 starts from the first
+// event.
+func (r *ActivityRepository) ListBoardActivityAfter(ctx context.Context, boardID uuid.UUID, afterSeq int64, limit int) ([]models.Activity, error) {
+	query := activitySelect + fmt.Sprintf(`
+		WHERE a.board_id = $1 AND a.board_seq > $2
+		ORDER BY a.board_seq
+		LIMIT %d`, limit)
+
+	rows, err := r.db.Query(ctx, query, boardID, afterSeq)
+	if err != nil {
+		return nil, fmt.Errorf("error listing activity: %v", err)
+	}
+	defer rows.Close()
+
+	return scanActivities(rows, limit)
+}
+
+// activitySelect selects activity with the actor's name for scanActivities
+const activitySelect = `
+		SELECT
+			a.id,
+			a.actor_id,
+			u.full_name,
+			a.entity_type,
+			a.entity_id,
+			a.board_id,
+			a.task_id,
+			a.action,
+			a.details,
+			a.board_seq,
+			a.created_at
+		FROM activity_logs a
+		LEFT JOIN users u ON u.id = a.actor_id`
+
+// scanActivities scans rows selected with activitySelect
+func scanActivities(rows pgx.Rows, sizeHint int) ([]models.Activity, error) {
+	activities := make([]models.Activity, 0, sizeHint)
+	for rows.Next() {
+		var activity models.Activity
+		var details []byte
+		err := rows.Scan(
+			&activity.ID,
+			&activity.ActorID,
+			&activity.ActorName,
+			&activity.EntityType,
+			&activity.EntityID,
+			&activity.BoardID,
+			&activity.TaskID,
+			&activity.Action,
+			&details,
+			&activity.BoardSeq,
+			&activity.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning activity: %v", err)
+		}
+		if err := unmarshalActivityDetails(details, &activity); err != nil {
+			return nil, err
+		}
+		activities = append(activities, activity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating activity: %v", err)
+	}
+
+	return activities, nil
+}
+
+// recordTaskActivity writes an event to a task's feed, and to its board's
+// feed if it has one. It must run before the task is deleted so the board
+// can still be looked up.
+func recordTaskActivity(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, action string, changes []models.FieldChange, details map[string]any) error {
+	return recordActivity(ctx, tx, models.ActivityEntityTask, taskID, &taskID, action, changes, details)
+}
+
+// recordBoardActivity writes an event to a board's feed
+func recordBoardActivity(ctx context.Context, tx pgx.Tx, boardID uuid.UUID, action string, changes []models.FieldChange, details map[string]any) error {
+	return recordActivity(ctx, tx, models.ActivityEntityBoard, boardID, nil, action, changes, details)
+}
+
+// recordActivity writes an activity event in the caller's transaction so the
+// event and the change it describes commit or roll back together. Updates
+// that didn't change anything aren't recorded. Events are stamped with the
+// wall clock rather than the transaction start so events written by one
+// transaction keep their order. Board events are numbered for event streams
+// by a deferred trigger as the caller commits, so the board's counter is only
+// locked during the commit.
+func recordActivity(ctx context.Context, tx pgx.Tx, entityType string, entityID uuid.UUID, taskID *uuid.UUID, action string, changes []models.FieldChange, details map[string]any) error {
+	if action == models.ActivityUpdated && len(changes) == 0 {
+		return nil
+	}
+
+	payload := make(map[string]any, len(details)+1)
+	for key, value := range details {
+		payload[key] = value
+	}
+	if len(changes) > 0 {
+		payload["changes"] = changes
+	}
+
+	detailsJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling activity details: %v", err)
+	}
+
+	info := requestInfoFrom(ctx)
+	ipAddress, userAgent := info.clientColumns()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO activity_logs (
+			actor_id,
+			entity_type,
+			entity_id,
+			board_id,
+			task_id,
+			action,
+			details,
+			ip_address,
+			user_agent,
+			created_at
+		)
+		VALUES (
+			$1, $2, $3,
+			CASE
+				WHEN $2 = 'board' THEN $3::uuid
+				ELSE (SELECT board_id FROM tasks WHERE id = $4)
+			END,
+			$4, $5, $6, $7, $8,
+			clock_timestamp()
+		)
+	`, info.ActorID, entityType, entityID, taskID, action, detailsJSON, ipAddress, userAgent)
+	if err != nil {
+		return fmt.Errorf("error recording activity: %v", err)
+	}
+
+	return nil
+}
+
+// unmarshalActivityDetails splits the stored details into field changes and
+// the remaining details
+func unmarshalActivityDetails(data []byte, activity *models.Activity) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var stored struct {
+		Changes []models.FieldChange `json:"changes"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("error unmarshaling activity changes: %v", err)
+	}
+	activity.Changes = stored.Changes
+
+	var details map[string]any
+	if err := json.Unmarshal(data, &details); err != nil {
+		return fmt.Errorf("error unmarshaling activity details: %v", err)
+	}
+	delete(details, "changes")
+	if len(details) > 0 {
+		activity.Details = details
+	}
+
+	return nil
+}
+
+// fieldChanges collects the before and after values of changed fields
+type fieldChanges []models.FieldChange
+
+// add records a change when before and after differ. Pointers are compared
+// and reported by the values they point to.
+func (c *fieldChanges) add(field string, before, after any) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	*c = append(*c, models.FieldChange{Field: field, Before: before, After: after})
+}
+
+// taskChanges diffs a task before and after an update. Content fields are
+// only compared when the update replaced the content.
+func taskChanges(before, after *models.Task, content *models.UpdateTaskContentInput) []models.FieldChange {
+	var changes fieldChanges
+	changes.add("title", before.Title, after.Title)
+	changes.add("description", before.Description, after.Description)
+	changes.add("status_id", before.StatusID, after.StatusID)
+	changes.add("priority_id", before.PriorityID, after.PriorityID)
+	changes.add("type_id", before.TypeID, after.TypeID)
+	changes.add("board_id", before.BoardID, after.BoardID)
+	changes.add("parent_id", before.ParentID, after.ParentID)
+	changes.add("order_index", before.OrderIndex, after.OrderIndex)
+
+	if content != nil {
+		changes.add("content.description", before.Content.Description, stringValue(content.Description))
+		changes.add("content.implementation_details", before.Content.ImplementationDetails, stringValue(content.ImplementationDetails))
+		changes.add("content.notes", before.Content.Notes, stringValue(content.Notes))
+		changes.add("content.attachments", nonNilStrings(before.Content.Attachments), nonNilStrings(content.Attachments))
+		changes.add("content.due_date", utcTime(before.Content.DueDate), utcTime(content.DueDate))
+		changes.add("content.assignee", before.Content.Assignee, content.Assignee)
+	}
+
+	return changes
+}
+
+// stringValue returns the string s points to, or "" when it's nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// nonNilStrings treats a nil slice as empty so it compares equal to []
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// utcTime normalizes a time so equal instants from different sources compare equal
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
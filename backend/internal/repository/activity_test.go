@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestTaskChanges(t *testing.T) {
+	boardID, assignee := uuid.New(), uuid.New()
+	due := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	dueElsewhere := due.In(time.FixedZone("UTC+2", 2*60*60))
+	strPtr := func(s string) *string { return &s }
+
+	before := &models.Task{
+		Title:      "Fix login",
+		StatusID:   1,
+		BoardID:    &boardID,
+		OrderIndex: 1024,
+		Content: models.TaskContent{
+			Description: "Users can't log in",
+			Notes:       "Seen on Safari",
+			DueDate:     &due,
+			Assignee:    &assignee,
+		},
+	}
+	sameContent := &models.UpdateTaskContentInput{
+		Description: strPtr("Users can't log in"),
+		Notes:       strPtr("Seen on Safari"),
+		Attachments: []string{},
+		DueDate:     &dueElsewhere,
+		Assignee:    &assignee,
+	}
+	with := func(change func(t *models.Task)) *models.Task {
+		after := *before
+		change(&after)
+		return &after
+	}
+
+	tests := []struct {
+		name       string
+		after      *models.Task
+		content    *models.UpdateTaskContentInput
+		wantFields []string
+	}{
+		{"nothing changed", with(func(t *models.Task) {}), nil, nil},
+		{"title and status", with(func(t *models.Task) { t.Title, t.StatusID = "Fix sign-in", 2 }), nil, []string{"title", "status_id"}},
+		{"moved off its board", with(func(t *models.Task) { t.BoardID = nil }), nil, []string{"board_id"}},
+		{"same board by another pointer", with(func(t *models.Task) { id := boardID; t.BoardID = &id }), nil, nil},
+		{"content cleared", with(func(t *models.Task) {}), &models.UpdateTaskContentInput{}, []string{"content.description", "content.notes", "content.due_date", "content.assignee"}},
+		{"same content in another time zone", with(func(t *models.Task) {}), sameContent, nil},
+		{"unassigned", with(func(t *models.Task) {}), &models.UpdateTaskContentInput{
+			Description: sameContent.Description,
+			Notes:       sameContent.Notes,
+			DueDate:     &due,
+		}, []string{"content.assignee"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, change := range taskChanges(before, tt.after, tt.content) {
+				fields = append(fields, change.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("changed fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+
+	t.Run("before and after values", func(t *testing.T) {
+		changes := taskChanges(before, with(func(t *models.Task) { t.Title = "Fix sign-in" }), nil)
+		want := []models.FieldChange{{Field: "title", Before: "Fix login", After: "Fix sign-in"}}
+		if !reflect.DeepEqual(changes, want) {
+			t.Errorf("taskChanges() = %+v, want %+v", changes, want)
+		}
+	})
+}
+
+func TestUnmarshalActivityDetails(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantChanges []models.FieldChange
+		wantDetails map[string]any
+	}{
+		{"empty", "", nil, nil},
+		{"details only", `{"title":"Fix login"}`, nil, map[string]any{"title": "Fix login"}},
+		{"changes only", `{"changes":[{"field":"status_id","before":1,"after":2}]}`, []models.FieldChange{{Field: "status_id", Before: float64(1), After: float64(2)}}, nil},
+		{
+			"changes and details",
+			`{"changes":[{"field":"completed","before":false,"after":true}],"criterion_id":"c1"}`,
+			[]models.FieldChange{{Field: "completed", Before: false, After: true}},
+			map[string]any{"criterion_id": "c1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var activity models.Activity
+			if err := unmarshalActivityDetails([]byte(tt.data), &activity); err != nil {
+				t.Fatalf("unmarshalActivityDetails() error = %v", err)
+			}
+			if !reflect.DeepEqual(activity.Changes, tt.wantChanges) {
+				t.Errorf("Changes = %+v, want %+v", activity.Changes, tt.wantChanges)
+			}
+			if !reflect.DeepEqual(activity.Details, tt.wantDetails) {
+				t.Errorf("Details = %v, want %v", activity.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestRequestInfoClientColumns(t *testing.T) {
+	ip, ua := RequestInfo{}.clientColumns()
+	if ip != nil || ua != nil {
+		t.Errorf("clientColumns() = (%v, %v), want nils", ip, ua)
+	}
+
+	ip, ua = RequestInfo{IPAddress: "203.0.113.7", UserAgent: strings.Repeat("a", 300)}.clientColumns()
+	if ip == nil || *ip != "203.0.113.7" {
+		t.Errorf("IP address = %v, want 203.0.113.7", ip)
+	}
+	if ua == nil || len(*ua) != 255 {
+		t.Errorf("user agent wasn't trimmed to 255 characters")
+	}
+}
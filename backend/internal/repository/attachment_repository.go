@@ -0,0 +1,468 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// AttachmentQuotaError is returned when an upload would take a board over its
+// attachment quota
+type AttachmentQuotaError struct {
+	UsedBytes  int64
+	QuotaBytes int64
+	Size       int64
+}
+
+func (e *AttachmentQuotaError) Error() string {
+	return "upload would exceed the attachment quota"
+}
+
+const attachmentColumns = `
+	id,
+	task_id,
+	storage_key,
+	filename,
+	content_type,
+	size_bytes,
+	sha256,
+	uploaded_by,
+	created_at,
+	thumbnail_status,
+	thumbnail_key,
+	thumbnail_content_type,
+	thumbnail_width,
+	thumbnail_height`
+
+// AttachmentRepository handles database operations for task attachments
+type AttachmentRepository struct {
+	db *pgxpool.Pool
+	// defaultQuota is the attachment quota, in bytes, of boards without
+	// their own
+	defaultQuota int64
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *pgxpool.Pool, defaultQuota int64) *AttachmentRepository {
+	return &AttachmentRepository{db: db, defaultQuota: defaultQuota}
+}
+
+// ListAttachments returns a task's attachments, oldest first
+func (r *AttachmentRepository) ListAttachments(ctx context.Context, taskID uuid.UUID) ([]models.Attachment, error) {
+	attachments, err := attachmentsForTasks(ctx, r.db, []uuid.UUID{taskID})
+	if err != nil {
+		return nil, err
+	}
+	if attachments[taskID] == nil {
+		return make([]models.Attachment, 0), nil
+	}
+	return attachments[taskID], nil
+}
+
+// GetAttachment returns one of a task's attachments
+func (r *AttachmentRepository) GetAttachment(ctx context.Context, taskID uuid.UUID, id uuid.UUID) (*models.Attachment, error) {
+	attachment, err := scanAttachment(r.db.QueryRow(ctx, `
+		SELECT `+attachmentColumns+`
+		FROM task_attachments
+		WHERE task_id = $1 AND id = $2
+	`, taskID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// CheckQuota reports whether an upload of size bytes to the task fits in its
+// quota. It's a cheap check before the upload is stored; CreateAttachment
+// checks again.
+func (r *AttachmentRepository) CheckQuota(ctx context.Context, task *models.Task, uploaderID uuid.UUID, size int64) error {
+	usage, err := r.usage(ctx, r.db, task.BoardID, uploaderID)
+	if err != nil {
+		return err
+	}
+	return checkAttachmentQuota(usage, size)
+}
+
+// CreateAttachment records an uploaded attachment. Uploads to the same board
+// are serialized so concurrent ones can't overshoot the quota together.
+func (r *AttachmentRepository) CreateAttachment(ctx context.Context, task *models.Task, attachment *models.Attachment) error {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	scope := "user:" + attachment.UploadedBy.String()
+	if task.BoardID != nil {
+		scope = "board:" + task.BoardID.String()
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('attachments:' || $1))`, scope); err != nil {
+		return fmt.Errorf("error locking attachment quota: %v", err)
+	}
+
+	usage, err := r.usage(ctx, tx, task.BoardID, *attachment.UploadedBy)
+	if err != nil {
+		return err
+	}
+	if err := checkAttachmentQuota(usage, attachment.Size); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO task_attachments (
+			id,
+			task_id,
+			storage_key,
+			filename,
+			content_type,
+			size_bytes,
+			sha256,
+			uploaded_by,
+			thumbnail_status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`,
+		attachment.ID,
+		attachment.TaskID,
+		attachment.StorageKey,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.SHA256,
+		attachment.UploadedBy,
+		attachment.ThumbnailStatus,
+	).Scan(&attachment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating attachment: %v", err)
+	}
+
+	if err := recordTaskActivity(ctx, tx, task.ID, models.ActivityAttachmentAdded, nil, map[string]any{
+		"attachment_id": attachment.ID,
+		"filename":      attachment.Filename,
+		"size":          attachment.Size,
+	}); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteAttachment deletes an attachment. Its blob is queued for removal by
+// a trigger.
+func (r *AttachmentRepository) DeleteAttachment(ctx context.Context, taskID uuid.UUID, id uuid.UUID) error {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var filename string
+	err = tx.QueryRow(ctx, `
+		DELETE FROM task_attachments
+		WHERE task_id = $1 AND id = $2
+		RETURNING filename
+	`, taskID, id).Scan(&filename)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("error deleting attachment: %v", err)
+	}
+
+	if err := recordTaskActivity(ctx, tx, taskID, models.ActivityAttachmentRemoved, nil, map[string]any{
+		"attachment_id": id,
+		"filename":      filename,
+	}); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// GetUsage returns how much of its attachment quota a board has used
+func (r *AttachmentRepository) GetUsage(ctx context.Context, boardID uuid.UUID) (*models.AttachmentUsage, error) {
+	return r.usage(ctx, r.db, &boardID, uuid.Nil)
+}
+
+// SetQuota gives a board its own attachment quota, or puts it back on the
+// default when quota is nil. Lowering a quota below current usage doesn't
+// remove anything; it only blocks further uploads.
+func (r *AttachmentRepository) SetQuota(ctx context.Context, boardID uuid.UUID, quota *int64) (*models.AttachmentUsage, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE boards SET attachment_quota_bytes = $1 WHERE id = $2
+	`, quota, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error setting attachment quota: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+
+	return r.GetUsage(ctx, boardID)
+}
+
+// checkAttachmentQuota returns an AttachmentQuotaError if size more bytes
+// wouldn't fit in the quota
+func checkAttachmentQuota(usage *models.AttachmentUsage, size int64) error {
+	if usage.UsedBytes+size > usage.QuotaBytes {
+		return &AttachmentQuotaError{UsedBytes: usage.UsedBytes, QuotaBytes: usage.QuotaBytes, Size: size}
+	}
+	return nil
+}
+
+// usage adds up the attachments counted against a quota: those on the
+// board's tasks or, for tasks without a board, those the uploader added to
+// tasks without a board
+func (r *AttachmentRepository) usage(ctx context.Context, db queryRower, boardID *uuid.UUID, uploaderID uuid.UUID) (*models.AttachmentUsage, error) {
+	usage := models.AttachmentUsage{QuotaBytes: r.defaultQuota}
+
+	if boardID == nil {
+		err := db.QueryRow(ctx, `
+			SELECT COALESCE(SUM(a.size_bytes), 0), COUNT(*)
+			FROM task_attachments a
+			JOIN tasks t ON t.id = a.task_id
+			WHERE t.board_id IS NULL AND a.uploaded_by = $1
+		`, uploaderID).Scan(&usage.UsedBytes, &usage.Count)
+		if err != nil {
+			return nil, fmt.Errorf("error getting attachment usage: %v", err)
+		}
+		return &usage, nil
+	}
+
+	var quota *int64
+	err := db.QueryRow(ctx, `
+		SELECT
+			b.attachment_quota_bytes,
+			(SELECT COALESCE(SUM(a.size_bytes), 0) FROM task_attachments a JOIN tasks t ON t.id = a.task_id WHERE t.board_id = b.id),
+			(SELECT COUNT(*) FROM task_attachments a JOIN tasks t ON t.id = a.task_id WHERE t.board_id = b.id)
+		FROM boards b
+		WHERE b.id = $1
+	`, boardID).Scan(&quota, &usage.UsedBytes, &usage.Count)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error getting attachment usage: %v", err)
+	}
+	if quota != nil {
+		usage.QuotaBytes = *quota
+		usage.Custom = true
+	}
+
+	return &usage, nil
+}
+
+// ClaimBlobDeletions leases a batch of blobs that are due to be removed from
+// storage. Claimed blobs aren't handed out again until the lease runs out.
+func (r *AttachmentRepository) ClaimBlobDeletions(ctx context.Context, limit int, lease time.Duration) ([]models.BlobDeletion, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM blob_deletions
+			WHERE next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE blob_deletions d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM due
+		WHERE d.id = due.id
+		RETURNING d.id, d.storage_key, d.attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming blob deletions: %v", err)
+	}
+	defer rows.Close()
+
+	deletions := make([]models.BlobDeletion, 0)
+	for rows.Next() {
+		var deletion models.BlobDeletion
+		if err := rows.Scan(&deletion.ID, &deletion.StorageKey, &deletion.Attempts); err != nil {
+			return nil, fmt.Errorf("error scanning blob deletion: %v", err)
+		}
+		deletions = append(deletions, deletion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blob deletions: %v", err)
+	}
+
+	return deletions, nil
+}
+
+// CompleteBlobDeletion removes a blob deletion once its blob is gone
+func (r *AttachmentRepository) CompleteBlobDeletion(ctx context.Context, id int64) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM blob_deletions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error completing blob deletion: %v", err)
+	}
+	return nil
+}
+
+// RetryBlobDeletion schedules another attempt at removing a blob
+func (r *AttachmentRepository) RetryBlobDeletion(ctx context.Context, id int64, nextAttemptAt time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE blob_deletions
+		SET attempts = attempts + 1, next_attempt_at = $1
+		WHERE id = $2
+	`, nextAttemptAt, id)
+	if err != nil {
+		return fmt.Errorf("error rescheduling blob deletion: %v", err)
+	}
+	return nil
+}
+
+// ClaimThumbnails leases a batch of attachments whose thumbnails are due to
+// be generated. Claimed attachments aren't handed out again until the lease
+// runs out.
+func (r *AttachmentRepository) ClaimThumbnails(ctx context.Context, limit int, lease time.Duration) ([]models.ThumbnailJob, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM task_attachments
+			WHERE thumbnail_status = 'pending' AND thumbnail_next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY thumbnail_next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE task_attachments a
+		SET thumbnail_next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM due
+		WHERE a.id = due.id
+		RETURNING a.id, a.task_id, a.storage_key, a.content_type, a.size_bytes, a.thumbnail_attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming thumbnails: %v", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]models.ThumbnailJob, 0)
+	for rows.Next() {
+		var job models.ThumbnailJob
+		err := rows.Scan(&job.AttachmentID, &job.TaskID, &job.StorageKey, &job.ContentType, &job.Size, &job.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning thumbnail job: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating thumbnail jobs: %v", err)
+	}
+
+	return jobs, nil
+}
+
+// CompleteThumbnail records an attachment's stored thumbnail. It returns
+// false if the attachment was deleted in the meantime, leaving the caller to
+// remove the thumbnail's blob.
+func (r *AttachmentRepository) CompleteThumbnail(ctx context.Context, id uuid.UUID, key string, contentType string, width, height int) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE task_attachments
+		SET thumbnail_status = 'ready',
+			thumbnail_key = $1,
+			thumbnail_content_type = $2,
+			thumbnail_width = $3,
+			thumbnail_height = $4
+		WHERE id = $5
+	`, key, contentType, width, height, id)
+	if err != nil {
+		return false, fmt.Errorf("error completing thumbnail: %v", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// FailThumbnail records a failed attempt at an attachment's thumbnail. It's
+// tried again at nextAttemptAt, or marked failed for good when that's nil.
+func (r *AttachmentRepository) FailThumbnail(ctx context.Context, id uuid.UUID, nextAttemptAt *time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE task_attachments
+		SET thumbnail_attempts = thumbnail_attempts + 1,
+			thumbnail_status = CASE WHEN $1::timestamptz IS NULL THEN 'failed' ELSE thumbnail_status END,
+			thumbnail_next_attempt_at = COALESCE($1, thumbnail_next_attempt_at)
+		WHERE id = $2
+	`, nextAttemptAt, id)
+	if err != nil {
+		return fmt.Errorf("error recording thumbnail failure: %v", err)
+	}
+	return nil
+}
+
+// attachmentsForTasks returns the attachments of each task, oldest first,
+// keyed by task ID
+func attachmentsForTasks(ctx context.Context, db *pgxpool.Pool, taskIDs []uuid.UUID) (map[uuid.UUID][]models.Attachment, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+attachmentColumns+`
+		FROM task_attachments
+		WHERE task_id = ANY($1)
+		ORDER BY created_at, id
+	`, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error listing attachments: %v", err)
+	}
+	defer rows.Close()
+
+	attachments := make(map[uuid.UUID][]models.Attachment)
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments[attachment.TaskID] = append(attachments[attachment.TaskID], *attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %v", err)
+	}
+
+	return attachments, nil
+}
+
+// scanAttachment scans a row of attachmentColumns
+func scanAttachment(row pgx.Row) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.TaskID,
+		&attachment.StorageKey,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.SHA256,
+		&attachment.UploadedBy,
+		&attachment.CreatedAt,
+		&attachment.ThumbnailStatus,
+		&attachment.ThumbnailKey,
+		&attachment.ThumbnailContentType,
+		&attachment.ThumbnailWidth,
+		&attachment.ThumbnailHeight,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error scanning attachment: %v", err)
+	}
+	return &attachment, nil
+}
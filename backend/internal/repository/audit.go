@@ -2,29 +2,174 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rafaelzasas/vtasker/backend/internal/models"
 )
 
-// CreateAuditLog creates a new audit log entry
-func (r *TaskRepository) CreateAuditLog(ctx context.Context, entry *models.AuditLog) error {
+// AuditRepository handles reading the admin audit trail
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// ListAuditLogs retrieves a page of the audit trail, newest first
+func (r *AuditRepository) ListAuditLogs(ctx context.Context, filter models.AuditFilter, cursor string, limit int) (*models.AuditPage, error) {
 	query := `
+		SELECT
+			a.id,
+			a.admin_id,
+			u.full_name,
+			a.action,
+			a.entity_type,
+			a.entity_id,
+			a.previous_state,
+			a.new_state,
+			a.ip_address,
+			a.user_agent,
+			a.created_at
+		FROM audit_logs a
+		LEFT JOIN users u ON u.id = a.admin_id
+		WHERE 1=1`
+	args := []interface{}{}
+	argNum := 1
+
+	if filter.AdminID != nil {
+		query += fmt.Sprintf(" AND a.admin_id = $%d", argNum)
+		args = append(args, *filter.AdminID)
+		argNum++
+	}
+	if filter.Action != "" {
+		query += fmt.Sprintf(" AND a.action = $%d", argNum)
+		args = append(args, filter.Action)
+		argNum++
+	}
+	if filter.EntityType != "" {
+		query += fmt.Sprintf(" AND a.entity_type = $%d", argNum)
+		args = append(args, filter.EntityType)
+		argNum++
+	}
+	if filter.EntityID != nil {
+		query += fmt.Sprintf(" AND a.entity_id = $%d", argNum)
+		args = append(args, *filter.EntityID)
+		argNum++
+	}
+	if filter.From != nil {
+		query += fmt.Sprintf(" AND a.created_at >= $%d", argNum)
+		args = append(args, *filter.From)
+		argNum++
+	}
+	if filter.To != nil {
+		query += fmt.Sprintf(" AND a.created_at < $%d", argNum)
+		args = append(args, *filter.To)
+		argNum++
+	}
+	keyset := newestFirst("a.created_at", "a.id")
+	if cursor != "" {
+		key, err := keyset.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, key)
+		query += " AND " + keyset.After(len(args))
+	}
+
+	// Fetch one extra row to know whether there's another page
+	query += keyset.OrderBy() + fmt.Sprintf(" LIMIT %d", limit+1)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit logs: %v", err)
+	}
+	defer rows.Close()
+
+	page := &models.AuditPage{Items: make([]models.AuditLog, 0, limit)}
+	for rows.Next() {
+		var entry models.AuditLog
+		err := rows.Scan(
+			&entry.ID,
+			&entry.AdminID,
+			&entry.AdminName,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.PreviousState,
+			&entry.NewState,
+			&entry.IPAddress,
+			&entry.UserAgent,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit log: %v", err)
+		}
+		page.Items = append(page.Items, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit logs: %v", err)
+	}
+
+	if len(page.Items) > limit {
+		last := page.Items[limit-1]
+		page.Items = page.Items[:limit]
+		page.NextCursor = keyset.CursorAt(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// RecordAudit writes a privileged action to the audit trail in the caller's
+// transaction. The admin, IP address and user agent come from the request
+// info on ctx. previous and next are stored as JSON; either can be nil.
+func RecordAudit(ctx context.Context, tx pgx.Tx, action string, entityType string, entityID uuid.UUID, previous any, next any) error {
+	previousJSON, err := auditState(previous)
+	if err != nil {
+		return err
+	}
+	nextJSON, err := auditState(next)
+	if err != nil {
+		return err
+	}
+
+	info := requestInfoFrom(ctx)
+	ipAddress, userAgent := info.clientColumns()
+
+	_, err = tx.Exec(ctx, `
 		INSERT INTO audit_logs (
+			admin_id,
 			action,
-			task_id,
-			details,
+			entity_type,
+			entity_id,
+			previous_state,
+			new_state,
+			ip_address,
+			user_agent,
 			created_at
-		) VALUES ($1, $2, $3, $4)`
-
-	_, err := r.db.Exec(ctx, query,
-		entry.Action,
-		entry.TaskID,
-		entry.Details,
-		entry.CreatedAt,
-	)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, clock_timestamp())
+	`, info.ActorID, action, entityType, entityID, previousJSON, nextJSON, ipAddress, userAgent)
 	if err != nil {
-		return err
+		return fmt.Errorf("error recording audit log: %v", err)
 	}
 
 	return nil
-} 
\ No newline at end of file
+}
+
+// auditState marshals a state snapshot, keeping nil as SQL NULL
+func auditState(state any) ([]byte, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling audit state: %v", err)
+	}
+	return data, nil
+}
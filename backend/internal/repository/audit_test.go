@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestRecordAudit(t *testing.T) {
+	adminID, userID := uuid.New(), uuid.New()
+	ctx := WithRequestInfo(context.Background(), RequestInfo{ActorID: &adminID, IPAddress: "203.0.113.7"})
+
+	tests := []struct {
+		name         string
+		previous     any
+		next         any
+		wantPrevious string
+		wantNext     string
+	}{
+		{"role change", map[string]any{"role_id": 1}, map[string]any{"role_id": 2}, `{"role_id":1}`, `{"role_id":2}`},
+		{"deletion has no new state", map[string]any{"email": "ana@example.com"}, nil, `{"email":"ana@example.com"}`, ""},
+		{"no states", nil, nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{}
+			if err := RecordAudit(ctx, tx, models.AuditUserRoleChanged, models.AuditEntityUser, userID, tt.previous, tt.next); err != nil {
+				t.Fatalf("RecordAudit() error = %v", err)
+			}
+			if len(tx.execs) != 1 {
+				t.Fatalf("RecordAudit() ran %d statements, want 1", len(tx.execs))
+			}
+
+			args := tx.execs[0]
+			if actor, _ := args[0].(*uuid.UUID); actor == nil || *actor != adminID {
+				t.Errorf("admin = %v, want %v", args[0], adminID)
+			}
+			if args[1] != models.AuditUserRoleChanged || args[2] != models.AuditEntityUser || args[3] != userID {
+				t.Errorf("action, entity = %v, %v, %v", args[1], args[2], args[3])
+			}
+			if got := string(args[4].([]byte)); got != tt.wantPrevious {
+				t.Errorf("previous state = %q, want %q", got, tt.wantPrevious)
+			}
+			if got := string(args[5].([]byte)); got != tt.wantNext {
+				t.Errorf("new state = %q, want %q", got, tt.wantNext)
+			}
+			if tt.next == nil && args[5].([]byte) != nil {
+				t.Error("missing new state isn't stored as NULL")
+			}
+			if ip, _ := args[6].(*string); ip == nil || *ip != "203.0.113.7" {
+				t.Errorf("IP address = %v, want 203.0.113.7", args[6])
+			}
+			if ua, _ := args[7].(*string); ua != nil {
+				t.Errorf("user agent = %q, want NULL", *ua)
+			}
+		})
+	}
+
+	t.Run("unmarshalable state", func(t *testing.T) {
+		tx := &fakeTx{}
+		if err := RecordAudit(ctx, tx, models.AuditUserDeleted, models.AuditEntityUser, userID, func() {}, nil); err == nil {
+			t.Error("RecordAudit() error = nil, want a marshaling error")
+		}
+		if len(tx.execs) != 0 {
+			t.Error("RecordAudit() wrote a row for a state it couldn't marshal")
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rafaelzasas/vtasker/backend/internal/models"
 )
@@ -144,35 +145,86 @@ func (r *BoardRepository) GetBoard(ctx context.Context, id string, userID uuid.U
 		board.Tasks = append(board.Tasks, task)
 	}
 
+	// Get column counts against their WIP limits
+	board.Columns, err = columnsForBoard(ctx, r.db, board.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &board, nil
 }
 
-// ListBoards retrieves all boards accessible by the user
-func (r *BoardRepository) ListBoards(ctx context.Context, userID uuid.UUID) ([]*models.Board, error) {
+// boardSortFields maps the fields boards can be sorted by to the SQL they
+// sort on, with boards aliased as b
+var boardSortFields = SortFields{
+	"name":       {{Expr: "LOWER(b.name)", Type: "text"}},
+	"created_at": {{Expr: "b.created_at", Type: "timestamptz"}},
+	"updated_at": {{Expr: "b.updated_at", Type: "timestamptz"}},
+}
+
+// BoardSortFields lists the fields boards can be sorted by
+var BoardSortFields = boardSortFields.Names()
+
+// ListBoards retrieves a page of the boards accessible by the user. sort is a
+// field from BoardSortFields, with a leading - to sort descending; boards are
+// newest first by default. A limit of 0 returns every board.
+func (r *BoardRepository) ListBoards(ctx context.Context, userID uuid.UUID, sort string, cursor string, limit int) (*models.BoardPage, error) {
+	return r.listBoards(ctx, `
+		WHERE (
+			b.is_public = true OR
+			b.owner_id = $1 OR
+			EXISTS (SELECT 1 FROM board_members bm WHERE bm.board_id = b.id AND bm.user_id = $1)
+		)`,
+		[]interface{}{userID}, sort, cursor, limit)
+}
+
+// listBoards pages through the boards matching a WHERE clause
+func (r *BoardRepository) listBoards(ctx context.Context, where string, args []interface{}, sort string, cursor string, limit int) (*models.BoardPage, error) {
+	keyset, err := boardSortFields.Keyset(sort, "-created_at", SortKey{Expr: "b.id", Type: "uuid"})
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT DISTINCT
-			b.id, 
+		SELECT
+			b.id,
 			b.name,
 			b.slug,
 			b.description,
 			b.owner_id,
 			b.is_public,
 			b.created_at,
-			b.updated_at
-		FROM boards b
-		LEFT JOIN board_members bm ON bm.board_id = b.id
-		WHERE b.is_public = true 
-		   OR b.owner_id = $1
-		   OR bm.user_id = $1
-		ORDER BY b.created_at DESC`
+			b.updated_at,
+			` + keyset.KeyColumn() + `
+		FROM boards b` + where
+
+	if cursor != "" {
+		key, err := keyset.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		condition := " AND "
+		if where == "" {
+			condition = " WHERE "
+		}
+		args = append(args, key)
+		query += condition + keyset.After(len(args))
+	}
+
+	query += keyset.OrderBy()
+	if limit > 0 {
+		// Fetch one extra row to know whether there's another page
+		query += fmt.Sprintf(" LIMIT %d", limit+1)
+	}
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error listing boards: %v", err)
 	}
 	defer rows.Close()
 
-	var boards []*models.Board
+	page := &models.BoardPage{Items: make([]*models.Board, 0)}
+	var sortKey, lastKey string
 	for rows.Next() {
 		var board models.Board
 		err := rows.Scan(
@@ -184,14 +236,25 @@ func (r *BoardRepository) ListBoards(ctx context.Context, userID uuid.UUID) ([]*
 			&board.IsPublic,
 			&board.CreatedAt,
 			&board.UpdatedAt,
+			&sortKey,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning board: %v", err)
 		}
-		boards = append(boards, &board)
+		if limit > 0 && len(page.Items) == limit {
+			// The extra row means there's another page after this one
+			page.NextCursor = keyset.Cursor(lastKey)
+			break
+		}
+		page.Items = append(page.Items, &board)
+		lastKey = sortKey
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating boards: %v", err)
 	}
 
-	return boards, nil
+	return page, nil
 }
 
 // GetBoardBySlug retrieves a board by its slug
@@ -318,6 +381,12 @@ func (r *BoardRepository) GetBoardBySlug(ctx context.Context, slug string, userI
 		board.Tasks = append(board.Tasks, task)
 	}
 
+	// Get column counts against their WIP limits
+	board.Columns, err = columnsForBoard(ctx, r.db, board.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &board, nil
 }
 
@@ -342,6 +411,13 @@ func (r *BoardRepository) CreateBoard(ctx context.Context, input *models.CreateB
 	board := models.NewBoard(*input, ownerID)
 	board.Slug = uniqueSlug
 
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		INSERT INTO boards (
 			id,
@@ -355,7 +431,7 @@ func (r *BoardRepository) CreateBoard(ctx context.Context, input *models.CreateB
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, name, slug, description, owner_id, is_public, created_at, updated_at`
 
-	err = r.db.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		board.ID,
@@ -383,6 +459,10 @@ func (r *BoardRepository) CreateBoard(ctx context.Context, input *models.CreateB
 		return nil, fmt.Errorf("error creating board: %v", err)
 	}
 
+	if err := recordBoardActivity(ctx, tx, board.ID, models.ActivityCreated, nil, map[string]any{"name": board.Name}); err != nil {
+		return nil, err
+	}
+
 	// Add members if provided
 	if len(input.Members) > 0 {
 		membersQuery := `
@@ -390,7 +470,7 @@ func (r *BoardRepository) CreateBoard(ctx context.Context, input *models.CreateB
 			VALUES ($1, $2, $3, $4)`
 
 		for _, member := range input.Members {
-			_, err = r.db.Exec(
+			_, err = tx.Exec(
 				ctx,
 				membersQuery,
 				board.ID,
@@ -401,9 +481,27 @@ func (r *BoardRepository) CreateBoard(ctx context.Context, input *models.CreateB
 			if err != nil {
 				return nil, fmt.Errorf("error adding board member: %v", err)
 			}
+
+			if err := recordBoardActivity(ctx, tx, board.ID, models.ActivityMemberAdded, nil, map[string]any{
+				"user_id": member.UserID,
+				"role":    member.Role,
+			}); err != nil {
+				return nil, err
+			}
+			if err := notifyUser(ctx, tx, member.UserID, models.NotificationBoardMemberAdded, &board.ID, nil, map[string]any{
+				"board_name": board.Name,
+				"role":       member.Role,
+			}); err != nil {
+				return nil, err
+			}
 		}
 	}
 
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
 	return board, nil
 }
 
@@ -453,6 +551,18 @@ func (r *BoardRepository) UpdateBoard(ctx context.Context, id string, input *mod
 		input.Slug = &uniqueSlug
 	}
 
+	// Keep the current values for the activity feed
+	var before models.Board
+	err = tx.QueryRow(ctx, `
+		SELECT name, slug, COALESCE(description, ''), is_public
+		FROM boards
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&before.Name, &before.Slug, &before.Description, &before.IsPublic)
+	if err != nil {
+		return nil, fmt.Errorf("error updating board: %v", err)
+	}
+
 	// Update board
 	query := `
 		UPDATE boards
@@ -466,9 +576,10 @@ func (r *BoardRepository) UpdateBoard(ctx context.Context, id string, input *mod
 			SELECT 1 FROM board_members
 			WHERE board_id = $5 AND user_id = $6 AND role = 'admin'
 		))
-		RETURNING id`
+		RETURNING id, name, slug, COALESCE(description, ''), is_public`
 
 	var boardID uuid.UUID
+	var after models.Board
 	err = tx.QueryRow(ctx, query,
 		input.Name,
 		input.Slug,
@@ -476,14 +587,28 @@ func (r *BoardRepository) UpdateBoard(ctx context.Context, id string, input *mod
 		input.IsPublic,
 		id,
 		userID,
-	).Scan(&boardID)
+	).Scan(&boardID, &after.Name, &after.Slug, &after.Description, &after.IsPublic)
 
 	if err != nil {
 		return nil, fmt.Errorf("error updating board: %v", err)
 	}
 
+	var changes fieldChanges
+	changes.add("name", before.Name, after.Name)
+	changes.add("slug", before.Slug, after.Slug)
+	changes.add("description", before.Description, after.Description)
+	changes.add("is_public", before.IsPublic, after.IsPublic)
+	if err := recordBoardActivity(ctx, tx, boardID, models.ActivityUpdated, changes, nil); err != nil {
+		return nil, err
+	}
+
 	// Update members if provided
 	if len(input.Members) > 0 {
+		previousRoles, err := boardMemberRoles(ctx, tx, boardID)
+		if err != nil {
+			return nil, err
+		}
+
 		// Remove existing members
 		_, err = tx.Exec(ctx, `DELETE FROM board_members WHERE board_id = $1`, boardID)
 		if err != nil {
@@ -505,6 +630,17 @@ func (r *BoardRepository) UpdateBoard(ctx context.Context, id string, input *mod
 				return nil, fmt.Errorf("error adding board member: %v", err)
 			}
 		}
+
+		if err := recordMemberChanges(ctx, tx, boardID, after.Name, previousRoles, input.Members); err != nil {
+			return nil, err
+		}
+	}
+
+	// Drop mentions of anyone who can no longer see the board
+	if len(input.Members) > 0 || (before.IsPublic && !after.IsPublic) {
+		if err := pruneBoardMentions(ctx, tx, boardID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Commit transaction
@@ -515,16 +651,88 @@ func (r *BoardRepository) UpdateBoard(ctx context.Context, id string, input *mod
 	return r.GetBoard(ctx, id, userID)
 }
 
+// boardMemberRoles returns the role of each member of a board
+func boardMemberRoles(ctx context.Context, tx pgx.Tx, boardID uuid.UUID) (map[uuid.UUID]models.BoardRole, error) {
+	rows, err := tx.Query(ctx, `SELECT user_id, role FROM board_members WHERE board_id = $1`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying board members: %v", err)
+	}
+	defer rows.Close()
+
+	roles := make(map[uuid.UUID]models.BoardRole)
+	for rows.Next() {
+		var userID uuid.UUID
+		var role models.BoardRole
+		if err := rows.Scan(&userID, &role); err != nil {
+			return nil, fmt.Errorf("error scanning board member: %v", err)
+		}
+		roles[userID] = role
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating board members: %v", err)
+	}
+
+	return roles, nil
+}
+
+// recordMemberChanges records who was added to, removed from or changed role
+// on a board when its member list is replaced, and notifies new members
+func recordMemberChanges(ctx context.Context, tx pgx.Tx, boardID uuid.UUID, boardName string, previous map[uuid.UUID]models.BoardRole, members []models.BoardMemberInput) error {
+	current := make(map[uuid.UUID]bool, len(members))
+	for _, member := range members {
+		current[member.UserID] = true
+
+		previousRole, existed := previous[member.UserID]
+		switch {
+		case !existed:
+			if err := recordBoardActivity(ctx, tx, boardID, models.ActivityMemberAdded, nil, map[string]any{
+				"user_id": member.UserID,
+				"role":    member.Role,
+			}); err != nil {
+				return err
+			}
+			if err := notifyUser(ctx, tx, member.UserID, models.NotificationBoardMemberAdded, &boardID, nil, map[string]any{
+				"board_name": boardName,
+				"role":       member.Role,
+			}); err != nil {
+				return err
+			}
+		case previousRole != member.Role:
+			var changes fieldChanges
+			changes.add("role", previousRole, member.Role)
+			if err := recordBoardActivity(ctx, tx, boardID, models.ActivityMemberUpdated, changes, map[string]any{"user_id": member.UserID}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for userID, role := range previous {
+		if current[userID] {
+			continue
+		}
+		if err := recordBoardActivity(ctx, tx, boardID, models.ActivityMemberRemoved, nil, map[string]any{
+			"user_id": userID,
+			"role":    role,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // DeleteBoard deletes a board
 func (r *BoardRepository) DeleteBoard(ctx context.Context, id string, userID uuid.UUID, isSuperAdmin bool) error {
 	// First check if the board exists and the user has permission to delete it
-	var ownerID uuid.UUID
+	var boardID, ownerID uuid.UUID
+	var name, slug string
 	var isPublic bool
 	err := r.db.QueryRow(ctx, `
-		SELECT owner_id, is_public
+		SELECT id, name, slug, owner_id, is_public
 		FROM boards
 		WHERE id = $1
-	`, id).Scan(&ownerID, &isPublic)
+	`, id).Scan(&boardID, &name, &slug, &ownerID, &isPublic)
 
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -533,8 +741,10 @@ func (r *BoardRepository) DeleteBoard(ctx context.Context, id string, userID uui
 		return fmt.Errorf("error checking board: %v", err)
 	}
 
-	// Check if user has permission to delete
-	if !isSuperAdmin && ownerID != userID {
+	// Check if user has permission to delete. Super admins can delete any
+	// board, but doing so on someone else's board is audited.
+	asSuperAdmin := false
+	if ownerID != userID {
 		// Check if user is a board admin
 		var isAdmin bool
 		err := r.db.QueryRow(ctx, `
@@ -549,12 +759,39 @@ func (r *BoardRepository) DeleteBoard(ctx context.Context, id string, userID uui
 		}
 
 		if !isAdmin {
-			return fmt.Errorf("unauthorized")
+			if !isSuperAdmin {
+				return fmt.Errorf("unauthorized")
+			}
+			asSuperAdmin = true
+		}
+	}
+
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Record the deletion first; the event outlives the board, which
+	// clears its board_id but leaves entity_id pointing at the board
+	if err := recordBoardActivity(ctx, tx, boardID, models.ActivityDeleted, nil, map[string]any{"name": name}); err != nil {
+		return err
+	}
+	if asSuperAdmin {
+		err := RecordAudit(ctx, tx, models.AuditBoardDeleted, models.AuditEntityBoard, boardID, map[string]any{
+			"name":      name,
+			"slug":      slug,
+			"owner_id":  ownerID,
+			"is_public": isPublic,
+		}, nil)
+		if err != nil {
+			return err
 		}
 	}
 
 	// Delete the board
-	result, err := r.db.Exec(ctx, "DELETE FROM boards WHERE id = $1", id)
+	result, err := tx.Exec(ctx, "DELETE FROM boards WHERE id = $1", boardID)
 	if err != nil {
 		return fmt.Errorf("error deleting board: %v", err)
 	}
@@ -564,48 +801,16 @@ func (r *BoardRepository) DeleteBoard(ctx context.Context, id string, userID uui
 		return fmt.Errorf("board not found")
 	}
 
-	return nil
-}
-
-// ListAllBoards retrieves all boards in the system (super admin only)
-func (r *BoardRepository) ListAllBoards(ctx context.Context) ([]*models.Board, error) {
-	query := `
-		SELECT DISTINCT
-			b.id, 
-			b.name,
-			b.slug,
-			b.description,
-			b.owner_id,
-			b.is_public,
-			b.created_at,
-			b.updated_at
-		FROM boards b
-		ORDER BY b.created_at DESC`
-
-	rows, err := r.db.Query(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("error listing boards: %v", err)
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
 	}
-	defer rows.Close()
 
-	var boards []*models.Board
-	for rows.Next() {
-		var board models.Board
-		err := rows.Scan(
-			&board.ID,
-			&board.Name,
-			&board.Slug,
-			&board.Description,
-			&board.OwnerID,
-			&board.IsPublic,
-			&board.CreatedAt,
-			&board.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning board: %v", err)
-		}
-		boards = append(boards, &board)
-	}
+	return nil
+}
 
-	return boards, nil
+// ListAllBoards retrieves a page of all boards in the system (super admin
+// only), sorted like ListBoards
+func (r *BoardRepository) ListAllBoards(ctx context.Context, sort string, cursor string, limit int) (*models.BoardPage, error) {
+	return r.listBoards(ctx, "", nil, sort, cursor, limit)
 } 
\ No newline at end of file
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// ErrNestedReply is returned when replying to a comment that is itself a reply
+var ErrNestedReply = errors.New("replies can only be one level deep")
+
+// CommentRepository handles database operations for task comments
+type CommentRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewCommentRepository creates a new comment repository
+func NewCommentRepository(db *pgxpool.Pool) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// ListComments retrieves all comments for a task with replies nested under
+// their parent comment, both ordered oldest first
+func (r *CommentRepository) ListComments(ctx context.Context, taskID uuid.UUID) ([]*models.Comment, error) {
+	query := `
+		SELECT
+			c.id,
+			c.task_id,
+			c.parent_id,
+			c.author_id,
+			c.body,
+			c.edited_at,
+			c.created_at,
+			c.updated_at,
+			u.id,
+			u.full_name,
+			u.email,
+			COALESCE(u.avatar_url, '')
+		FROM task_comments c
+		JOIN users u ON u.id = c.author_id
+		WHERE c.task_id = $1
+		ORDER BY c.created_at, c.id`
+
+	rows, err := r.db.Query(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing comments: %v", err)
+	}
+	defer rows.Close()
+
+	var all []*models.Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment rows: %v", err)
+	}
+
+	// Attach mentions, then replies to their parents
+	if err := attachCommentMentions(ctx, r.db, all); err != nil {
+		return nil, err
+	}
+
+	comments := make([]*models.Comment, 0)
+	byID := make(map[uuid.UUID]*models.Comment)
+	for _, comment := range all {
+		if comment.ParentID == nil {
+			byID[comment.ID] = comment
+			comments = append(comments, comment)
+		}
+	}
+	for _, comment := range all {
+		if comment.ParentID != nil {
+			if parent, ok := byID[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, *comment)
+			}
+		}
+	}
+
+	return comments, nil
+}
+
+// GetComment retrieves a single comment by ID without its replies
+func (r *CommentRepository) GetComment(ctx context.Context, id uuid.UUID) (*models.Comment, error) {
+	query := `
+		SELECT
+			c.id,
+			c.task_id,
+			c.parent_id,
+			c.author_id,
+			c.body,
+			c.edited_at,
+			c.created_at,
+			c.updated_at,
+			u.id,
+			u.full_name,
+			u.email,
+			COALESCE(u.avatar_url, '')
+		FROM task_comments c
+		JOIN users u ON u.id = c.author_id
+		WHERE c.id = $1`
+
+	comment, err := scanComment(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := attachCommentMentions(ctx, r.db, []*models.Comment{comment}); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// CreateComment creates a new comment on a task
+func (r *CommentRepository) CreateComment(ctx context.Context, taskID uuid.UUID, input *models.CreateCommentInput, authorID uuid.UUID) (*models.Comment, error) {
+	comment := models.NewComment(*input, taskID, authorID)
+
+	// Replies must target a top-level comment on the same task
+	if comment.ParentID != nil {
+		var parentTaskID uuid.UUID
+		var grandparentID *uuid.UUID
+		err := r.db.QueryRow(ctx, `
+			SELECT task_id, parent_id
+			FROM task_comments
+			WHERE id = $1
+		`, comment.ParentID).Scan(&parentTaskID, &grandparentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrNotFound
+			}
+			return nil, fmt.Errorf("error checking parent comment: %v", err)
+		}
+		if parentTaskID != taskID {
+			return nil, ErrNotFound
+		}
+		if grandparentID != nil {
+			return nil, ErrNestedReply
+		}
+	}
+
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO task_comments (
+			id,
+			task_id,
+			parent_id,
+			author_id,
+			body,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = tx.Exec(ctx, query,
+		comment.ID,
+		comment.TaskID,
+		comment.ParentID,
+		comment.AuthorID,
+		comment.Body,
+		comment.CreatedAt,
+		comment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating comment: %v", err)
+	}
+
+	if err := saveCommentMentions(ctx, tx, comment.TaskID, comment.ID, comment.Body); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return r.GetComment(ctx, comment.ID)
+}
+
+// UpdateComment replaces the body of a comment, keeping the previous body in
+// the comment's edit history
+func (r *CommentRepository) UpdateComment(ctx context.Context, id uuid.UUID, input *models.UpdateCommentInput, userID uuid.UUID) (*models.Comment, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var taskID uuid.UUID
+	var previousBody string
+	err = tx.QueryRow(ctx, `
+		SELECT task_id, body FROM task_comments WHERE id = $1 FOR UPDATE
+	`, id).Scan(&taskID, &previousBody)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error loading comment: %v", err)
+	}
+
+	// Nothing to record if the body did not change
+	if previousBody != input.Body {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO task_comment_edits (comment_id, previous_body, edited_by)
+			VALUES ($1, $2, $3)
+		`, id, previousBody, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error recording comment edit: %v", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE task_comments
+			SET body = $1, edited_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+		`, input.Body, id)
+		if err != nil {
+			return nil, fmt.Errorf("error updating comment: %v", err)
+		}
+
+		if err := saveCommentMentions(ctx, tx, taskID, id, input.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return r.GetComment(ctx, id)
+}
+
+// DeleteComment deletes a comment and, through the foreign key, its replies
+func (r *CommentRepository) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM task_comments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting comment: %v", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListCommentEdits returns the edit history of a comment, newest first
+func (r *CommentRepository) ListCommentEdits(ctx context.Context, commentID uuid.UUID) ([]models.CommentEdit, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, comment_id, previous_body, edited_by, created_at
+		FROM task_comment_edits
+		WHERE comment_id = $1
+		ORDER BY created_at DESC
+	`, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing comment edits: %v", err)
+	}
+	defer rows.Close()
+
+	edits := make([]models.CommentEdit, 0)
+	for rows.Next() {
+		var edit models.CommentEdit
+		err := rows.Scan(
+			&edit.ID,
+			&edit.CommentID,
+			&edit.PreviousBody,
+			&edit.EditedBy,
+			&edit.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning comment edit: %v", err)
+		}
+		edits = append(edits, edit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment edits: %v", err)
+	}
+
+	return edits, nil
+}
+
+// saveCommentMentions resolves the @mentions in a comment body against the
+// comment's task
+func saveCommentMentions(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, commentID uuid.UUID, body string) error {
+	task := models.Task{ID: taskID}
+	err := tx.QueryRow(ctx, `SELECT title, board_id FROM tasks WHERE id = $1`, taskID).Scan(&task.Title, &task.BoardID)
+	if err != nil {
+		return fmt.Errorf("error getting comment task: %v", err)
+	}
+
+	return saveMentions(ctx, tx, &task, &commentID, models.MentionFieldComment, body)
+}
+
+// attachCommentMentions loads the mentions of each comment
+func attachCommentMentions(ctx context.Context, db *pgxpool.Pool, comments []*models.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	commentIDs := make([]uuid.UUID, len(comments))
+	for i, comment := range comments {
+		commentIDs[i] = comment.ID
+	}
+
+	mentions, err := mentionsForComments(ctx, db, commentIDs)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		comment.Mentions = mentions[comment.ID]
+		if comment.Mentions == nil {
+			comment.Mentions = make([]models.Mention, 0)
+		}
+	}
+
+	return nil
+}
+
+// scanComment scans a comment joined with its author
+func scanComment(row pgx.Row) (*models.Comment, error) {
+	var comment models.Comment
+	var author models.User
+	err := row.Scan(
+		&comment.ID,
+		&comment.TaskID,
+		&comment.ParentID,
+		&comment.AuthorID,
+		&comment.Body,
+		&comment.EditedAt,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+		&author.ID,
+		&author.FullName,
+		&author.Email,
+		&author.AvatarURL,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error scanning comment: %v", err)
+	}
+	comment.Author = &author
+	return &comment, nil
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// ErrDoneGateOverrideForbidden is returned when a user who isn't a board admin
+// tries to override the definition of done
+var ErrDoneGateOverrideForbidden = errors.New("only board admins can override the definition of done")
+
+// DoneGateError is returned when a task moving into a done status doesn't
+// meet the definition of done
+type DoneGateError struct {
+	Violation *models.DoneGateViolation
+}
+
+func (e *DoneGateError) Error() string {
+	return "task doesn't meet the definition of done"
+}
+
+// queryRower is implemented by both the pool and transactions
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// DoneGateRepository handles database operations for definition-of-done gates
+type DoneGateRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewDoneGateRepository creates a new done gate repository
+func NewDoneGateRepository(db *pgxpool.Pool) *DoneGateRepository {
+	return &DoneGateRepository{db: db}
+}
+
+// GetGate returns the gate that applies to a board: its own gate if it has
+// one, otherwise the global gate. A nil boardID returns the global gate.
+func (r *DoneGateRepository) GetGate(ctx context.Context, boardID *uuid.UUID) (*models.DoneGate, error) {
+	return effectiveDoneGate(ctx, r.db, boardID)
+}
+
+// SetGate creates or replaces the gate for a board, or the global gate when boardID is nil
+func (r *DoneGateRepository) SetGate(ctx context.Context, boardID *uuid.UUID, input *models.DoneGateInput) (*models.DoneGate, error) {
+	// Board gates and the global gate have separate unique indexes
+	conflict := `(board_id) WHERE board_id IS NOT NULL`
+	if boardID == nil {
+		conflict = `((board_id IS NULL)) WHERE board_id IS NULL`
+	}
+
+	return scanDoneGate(r.db.QueryRow(ctx, `
+		INSERT INTO done_gates (board_id, enabled, require_criteria, require_dependencies)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT `+conflict+` DO UPDATE
+		SET
+			enabled = EXCLUDED.enabled,
+			require_criteria = EXCLUDED.require_criteria,
+			require_dependencies = EXCLUDED.require_dependencies
+		RETURNING id, board_id, enabled, require_criteria, require_dependencies, created_at, updated_at
+	`, boardID, input.Enabled, input.RequireCriteria, input.RequireDependencies))
+}
+
+// DeleteBoardGate removes a board's own gate so the global gate applies again
+func (r *DoneGateRepository) DeleteBoardGate(ctx context.Context, boardID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM done_gates WHERE board_id = $1`, boardID)
+	if err != nil {
+		return fmt.Errorf("error deleting done gate: %v", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListOverrides retrieves the recorded gate overrides on a board, newest first
+func (r *DoneGateRepository) ListOverrides(ctx context.Context, boardID uuid.UUID) ([]models.DoneGateOverride, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			id,
+			task_id,
+			board_id,
+			user_id,
+			from_status_id,
+			to_status_id,
+			reason,
+			unmet_criteria,
+			blocking_tasks,
+			created_at
+		FROM done_gate_overrides
+		WHERE board_id = $1
+		ORDER BY created_at DESC
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing done gate overrides: %v", err)
+	}
+	defer rows.Close()
+
+	overrides := make([]models.DoneGateOverride, 0)
+	for rows.Next() {
+		var override models.DoneGateOverride
+		var unmetCriteria, blockingTasks []byte
+		err := rows.Scan(
+			&override.ID,
+			&override.TaskID,
+			&override.BoardID,
+			&override.UserID,
+			&override.FromStatusID,
+			&override.ToStatusID,
+			&override.Reason,
+			&unmetCriteria,
+			&blockingTasks,
+			&override.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning done gate override: %v", err)
+		}
+		if err := json.Unmarshal(unmetCriteria, &override.UnmetCriteria); err != nil {
+			return nil, fmt.Errorf("error unmarshaling unmet criteria: %v", err)
+		}
+		if err := json.Unmarshal(blockingTasks, &override.BlockingTasks); err != nil {
+			return nil, fmt.Errorf("error unmarshaling blocking tasks: %v", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating done gate overrides: %v", err)
+	}
+
+	return overrides, nil
+}
+
+// enforceDoneGate checks the definition of done for a task moving into its
+// StatusID. It runs before the task is saved, and the status the task moves
+// from is read from its row, locked for the rest of tx, so the gate and any
+// recorded override always start from the status the task really has. A move
+// that doesn't meet the gate fails with a DoneGateError unless the input
+// overrides it and the user is allowed to, in which case the override is
+// recorded.
+func enforceDoneGate(ctx context.Context, tx pgx.Tx, task *models.Task, input *models.UpdateTaskInput, userID uuid.UUID, canOverride bool) error {
+	fromStatusID, _, err := lockTask(ctx, tx, task.ID.String())
+	if err != nil {
+		return err
+	}
+	if fromStatusID == task.StatusID {
+		return nil
+	}
+
+	fromCategory, err := statusCategory(ctx, tx, fromStatusID)
+	if err != nil {
+		return err
+	}
+	toCategory, err := statusCategory(ctx, tx, task.StatusID)
+	if err != nil {
+		return err
+	}
+	if toCategory != models.StatusCategoryDone || fromCategory == models.StatusCategoryDone {
+		return nil
+	}
+
+	gate, err := effectiveDoneGate(ctx, tx, task.BoardID)
+	if err != nil {
+		return err
+	}
+	if !gate.Enabled {
+		return nil
+	}
+
+	violation, err := checkDoneGate(ctx, tx, task.ID, gate)
+	if err != nil {
+		return err
+	}
+	if violation.IsEmpty() {
+		return nil
+	}
+
+	if !input.OverrideDoneGate {
+		return &DoneGateError{Violation: violation}
+	}
+	if !canOverride {
+		return ErrDoneGateOverrideForbidden
+	}
+
+	unmetCriteria, err := json.Marshal(violation.UnmetCriteria)
+	if err != nil {
+		return fmt.Errorf("error marshaling unmet criteria: %v", err)
+	}
+	blockingTasks, err := json.Marshal(violation.BlockingTasks)
+	if err != nil {
+		return fmt.Errorf("error marshaling blocking tasks: %v", err)
+	}
+
+	var reason *string
+	if input.OverrideReason != "" {
+		reason = &input.OverrideReason
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO done_gate_overrides (
+			task_id,
+			board_id,
+			user_id,
+			from_status_id,
+			to_status_id,
+			reason,
+			unmet_criteria,
+			blocking_tasks
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, task.ID, task.BoardID, userID, fromStatusID, task.StatusID, reason, unmetCriteria, blockingTasks)
+	if err != nil {
+		return fmt.Errorf("error recording done gate override: %v", err)
+	}
+
+	return nil
+}
+
+// checkDoneGate lists the open criteria and unfinished dependencies that keep
+// a task from meeting the gate
+func checkDoneGate(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, gate *models.DoneGate) (*models.DoneGateViolation, error) {
+	violation := &models.DoneGateViolation{
+		UnmetCriteria: make([]models.AcceptanceCriterion, 0),
+		BlockingTasks: make([]models.BlockingTask, 0),
+	}
+
+	if gate.RequireCriteria {
+		rows, err := tx.Query(ctx, `
+			SELECT `+acceptanceCriterionColumns+`
+			FROM acceptance_criteria
+			WHERE task_id = $1 AND NOT completed
+			ORDER BY order_index
+		`, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("error querying unmet criteria: %v", err)
+		}
+		for rows.Next() {
+			ac, err := scanAcceptanceCriterion(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			violation.UnmetCriteria = append(violation.UnmetCriteria, *ac)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating unmet criteria: %v", err)
+		}
+	}
+
+	if gate.RequireDependencies {
+		rows, err := tx.Query(ctx, `
+			SELECT t.id, t.title, t.status_id
+			FROM task_dependencies td
+			JOIN tasks t ON t.id = td.dependency_id
+			LEFT JOIN task_statuses ts ON ts.id = t.status_id
+			WHERE td.task_id = $1 AND COALESCE(ts.category, 'todo') <> 'done'
+			ORDER BY t.title
+		`, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("error querying blocking tasks: %v", err)
+		}
+		for rows.Next() {
+			var blocking models.BlockingTask
+			if err := rows.Scan(&blocking.ID, &blocking.Title, &blocking.StatusID); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning blocking task: %v", err)
+			}
+			violation.BlockingTasks = append(violation.BlockingTasks, blocking)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating blocking tasks: %v", err)
+		}
+	}
+
+	return violation, nil
+}
+
+// effectiveDoneGate loads the board's gate, falling back to the global gate
+// and then to the built-in default
+func effectiveDoneGate(ctx context.Context, q queryRower, boardID *uuid.UUID) (*models.DoneGate, error) {
+	gate, err := scanDoneGate(q.QueryRow(ctx, `
+		SELECT id, board_id, enabled, require_criteria, require_dependencies, created_at, updated_at
+		FROM done_gates
+		WHERE board_id = $1 OR board_id IS NULL
+		ORDER BY board_id NULLS LAST
+		LIMIT 1
+	`, boardID))
+	if errors.Is(err, ErrNotFound) {
+		return models.DefaultDoneGate(), nil
+	}
+	return gate, err
+}
+
+// statusCategory returns the category of a status
+func statusCategory(ctx context.Context, q queryRower, statusID int32) (models.StatusCategory, error) {
+	var category models.StatusCategory
+	err := q.QueryRow(ctx, `SELECT category FROM task_statuses WHERE id = $1`, statusID).Scan(&category)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.StatusCategoryTodo, nil
+		}
+		return "", fmt.Errorf("error getting status category: %v", err)
+	}
+	return category, nil
+}
+
+// scanDoneGate scans a done gate row, mapping a missing row to ErrNotFound
+func scanDoneGate(row pgx.Row) (*models.DoneGate, error) {
+	var gate models.DoneGate
+	err := row.Scan(
+		&gate.ID,
+		&gate.BoardID,
+		&gate.Enabled,
+		&gate.RequireCriteria,
+		&gate.RequireDependencies,
+		&gate.CreatedAt,
+		&gate.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error scanning done gate: %v", err)
+	}
+	return &gate, nil
+}
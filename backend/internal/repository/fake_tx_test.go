@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeTx stands in for a transaction in code that only reads through
+// QueryRow and writes through Exec. Any other method panics.
+type fakeTx struct {
+	pgx.Tx
+	queryRow func(sql string, args ...any) pgx.Row
+	// execs records the arguments of each Exec
+	execs [][]any
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.queryRow(sql, args...)
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.execs = append(tx.execs, args)
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+// fakeRow scans fixed values in column order, or fails with err
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(dest) != len(r.values) {
+		return fmt.Errorf("scanning %d columns into %d values", len(r.values), len(dest))
+	}
+	for i, value := range r.values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+// rowsInOrder answers each query with the next row, failing once they run out
+func rowsInOrder(rows ...fakeRow) func(sql string, args ...any) pgx.Row {
+	return func(sql string, args ...any) pgx.Row {
+		if len(rows) == 0 {
+			return fakeRow{err: fmt.Errorf("unexpected query: %s", sql)}
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row
+	}
+}
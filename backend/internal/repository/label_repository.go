@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// ErrLabelNameExists is returned when a board already has a label with the given name
+var ErrLabelNameExists = errors.New("label name already exists")
+
+// ErrLabelBoardMismatch is returned when attaching a label from another board to a task
+var ErrLabelBoardMismatch = errors.New("label belongs to a different board")
+
+// LabelRepository handles database operations for board labels
+type LabelRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewLabelRepository creates a new label repository
+func NewLabelRepository(db *pgxpool.Pool) *LabelRepository {
+	return &LabelRepository{db: db}
+}
+
+// ListBoardLabels retrieves all labels defined on a board
+func (r *LabelRepository) ListBoardLabels(ctx context.Context, boardID uuid.UUID) ([]models.Label, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, board_id, name, color, created_at, updated_at
+		FROM board_labels
+		WHERE board_id = $1
+		ORDER BY name
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing labels: %v", err)
+	}
+	defer rows.Close()
+
+	labels := make([]models.Label, 0)
+	for rows.Next() {
+		var label models.Label
+		err := rows.Scan(
+			&label.ID,
+			&label.BoardID,
+			&label.Name,
+			&label.Color,
+			&label.CreatedAt,
+			&label.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning label: %v", err)
+		}
+		labels = append(labels, label)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating labels: %v", err)
+	}
+
+	return labels, nil
+}
+
+// GetLabel retrieves a label by ID
+func (r *LabelRepository) GetLabel(ctx context.Context, id uuid.UUID) (*models.Label, error) {
+	var label models.Label
+	err := r.db.QueryRow(ctx, `
+		SELECT id, board_id, name, color, created_at, updated_at
+		FROM board_labels
+		WHERE id = $1
+	`, id).Scan(
+		&label.ID,
+		&label.BoardID,
+		&label.Name,
+		&label.Color,
+		&label.CreatedAt,
+		&label.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error getting label: %v", err)
+	}
+
+	return &label, nil
+}
+
+// CreateLabel creates a new label on a board
+func (r *LabelRepository) CreateLabel(ctx context.Context, boardID uuid.UUID, input *models.CreateLabelInput) (*models.Label, error) {
+	label := models.NewLabel(*input, boardID)
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO board_labels (id, board_id, name, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, label.ID, label.BoardID, label.Name, label.Color, label.CreatedAt, label.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "SQLSTATE 23505") {
+			return nil, ErrLabelNameExists
+		}
+		return nil, fmt.Errorf("error creating label: %v", err)
+	}
+
+	return label, nil
+}
+
+// UpdateLabel renames or recolors a label
+func (r *LabelRepository) UpdateLabel(ctx context.Context, id uuid.UUID, input *models.UpdateLabelInput) (*models.Label, error) {
+	var label models.Label
+	err := r.db.QueryRow(ctx, `
+		UPDATE board_labels
+		SET
+			name = COALESCE($1, name),
+			color = COALESCE($2, color)
+		WHERE id = $3
+		RETURNING id, board_id, name, color, created_at, updated_at
+	`, input.Name, input.Color, id).Scan(
+		&label.ID,
+		&label.BoardID,
+		&label.Name,
+		&label.Color,
+		&label.CreatedAt,
+		&label.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if strings.Contains(err.Error(), "SQLSTATE 23505") {
+			return nil, ErrLabelNameExists
+		}
+		return nil, fmt.Errorf("error updating label: %v", err)
+	}
+
+	return &label, nil
+}
+
+// DeleteLabel deletes a label and removes it from every task it was attached to
+func (r *LabelRepository) DeleteLabel(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM board_labels WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting label: %v", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AddTaskLabel attaches a label to a task. Attaching a label twice is a no-op.
+func (r *LabelRepository) AddTaskLabel(ctx context.Context, task *models.Task, labelID uuid.UUID) error {
+	label, err := r.GetLabel(ctx, labelID)
+	if err != nil {
+		return err
+	}
+
+	if task.BoardID == nil || *task.BoardID != label.BoardID {
+		return ErrLabelBoardMismatch
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO task_labels (task_id, label_id)
+		VALUES ($1, $2)
+		ON CONFLICT (task_id, label_id) DO NOTHING
+	`, task.ID, labelID)
+	if err != nil {
+		return fmt.Errorf("error adding task label: %v", err)
+	}
+
+	return nil
+}
+
+// RemoveTaskLabel detaches a label from a task
+func (r *LabelRepository) RemoveTaskLabel(ctx context.Context, taskID uuid.UUID, labelID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		DELETE FROM task_labels
+		WHERE task_id = $1 AND label_id = $2
+	`, taskID, labelID)
+	if err != nil {
+		return fmt.Errorf("error removing task label: %v", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// labelsForTasks loads the labels attached to each of the given tasks
+func labelsForTasks(ctx context.Context, db *pgxpool.Pool, taskIDs []uuid.UUID) (map[uuid.UUID][]models.Label, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			tl.task_id,
+			bl.id,
+			bl.board_id,
+			bl.name,
+			bl.color,
+			bl.created_at,
+			bl.updated_at
+		FROM task_labels tl
+		JOIN board_labels bl ON bl.id = tl.label_id
+		WHERE tl.task_id = ANY($1)
+		ORDER BY bl.name
+	`, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying task labels: %v", err)
+	}
+	defer rows.Close()
+
+	labels := make(map[uuid.UUID][]models.Label)
+	for rows.Next() {
+		var taskID uuid.UUID
+		var label models.Label
+		err := rows.Scan(
+			&taskID,
+			&label.ID,
+			&label.BoardID,
+			&label.Name,
+			&label.Color,
+			&label.CreatedAt,
+			&label.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning task label: %v", err)
+		}
+		labels[taskID] = append(labels[taskID], label)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task labels: %v", err)
+	}
+
+	return labels, nil
+}
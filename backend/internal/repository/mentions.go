@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// maxMentionLength caps how long an @token can be, matching the longest
+// possible email address
+const maxMentionLength = 320
+
+// mentionExcerptRadius is how much text either side of a mention is quoted in
+// its notification
+const mentionExcerptRadius = 80
+
+// mentionToken is an @email or @handle found in text, before it's resolved.
+// Key is the lowercased email or handle without the @.
+type mentionToken struct {
+	start int
+	end   int
+	key   string
+	email bool
+}
+
+// mentionedUser is a user a mention token resolved to
+type mentionedUser struct {
+	id       uuid.UUID
+	handle   string
+	fullName string
+}
+
+// parseMentions finds the @email and @handle tokens in text. An @ only starts
+// a mention at the start of the text or after a character that can't be part
+// of one, so the @ inside a plain email address isn't read as a mention.
+// Trailing dots and dashes are left out, as they usually end the sentence.
+func parseMentions(text string) []mentionToken {
+	runes := []rune(text)
+	var tokens []mentionToken
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isMentionRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && (isMentionRune(runes[end]) || runes[end] == '@') {
+			end++
+		}
+		next := end
+		for end > i+1 && (runes[end-1] == '.' || runes[end-1] == '-') {
+			end--
+		}
+
+		key := strings.ToLower(string(runes[i+1 : end]))
+		if key != "" && len(key) <= maxMentionLength {
+			switch strings.Count(key, "@") {
+			case 0:
+				tokens = append(tokens, mentionToken{start: i, end: end, key: key})
+			case 1:
+				local, domain, _ := strings.Cut(key, "@")
+				if local != "" && strings.Contains(domain, ".") {
+					tokens = append(tokens, mentionToken{start: i, end: end, key: key, email: true})
+				}
+			}
+		}
+
+		i = next - 1
+	}
+
+	return tokens
+}
+
+// isMentionRune reports whether r can appear in a handle or email address
+func isMentionRune(r rune) bool {
+	return r >= 'a' && r <= 'z' ||
+		r >= 'A' && r <= 'Z' ||
+		r >= '0' && r <= '9' ||
+		r == '.' || r == '_' || r == '-' || r == '+'
+}
+
+// resolveMentions looks up the users that mention tokens refer to. Only users
+// who can see the board are resolved, so a mention never reveals whether
+// someone is a member of a board the author can't see into. Tasks without a
+// board are visible to everyone.
+func resolveMentions(ctx context.Context, tx pgx.Tx, boardID *uuid.UUID, tokens []mentionToken) (map[string]mentionedUser, error) {
+	resolved := make(map[string]mentionedUser)
+	if len(tokens) == 0 {
+		return resolved, nil
+	}
+
+	var emails, handles []string
+	for _, token := range tokens {
+		if token.email {
+			emails = append(emails, token.key)
+		} else {
+			handles = append(handles, token.key)
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT u.id, u.handle, u.full_name, LOWER(u.email)
+		FROM users u
+		WHERE (LOWER(u.email) = ANY($1) OR u.handle = ANY($2))
+		AND `+boardAccessCondition("$3::uuid", "u.id"), emails, handles, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving mentions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user mentionedUser
+		var email string
+		if err := rows.Scan(&user.id, &user.handle, &user.fullName, &email); err != nil {
+			return nil, fmt.Errorf("error scanning mentioned user: %v", err)
+		}
+		// Handles can't contain an @, so emails and handles share one map
+		resolved[email] = user
+		resolved[user.handle] = user
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentioned users: %v", err)
+	}
+
+	return resolved, nil
+}
+
+// saveMentions replaces the mentions stored for one field of a task, or for
+// a comment when commentID is set, and notifies the users who weren't already
+// mentioned there
+func saveMentions(ctx context.Context, tx pgx.Tx, task *models.Task, commentID *uuid.UUID, field string, text string) error {
+	previous := make(map[uuid.UUID]bool)
+	rows, err := tx.Query(ctx, `
+		DELETE FROM task_mentions
+		WHERE task_id = $1 AND field = $2 AND comment_id IS NOT DISTINCT FROM $3
+		RETURNING user_id
+	`, task.ID, field, commentID)
+	if err != nil {
+		return fmt.Errorf("error clearing mentions: %v", err)
+	}
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning previous mention: %v", err)
+		}
+		previous[userID] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error clearing mentions: %v", err)
+	}
+
+	tokens := parseMentions(text)
+	resolved, err := resolveMentions(ctx, tx, task.BoardID, tokens)
+	if err != nil {
+		return err
+	}
+
+	runes := []rune(text)
+	notified := make(map[uuid.UUID]bool)
+	for _, token := range tokens {
+		user, ok := resolved[token.key]
+		if !ok {
+			continue
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO task_mentions (task_id, comment_id, field, user_id, start_offset, end_offset, mention_text)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, task.ID, commentID, field, user.id, token.start, token.end, string(runes[token.start:token.end]))
+		if err != nil {
+			return fmt.Errorf("error creating mention: %v", err)
+		}
+
+		if previous[user.id] || notified[user.id] {
+			continue
+		}
+		notified[user.id] = true
+
+		data := map[string]any{
+			"task_title": task.Title,
+			"field":      field,
+			"excerpt":    mentionExcerpt(runes, token.start, token.end),
+		}
+		if commentID != nil {
+			data["comment_id"] = *commentID
+		}
+		if err := notifyUser(ctx, tx, user.id, models.NotificationMentioned, task.BoardID, &task.ID, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mentionExcerpt quotes the text around a mention for its notification,
+// collapsed onto one line
+func mentionExcerpt(runes []rune, start, end int) string {
+	from := max(start-mentionExcerptRadius, 0)
+	to := min(end+mentionExcerptRadius, len(runes))
+
+	excerpt := strings.Join(strings.Fields(string(runes[from:to])), " ")
+	if from > 0 {
+		excerpt = "…" + excerpt
+	}
+	if to < len(runes) {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// pruneBoardMentions removes mentions on a board's tasks of users who can no
+// longer see the board, after its members or visibility change or tasks move
+// onto it
+func pruneBoardMentions(ctx context.Context, tx pgx.Tx, boardID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM task_mentions m
+		USING tasks t, boards b
+		WHERE t.id = m.task_id
+		AND b.id = t.board_id
+		AND b.id = $1
+		AND b.is_public = false
+		AND b.owner_id <> m.user_id
+		AND NOT EXISTS (SELECT 1 FROM board_members bm WHERE bm.board_id = b.id AND bm.user_id = m.user_id)
+	`, boardID)
+	if err != nil {
+		return fmt.Errorf("error pruning mentions: %v", err)
+	}
+	return nil
+}
+
+// mentionsFor loads resolved mentions together with the users they refer to
+func mentionsFor(ctx context.Context, db *pgxpool.Pool, condition string, ids []uuid.UUID) ([]mentionRow, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			m.task_id,
+			m.comment_id,
+			m.field,
+			m.start_offset,
+			m.end_offset,
+			m.mention_text,
+			m.user_id,
+			u.handle,
+			u.full_name
+		FROM task_mentions m
+		JOIN users u ON u.id = m.user_id
+		WHERE `+condition+`
+		ORDER BY m.field, m.start_offset
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error querying mentions: %v", err)
+	}
+	defer rows.Close()
+
+	var mentions []mentionRow
+	for rows.Next() {
+		var row mentionRow
+		err := rows.Scan(
+			&row.taskID,
+			&row.mention.CommentID,
+			&row.mention.Field,
+			&row.mention.Start,
+			&row.mention.End,
+			&row.mention.Text,
+			&row.mention.UserID,
+			&row.mention.Handle,
+			&row.mention.FullName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning mention: %v", err)
+		}
+		mentions = append(mentions, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentions: %v", err)
+	}
+
+	return mentions, nil
+}
+
+// mentionRow is a mention along with the task it belongs to
+type mentionRow struct {
+	taskID  uuid.UUID
+	mention models.Mention
+}
+
+// mentionsForTasks returns the mentions in the text fields of each task,
+// keyed by task ID
+func mentionsForTasks(ctx context.Context, db *pgxpool.Pool, taskIDs []uuid.UUID) (map[uuid.UUID][]models.Mention, error) {
+	rows, err := mentionsFor(ctx, db, "m.task_id = ANY($1) AND m.comment_id IS NULL", taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := make(map[uuid.UUID][]models.Mention)
+	for _, row := range rows {
+		mentions[row.taskID] = append(mentions[row.taskID], row.mention)
+	}
+	return mentions, nil
+}
+
+// mentionsForComments returns the mentions in each comment, keyed by comment ID
+func mentionsForComments(ctx context.Context, db *pgxpool.Pool, commentIDs []uuid.UUID) (map[uuid.UUID][]models.Mention, error) {
+	rows, err := mentionsFor(ctx, db, "m.comment_id = ANY($1)", commentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := make(map[uuid.UUID][]models.Mention)
+	for _, row := range rows {
+		mentions[*row.mention.CommentID] = append(mentions[*row.mention.CommentID], row.mention)
+	}
+	return mentions, nil
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// maxDigestTasks caps the number of tasks listed in a digest
+const maxDigestTasks = 200
+
+// CreateDueSoonNotifications notifies assignees of unfinished tasks due
+// within the given window, as long as they can still see the task's board. Each assignee is notified once per due date, so
+// moving the due date notifies them again. It returns how many were created.
+func (r *NotificationRepository) CreateDueSoonNotifications(ctx context.Context, within time.Duration) (int64, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Only one replica scans at a time so nobody is notified twice
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('notifications:task_due_soon'))`).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("error locking due soon scan: %v", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO notifications (user_id, type, board_id, task_id, data, email_status)
+		SELECT
+			tc.assignee,
+			$2,
+			t.board_id,
+			t.id,
+			jsonb_build_object('task_title', t.title, 'due_date', tc.due_date),
+			CASE WHEN COALESCE(p.email, true) THEN 'pending' END
+		FROM task_contents tc
+		JOIN tasks t ON t.id = tc.task_id
+		JOIN task_statuses ts ON ts.id = t.status_id
+		LEFT JOIN notification_preferences p ON p.user_id = tc.assignee AND p.type = $2
+		WHERE tc.assignee IS NOT NULL
+		AND tc.due_date > CURRENT_TIMESTAMP
+		AND tc.due_date <= CURRENT_TIMESTAMP + make_interval(secs => $1)
+		AND ts.category <> 'done'
+		AND COALESCE(p.enabled, true)
+		AND `+boardAccessCondition("t.board_id", "tc.assignee")+`
+		AND NOT EXISTS (
+			SELECT 1 FROM notifications n
+			WHERE n.task_id = t.id
+			AND n.type = $2
+			AND n.user_id = tc.assignee
+			AND n.data -> 'due_date' = to_jsonb(tc.due_date)
+		)
+	`, within.Seconds(), models.NotificationTaskDueSoon)
+	if err != nil {
+		return 0, fmt.Errorf("error creating due soon notifications: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// ClaimDueEmails picks up to limit notifications due to be emailed. Claimed
+// notifications aren't due again until lease has passed, so other replicas
+// skip them while they're being sent.
+func (r *NotificationRepository) ClaimDueEmails(ctx context.Context, limit int, lease time.Duration) ([]models.PendingNotificationEmail, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM notifications
+			WHERE email_status = 'pending' AND email_next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY email_next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE notifications n
+			SET email_next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+			FROM due
+			WHERE n.id = due.id
+			RETURNING n.id, n.user_id, n.type, n.actor_id, n.board_id, n.task_id, n.data, n.email_attempts
+		)
+		SELECT
+			c.id,
+			c.type,
+			c.data,
+			c.task_id,
+			b.slug,
+			a.full_name,
+			c.email_attempts,
+			u.email,
+			u.full_name
+		FROM claimed c
+		JOIN users u ON u.id = c.user_id
+		LEFT JOIN users a ON a.id = c.actor_id
+		LEFT JOIN boards b ON b.id = c.board_id
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming notification emails: %v", err)
+	}
+	defer rows.Close()
+
+	emails := make([]models.PendingNotificationEmail, 0)
+	for rows.Next() {
+		var email models.PendingNotificationEmail
+		err := rows.Scan(
+			&email.ID,
+			&email.Type,
+			&email.Data,
+			&email.TaskID,
+			&email.BoardSlug,
+			&email.ActorName,
+			&email.Attempts,
+			&email.Email,
+			&email.FullName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning notification email: %v", err)
+		}
+		emails = append(emails, email)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification emails: %v", err)
+	}
+
+	return emails, nil
+}
+
+// MarkEmailSent records that a notification was emailed
+func (r *NotificationRepository) MarkEmailSent(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE notifications
+		SET
+			email_status = 'sent',
+			email_attempts = email_attempts + 1,
+			emailed_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("error marking notification emailed: %v", err)
+	}
+	return nil
+}
+
+// MarkEmailFailed records a failed attempt to email a notification. A nil
+// nextAttemptAt gives up on it.
+func (r *NotificationRepository) MarkEmailFailed(ctx context.Context, id uuid.UUID, nextAttemptAt *time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE notifications
+		SET
+			email_status = CASE WHEN $2::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			email_attempts = email_attempts + 1,
+			email_next_attempt_at = COALESCE($2, email_next_attempt_at)
+		WHERE id = $1
+	`, id, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("error marking notification email failed: %v", err)
+	}
+	return nil
+}
+
+// ClaimDigests picks up to limit users who haven't had a digest on day and
+// marks theirs as sent, so each user gets at most one a day. Users who
+// turned the digest off are skipped.
+func (r *NotificationRepository) ClaimDigests(ctx context.Context, day time.Time, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		INSERT INTO notification_digests (user_id, sent_on)
+		SELECT u.id, $1::date
+		FROM users u
+		LEFT JOIN notification_preferences p ON p.user_id = u.id AND p.type = $3
+		WHERE COALESCE(p.enabled, true) AND COALESCE(p.email, true)
+		AND NOT EXISTS (
+			SELECT 1 FROM notification_digests d
+			WHERE d.user_id = u.id AND d.sent_on = $1::date
+		)
+		LIMIT $2
+		ON CONFLICT DO NOTHING
+		RETURNING user_id
+	`, day.Format("2006-01-02"), limit, models.NotificationDailyDigest)
+	if err != nil {
+		return nil, fmt.Errorf("error claiming digests: %v", err)
+	}
+	defer rows.Close()
+
+	userIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("error scanning digest: %v", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating digests: %v", err)
+	}
+
+	return userIDs, nil
+}
+
+// GetDigest collects a user's overdue tasks and those due within a week of
+// now, across every board they own or are a member of. Finished tasks are
+// left out. At most maxDigestTasks are listed, soonest due first, but the
+// counts cover every task.
+func (r *NotificationRepository) GetDigest(ctx context.Context, userID uuid.UUID, now time.Time) (*models.Digest, error) {
+	digest := &models.Digest{
+		UserID:      userID,
+		Overdue:     make([]models.DigestTask, 0),
+		DueThisWeek: make([]models.DigestTask, 0),
+	}
+
+	err := r.db.QueryRow(ctx, `SELECT email, full_name FROM users WHERE id = $1`, userID).Scan(&digest.Email, &digest.FullName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting digest user: %v", err)
+	}
+
+	const digestTasks = `
+		FROM tasks t
+		JOIN task_contents tc ON tc.task_id = t.id
+		JOIN boards b ON b.id = t.board_id
+		JOIN task_statuses ts ON ts.id = t.status_id
+		WHERE tc.due_date IS NOT NULL
+		AND tc.due_date < $2
+		AND ts.category <> 'done'
+		AND (
+			b.owner_id = $1 OR
+			EXISTS (
+				SELECT 1 FROM board_members bm
+				WHERE bm.board_id = b.id AND bm.user_id = $1
+			)
+		)`
+	weekEnd := now.AddDate(0, 0, 7)
+
+	err = r.db.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE tc.due_date < $3),
+			COUNT(*) FILTER (WHERE tc.due_date >= $3)
+	`+digestTasks, userID, weekEnd, now).Scan(&digest.OverdueCount, &digest.DueThisWeekCount)
+	if err != nil {
+		return nil, fmt.Errorf("error counting digest tasks: %v", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT t.id, t.title, b.name, b.slug, ts.name, tc.due_date
+	`+digestTasks+fmt.Sprintf(`
+		ORDER BY tc.due_date, t.id
+		LIMIT %d`, maxDigestTasks), userID, weekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error getting digest tasks: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task models.DigestTask
+		err := rows.Scan(
+			&task.ID,
+			&task.Title,
+			&task.BoardName,
+			&task.BoardSlug,
+			&task.StatusName,
+			&task.DueDate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning digest task: %v", err)
+		}
+		if task.DueDate.Before(now) {
+			digest.Overdue = append(digest.Overdue, task)
+		} else {
+			digest.DueThisWeek = append(digest.DueThisWeek, task)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating digest tasks: %v", err)
+	}
+
+	return digest, nil
+}
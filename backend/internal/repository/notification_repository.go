@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// NotificationRepository handles database operations for notifications and
+// notification preferences
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// ListNotifications retrieves a page of a user's notifications, newest
+// first, optionally only the unread ones
+func (r *NotificationRepository) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, cursor string, limit int) (*models.NotificationPage, error) {
+	query := `
+		SELECT
+			n.id,
+			n.user_id,
+			n.type,
+			n.actor_id,
+			u.full_name,
+			n.board_id,
+			n.task_id,
+			n.data,
+			n.read_at,
+			n.created_at
+		FROM notifications n
+		LEFT JOIN users u ON u.id = n.actor_id
+		WHERE n.user_id = $1`
+	args := []interface{}{userID}
+
+	if unreadOnly {
+		query += ` AND n.read_at IS NULL`
+	}
+	keyset := newestFirst("n.created_at", "n.id")
+	if cursor != "" {
+		key, err := keyset.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, key)
+		query += " AND " + keyset.After(len(args))
+	}
+
+	// Fetch one extra row to know whether there's another page
+	query += keyset.OrderBy() + fmt.Sprintf(" LIMIT %d", limit+1)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing notifications: %v", err)
+	}
+	defer rows.Close()
+
+	page := &models.NotificationPage{Items: make([]models.Notification, 0, limit)}
+	for rows.Next() {
+		var notification models.Notification
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Type,
+			&notification.ActorID,
+			&notification.ActorName,
+			&notification.BoardID,
+			&notification.TaskID,
+			&notification.Data,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning notification: %v", err)
+		}
+		page.Items = append(page.Items, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %v", err)
+	}
+
+	if len(page.Items) > limit {
+		last := page.Items[limit-1]
+		page.Items = page.Items[:limit]
+		page.NextCursor = keyset.CursorAt(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// CountUnread returns how many of a user's notifications are unread
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = $1 AND read_at IS NULL
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting notifications: %v", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of a user's notifications as read. Marking a
+// notification that's already read is a no-op.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("error marking notification read: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks all of a user's notifications as read and returns how
+// many were unread
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE notifications
+		SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND read_at IS NULL
+	`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error marking notifications read: %v", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// GetPreferences returns whether a user gets each type of notification
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID uuid.UUID) ([]models.NotificationPreference, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT type, enabled, email
+		FROM notification_preferences
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting notification preferences: %v", err)
+	}
+	defer rows.Close()
+
+	stored := make(map[string]models.NotificationPreference)
+	for rows.Next() {
+		var preference models.NotificationPreference
+		if err := rows.Scan(&preference.Type, &preference.Enabled, &preference.Email); err != nil {
+			return nil, fmt.Errorf("error scanning notification preference: %v", err)
+		}
+		stored[preference.Type] = preference
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification preferences: %v", err)
+	}
+
+	// Types without a stored preference are on, in-app and by email
+	preferences := make([]models.NotificationPreference, 0, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preference, ok := stored[notificationType]
+		if !ok {
+			preference = models.NotificationPreference{Type: notificationType, Enabled: true, Email: true}
+		}
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences turns notification types, or emailing them, on or off
+// for a user. Types and settings that aren't given keep their current value.
+func (r *NotificationRepository) UpdatePreferences(ctx context.Context, userID uuid.UUID, preferences map[string]models.NotificationPreferenceInput) ([]models.NotificationPreference, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for notificationType, preference := range preferences {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO notification_preferences (user_id, type, enabled, email)
+			VALUES ($1, $2, COALESCE($3, true), COALESCE($4, true))
+			ON CONFLICT (user_id, type) DO UPDATE
+			SET
+				enabled = COALESCE($3, notification_preferences.enabled),
+				email = COALESCE($4, notification_preferences.email)
+		`, userID, notificationType, preference.Enabled, preference.Email)
+		if err != nil {
+			return nil, fmt.Errorf("error updating notification preference: %v", err)
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return r.GetPreferences(ctx, userID)
+}
+
+// notifyUser notifies a user about a change made in tx. Nobody is notified
+// about their own changes, and users who turned the type off or can no
+// longer see the board aren't notified.
+// Types that are emailed are queued for email unless the user turned that off.
+func notifyUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, notificationType string, boardID *uuid.UUID, taskID *uuid.UUID, data map[string]any) error {
+	actorID := requestInfoFrom(ctx).ActorID
+	if actorID != nil && *actorID == userID {
+		return nil
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error marshaling notification data: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO notifications (user_id, type, actor_id, board_id, task_id, data, email_status)
+		SELECT
+			$1, $2, $3, $4, $5, $6,
+			CASE WHEN $7::boolean AND COALESCE(p.email, true) THEN 'pending' END
+		FROM (SELECT 1) AS one
+		LEFT JOIN notification_preferences p ON p.user_id = $1 AND p.type = $2
+		WHERE COALESCE(p.enabled, true)
+		AND `+boardAccessCondition("$4::uuid", "$1::uuid"), userID, notificationType, actorID, boardID, taskID, dataJSON, models.IsEmailNotification(notificationType))
+	if err != nil {
+		return fmt.Errorf("error creating notification: %v", err)
+	}
+	return nil
+}
+
+// notifyTaskChanges notifies the people affected by a task being created or
+// updated: a new assignee, and the owner when the status changes
+func notifyTaskChanges(ctx context.Context, tx pgx.Tx, before *models.Task, after *models.Task, assignee *uuid.UUID) error {
+	var previousAssignee *uuid.UUID
+	if before != nil {
+		previousAssignee = before.Content.Assignee
+	}
+	if assignee != nil && (previousAssignee == nil || *previousAssignee != *assignee) {
+		if err := notifyUser(ctx, tx, *assignee, models.NotificationTaskAssigned, after.BoardID, &after.ID, map[string]any{
+			"task_title": after.Title,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if before != nil && before.StatusID != after.StatusID && after.OwnerID != nil {
+		if err := notifyUser(ctx, tx, *after.OwnerID, models.NotificationTaskStatusChanged, after.BoardID, &after.ID, map[string]any{
+			"task_title":     after.Title,
+			"from_status_id": before.StatusID,
+			"to_status_id":   after.StatusID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestNotifyTaskChanges(t *testing.T) {
+	actorID, ownerID, assigneeID, otherID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	ctx := WithRequestInfo(context.Background(), RequestInfo{ActorID: &actorID})
+
+	task := func(statusID int32, assignee *uuid.UUID) *models.Task {
+		return &models.Task{ID: uuid.New(), Title: "Fix login", StatusID: statusID, OwnerID: &ownerID, Content: models.TaskContent{Assignee: assignee}}
+	}
+
+	// sent is who was notified of what, with whether it's emailed
+	type sent struct {
+		userID           uuid.UUID
+		notificationType string
+		email            bool
+	}
+
+	tests := []struct {
+		name     string
+		before   *models.Task
+		after    *models.Task
+		assignee *uuid.UUID
+		want     []sent
+	}{
+		{"new task", nil, task(1, nil), nil, nil},
+		{"new task with an assignee", nil, task(1, nil), &assigneeID, []sent{{assigneeID, models.NotificationTaskAssigned, true}}},
+		{"assigning yourself", task(1, nil), task(1, nil), &actorID, nil},
+		{"same assignee", task(1, &assigneeID), task(1, &assigneeID), &assigneeID, nil},
+		{"reassigned", task(1, &assigneeID), task(1, &otherID), &otherID, []sent{{otherID, models.NotificationTaskAssigned, true}}},
+		{"unassigned", task(1, &assigneeID), task(1, nil), nil, nil},
+		{"status changed", task(1, nil), task(2, nil), nil, []sent{{ownerID, models.NotificationTaskStatusChanged, false}}},
+		{
+			"reassigned and moved",
+			task(1, nil), task(2, &assigneeID), &assigneeID,
+			[]sent{{assigneeID, models.NotificationTaskAssigned, true}, {ownerID, models.NotificationTaskStatusChanged, false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{}
+			if err := notifyTaskChanges(ctx, tx, tt.before, tt.after, tt.assignee); err != nil {
+				t.Fatalf("notifyTaskChanges() error = %v", err)
+			}
+
+			var got []sent
+			for _, args := range tx.execs {
+				got = append(got, sent{args[0].(uuid.UUID), args[1].(string), args[6].(bool)})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("notified %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("notification %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidSort is returned when a list is sorted by a field it can't be
+// sorted by
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortKey is an expression a list is ordered by
+type SortKey struct {
+	// Expr is the SQL sorted on
+	Expr string
+	// Type is the SQL type of Expr, used to read its value back from a cursor
+	Type string
+	// Last is set for nullable expressions: the values that stand in for
+	// NULL ascending and descending, so rows without a value sort last
+	// either way
+	Last [2]string
+}
+
+// expr returns the SQL for the key, with NULL replaced for nullable keys
+func (k SortKey) expr(descending bool) string {
+	if k.Last[0] == "" {
+		return k.Expr
+	}
+	last := k.Last[0]
+	if descending {
+		last = k.Last[1]
+	}
+	return "COALESCE(" + k.Expr + ", " + last + ")"
+}
+
+// SortFields maps the fields a list can be sorted by to the keys they sort on
+type SortFields map[string][]SortKey
+
+// Names returns the fields in alphabetical order
+func (f SortFields) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Keyset returns the keyset for a sort: a field, with a leading - to sort
+// descending. An empty sort is defaultSort. id is the unique key ties fall
+// back to.
+func (f SortFields) Keyset(sort string, defaultSort string, id SortKey) (*Keyset, error) {
+	if sort == "" {
+		sort = defaultSort
+	}
+	descending := strings.HasPrefix(sort, "-")
+
+	keys, ok := f[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	return &Keyset{
+		sort:       sort,
+		keys:       append(append([]SortKey{}, keys...), id),
+		descending: descending,
+	}, nil
+}
+
+// newestFirst returns the keyset of feeds that are always listed newest
+// first, by their created_at and id columns
+func newestFirst(createdAt string, id string) *Keyset {
+	return &Keyset{
+		sort: "-created_at",
+		keys: []SortKey{
+			{Expr: createdAt, Type: "timestamptz"},
+			{Expr: id, Type: "uuid"},
+		},
+		descending: true,
+	}
+}
+
+// Keyset orders a list and pages through it. A cursor holds the sort values
+// of the last row of a page, so the next page starts after that row even if
+// rows were added or removed in between.
+type Keyset struct {
+	sort       string
+	keys       []SortKey
+	descending bool
+}
+
+// exprs returns the SQL of each key
+func (k *Keyset) exprs() []string {
+	exprs := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		exprs[i] = key.expr(k.descending)
+	}
+	return exprs
+}
+
+// OrderBy returns the ORDER BY clause
+func (k *Keyset) OrderBy() string {
+	exprs := k.exprs()
+	if k.descending {
+		for i := range exprs {
+			exprs[i] += " DESC"
+		}
+	}
+	return " ORDER BY " + strings.Join(exprs, ", ")
+}
+
+// KeyColumn returns a select column holding a row's sort values, to be
+// passed to Cursor
+func (k *Keyset) KeyColumn() string {
+	return "json_build_array(" + strings.Join(k.exprs(), ", ") + ")::text"
+}
+
+// After returns the condition that selects the rows after a cursor, whose
+// decoded key is bound to the given parameter
+func (k *Keyset) After(param int) string {
+	values := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		values[i] = fmt.Sprintf("($%d::text::jsonb ->> %d)::%s", param, i, key.Type)
+	}
+
+	op := ">"
+	if k.descending {
+		op = "<"
+	}
+	return "(" + strings.Join(k.exprs(), ", ") + ") " + op + " (" + strings.Join(values, ", ") + ")"
+}
+
+// keysetCursor is the content of a cursor: the sort it was made for and the
+// sort values of the row it points after
+type keysetCursor struct {
+	Sort string          `json:"s"`
+	Key  json.RawMessage `json:"k"`
+}
+
+// Cursor returns the cursor after a row, given its KeyColumn
+func (k *Keyset) Cursor(key string) string {
+	raw, _ := json.Marshal(keysetCursor{Sort: k.sort, Key: json.RawMessage(key)})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// CursorAt returns the cursor after a row given its sort values, for lists
+// that scan them into their items rather than selecting KeyColumn
+func (k *Keyset) CursorAt(values ...any) string {
+	key, _ := json.Marshal(values)
+	return k.Cursor(string(key))
+}
+
+// Decode returns the key held by a cursor. A cursor made for another sort is
+// invalid.
+func (k *Keyset) Decode(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+
+	var decoded keysetCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.Sort != k.sort {
+		return "", ErrInvalidCursor
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(decoded.Key, &values); err != nil || len(values) != len(k.keys) {
+		return "", ErrInvalidCursor
+	}
+
+	return string(decoded.Key), nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var testSortFields = SortFields{
+	"name":     {{Expr: "LOWER(x.name)", Type: "text"}},
+	"due_date": {{Expr: "x.due_date", Type: "timestamptz", Last: [2]string{"'infinity'", "'-infinity'"}}},
+}
+
+func testKeyset(t *testing.T, sort string) *Keyset {
+	t.Helper()
+	keyset, err := testSortFields.Keyset(sort, "name", SortKey{Expr: "x.id", Type: "uuid"})
+	if err != nil {
+		t.Fatalf("Keyset(%q) error = %v", sort, err)
+	}
+	return keyset
+}
+
+func TestKeysetCursorRoundTrip(t *testing.T) {
+	keyset := testKeyset(t, "-name")
+	key := `["ana","0b7c8f1e-6f4a-4c36-9a57-2f1f0f3b8a11"]`
+
+	cursor := keyset.Cursor(key)
+	if strings.ContainsAny(cursor, "+/=") {
+		t.Errorf("cursor %q isn't URL-safe", cursor)
+	}
+
+	decoded, err := keyset.Decode(cursor)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded != key {
+		t.Errorf("Decode() = %s, want %s", decoded, key)
+	}
+}
+
+func TestKeysetCursorAt(t *testing.T) {
+	keyset := newestFirst("a.created_at", "a.id")
+	createdAt := time.Date(2025, 3, 14, 15, 30, 0, 123456000, time.UTC)
+	id := uuid.MustParse("0b7c8f1e-6f4a-4c36-9a57-2f1f0f3b8a11")
+
+	key, err := keyset.Decode(keyset.CursorAt(createdAt, id))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if want := `["2025-03-14T15:30:00.123456Z","0b7c8f1e-6f4a-4c36-9a57-2f1f0f3b8a11"]`; key != want {
+		t.Errorf("key = %s, want %s", key, want)
+	}
+}
+
+func TestKeysetDecodeRejectsInvalidCursors(t *testing.T) {
+	keyset := testKeyset(t, "name")
+	valid := keyset.Cursor(`["ana","0b7c8f1e-6f4a-4c36-9a57-2f1f0f3b8a11"]`)
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := map[string]string{
+		"not base64":           "not a cursor!",
+		"not JSON":             encode("name|ana"),
+		"another sort":         testKeyset(t, "-name").Cursor(`["ana","0b7c8f1e-6f4a-4c36-9a57-2f1f0f3b8a11"]`),
+		"another list's sort":  newestFirst("a.created_at", "a.id").CursorAt(time.Now(), uuid.New()),
+		"too few values":       encode(`{"s":"name","k":["ana"]}`),
+		"too many values":      encode(`{"s":"name","k":["ana","0b7c8f1e-6f4a-4c36-9a57-2f1f0f3b8a11",1]}`),
+		"key isn't an array":   encode(`{"s":"name","k":"ana"}`),
+		"tampered":             valid[:len(valid)-2] + "xx",
+		"standard base64 pads": base64.StdEncoding.EncodeToString([]byte(`{"s":"name","k":["a","b"]}`)) + "=",
+	}
+
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := keyset.Decode(cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode(%q) error = %v, want %v", cursor, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestKeysetBreaksTiesOnID(t *testing.T) {
+	// Rows with the same name are ordered by ID, and the cursor compares the
+	// whole tuple, so a page boundary inside a run of equal names neither
+	// skips nor repeats rows
+	tests := []struct {
+		sort    string
+		orderBy string
+		after   string
+	}{
+		{
+			sort:    "name",
+			orderBy: " ORDER BY LOWER(x.name), x.id",
+			after:   "(LOWER(x.name), x.id) > (($3::text::jsonb ->> 0)::text, ($3::text::jsonb ->> 1)::uuid)",
+		},
+		{
+			sort:    "-name",
+			orderBy: " ORDER BY LOWER(x.name) DESC, x.id DESC",
+			after:   "(LOWER(x.name), x.id) < (($3::text::jsonb ->> 0)::text, ($3::text::jsonb ->> 1)::uuid)",
+		},
+		{
+			// Missing due dates sort last either way
+			sort:    "due_date",
+			orderBy: " ORDER BY COALESCE(x.due_date, 'infinity'), x.id",
+			after:   "(COALESCE(x.due_date, 'infinity'), x.id) > (($3::text::jsonb ->> 0)::timestamptz, ($3::text::jsonb ->> 1)::uuid)",
+		},
+		{
+			sort:    "-due_date",
+			orderBy: " ORDER BY COALESCE(x.due_date, '-infinity') DESC, x.id DESC",
+			after:   "(COALESCE(x.due_date, '-infinity'), x.id) < (($3::text::jsonb ->> 0)::timestamptz, ($3::text::jsonb ->> 1)::uuid)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			keyset := testKeyset(t, tt.sort)
+			if got := keyset.OrderBy(); got != tt.orderBy {
+				t.Errorf("OrderBy() = %q, want %q", got, tt.orderBy)
+			}
+			if got := keyset.After(3); got != tt.after {
+				t.Errorf("After(3) = %q, want %q", got, tt.after)
+			}
+		})
+	}
+}
+
+func TestKeysetDefaultAndInvalidSort(t *testing.T) {
+	if got := testKeyset(t, "").OrderBy(); got != " ORDER BY LOWER(x.name), x.id" {
+		t.Errorf("default OrderBy() = %q", got)
+	}
+
+	for _, sort := range []string{"title", "--name", "name,due_date"} {
+		if _, err := testSortFields.Keyset(sort, "name", SortKey{Expr: "x.id", Type: "uuid"}); !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("Keyset(%q) error = %v, want %v", sort, err, ErrInvalidSort)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rafaelzasas/vtasker/backend/internal/config"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
 	"github.com/rafaelzasas/vtasker/backend/internal/models/user"
 	"github.com/rafaelzasas/vtasker/backend/internal/repository"
 )
@@ -73,7 +74,7 @@ func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
 	query := `
 		SELECT 
-			u.id, u.email, u.password_hash, u.full_name, u.avatar_url, u.role_id,
+			u.id, u.email, u.password_hash, u.full_name, u.handle, u.avatar_url, u.role_id,
 			u.created_at, u.updated_at, u.last_login_at,
 			r.id, r.code, r.name, r.description, r.created_at, r.updated_at
 		FROM users u
@@ -84,7 +85,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.User,
 	var role user.UserRole
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&u.ID, &u.Email, &u.PasswordHash,
-		&u.FullName, &u.AvatarURL, &u.RoleID,
+		&u.FullName, &u.Handle, &u.AvatarURL, &u.RoleID,
 		&u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt,
 		&role.ID, &role.Code, &role.Name, &role.Description,
 		&role.CreatedAt, &role.UpdatedAt,
@@ -105,7 +106,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.User,
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
 	query := `
 		SELECT 
-			u.id, u.email, u.password_hash, u.full_name, u.avatar_url, u.role_id,
+			u.id, u.email, u.password_hash, u.full_name, u.handle, u.avatar_url, u.role_id,
 			u.created_at, u.updated_at, u.last_login_at,
 			r.id, r.code, r.name, r.description, r.created_at, r.updated_at
 		FROM users u
@@ -116,7 +117,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*user.Us
 	var role user.UserRole
 	err := r.db.QueryRow(ctx, query, email).Scan(
 		&u.ID, &u.Email, &u.PasswordHash,
-		&u.FullName, &u.AvatarURL, &u.RoleID,
+		&u.FullName, &u.Handle, &u.AvatarURL, &u.RoleID,
 		&u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt,
 		&role.ID, &role.Code, &role.Name, &role.Description,
 		&role.CreatedAt, &role.UpdatedAt,
@@ -137,7 +138,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*user.Us
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*user.User, error) {
 	query := `
 		SELECT 
-			u.id, u.email, u.password_hash, u.full_name, u.avatar_url, u.role_id,
+			u.id, u.email, u.password_hash, u.full_name, u.handle, u.avatar_url, u.role_id,
 			u.created_at, u.updated_at, u.last_login_at,
 			r.id, r.code, r.name, r.description, r.created_at, r.updated_at
 		FROM users u
@@ -148,7 +149,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*u
 	var role user.UserRole
 	err := r.db.QueryRow(ctx, query, username).Scan(
 		&u.ID, &u.Email, &u.PasswordHash,
-		&u.FullName, &u.AvatarURL, &u.RoleID,
+		&u.FullName, &u.Handle, &u.AvatarURL, &u.RoleID,
 		&u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt,
 		&role.ID, &role.Code, &role.Name, &role.Description,
 		&role.CreatedAt, &role.UpdatedAt,
@@ -190,6 +191,13 @@ func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
 		}
 	}
 
+	// Start a transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		UPDATE users
 		SET email = $1, password_hash = $2,
@@ -197,7 +205,7 @@ func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
 			updated_at = $6, last_login_at = $7
 		WHERE id = $8`
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := tx.Exec(ctx, query,
 		u.Email, u.PasswordHash,
 		u.FullName, u.AvatarURL, u.RoleID,
 		u.UpdatedAt, u.LastLoginAt, u.ID,
@@ -211,11 +219,38 @@ func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
 		return repository.ErrNotFound
 	}
 
+	// Role changes are privileged, so they go in the audit trail
+	if u.RoleID != currentUser.RoleID {
+		var roleCode string
+		if err := tx.QueryRow(ctx, "SELECT code FROM user_roles WHERE id = $1", u.RoleID).Scan(&roleCode); err != nil {
+			return fmt.Errorf("failed to get role: %w", err)
+		}
+
+		err = repository.RecordAudit(ctx, tx, models.AuditUserRoleChanged, models.AuditEntityUser, u.ID,
+			map[string]any{"role_id": currentUser.RoleID, "role_code": currentUser.GetRole()},
+			map[string]any{"role_id": u.RoleID, "role_code": roleCode},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
-// Delete deletes a user by ID
+// Delete deletes a user by ID, along with the boards they own
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	// Keep a snapshot of the user for the audit trail
+	previous, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	// Start a transaction
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -230,7 +265,7 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	// Then, delete any boards owned by the user
-	_, err = tx.Exec(ctx, `DELETE FROM boards WHERE owner_id = $1`, id)
+	boards, err := tx.Exec(ctx, `DELETE FROM boards WHERE owner_id = $1`, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete boards: %w", err)
 	}
@@ -245,6 +280,14 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		return repository.ErrNotFound
 	}
 
+	err = repository.RecordAudit(ctx, tx, models.AuditUserDeleted, models.AuditEntityUser, id,
+		map[string]any{"user": previous, "owned_boards": boards.RowsAffected()},
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
 	// Commit the transaction
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -274,26 +317,11 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) erro
 
 // List retrieves all users with optional filtering
 func (r *UserRepository) List(ctx context.Context, filter repository.UserFilter) ([]*user.User, error) {
-	var conditions []string
-	var args []interface{}
-	argCount := 1
-
-	if filter.Role != nil {
-		conditions = append(conditions, fmt.Sprintf("r.code = $%d", argCount))
-		args = append(args, *filter.Role)
-		argCount++
-	}
-
-	if filter.Search != "" {
-		searchTerm := "%" + filter.Search + "%"
-		conditions = append(conditions, fmt.Sprintf("(u.email ILIKE $%d OR u.full_name ILIKE $%d)", argCount, argCount))
-		args = append(args, searchTerm)
-		argCount++
-	}
+	conditions, args := userConditions(filter)
 
 	query := `
 		SELECT 
-			u.id, u.email, u.password_hash, u.full_name, u.avatar_url, u.role_id,
+			u.id, u.email, u.password_hash, u.full_name, u.handle, u.avatar_url, u.role_id,
 			u.created_at, u.updated_at, u.last_login_at,
 			r.id, r.code, r.name, r.description, r.created_at, r.updated_at
 		FROM users u
@@ -327,7 +355,7 @@ func (r *UserRepository) List(ctx context.Context, filter repository.UserFilter)
 		var role user.UserRole
 		err := rows.Scan(
 			&u.ID, &u.Email, &u.PasswordHash,
-			&u.FullName, &u.AvatarURL, &u.RoleID,
+			&u.FullName, &u.Handle, &u.AvatarURL, &u.RoleID,
 			&u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt,
 			&role.ID, &role.Code, &role.Name, &role.Description,
 			&role.CreatedAt, &role.UpdatedAt,
@@ -346,6 +374,105 @@ func (r *UserRepository) List(ctx context.Context, filter repository.UserFilter)
 	return users, nil
 }
 
+// ListPage retrieves a page of users with optional filtering, in the order of
+// a sort from repository.UserSortFields. A limit of 0 returns every user.
+func (r *UserRepository) ListPage(ctx context.Context, filter repository.UserFilter, sort string, cursor string, limit int) (*user.UserPage, error) {
+	keyset, err := repository.UserKeyset(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, args := userConditions(filter)
+	if cursor != "" {
+		key, err := keyset.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, key)
+		conditions = append(conditions, keyset.After(len(args)))
+	}
+
+	query := `
+		SELECT
+			u.id, u.email, u.password_hash, u.full_name, u.handle, u.avatar_url, u.role_id,
+			u.created_at, u.updated_at, u.last_login_at,
+			r.id, r.code, r.name, r.description, r.created_at, r.updated_at,
+			` + keyset.KeyColumn() + `
+		FROM users u
+		JOIN user_roles r ON r.id = u.role_id`
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += keyset.OrderBy()
+	if limit > 0 {
+		// Fetch one extra row to know whether there's another page
+		query += fmt.Sprintf(" LIMIT %d", limit+1)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	page := &user.UserPage{Items: make([]*user.User, 0)}
+	var sortKey, lastKey string
+	for rows.Next() {
+		var u user.User
+		var role user.UserRole
+		err := rows.Scan(
+			&u.ID, &u.Email, &u.PasswordHash,
+			&u.FullName, &u.Handle, &u.AvatarURL, &u.RoleID,
+			&u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt,
+			&role.ID, &role.Code, &role.Name, &role.Description,
+			&role.CreatedAt, &role.UpdatedAt,
+			&sortKey,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user row: %w", err)
+		}
+		if limit > 0 && len(page.Items) == limit {
+			// The extra row means there's another page after this one
+			page.NextCursor = keyset.Cursor(lastKey)
+			break
+		}
+		u.Role = &role
+		page.Items = append(page.Items, &u)
+		lastKey = sortKey
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return page, nil
+}
+
+// userConditions returns the WHERE conditions for a user filter, with users
+// aliased as u and their roles as r, and their arguments
+func userConditions(filter repository.UserFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	argCount := 1
+
+	if filter.Role != nil {
+		conditions = append(conditions, fmt.Sprintf("r.code = $%d", argCount))
+		args = append(args, *filter.Role)
+		argCount++
+	}
+
+	if filter.Search != "" {
+		searchTerm := "%" + filter.Search + "%"
+		conditions = append(conditions, fmt.Sprintf("(u.email ILIKE $%d OR u.full_name ILIKE $%d OR u.handle ILIKE $%d)", argCount, argCount, argCount))
+		args = append(args, searchTerm)
+		argCount++
+	}
+
+	return conditions, args
+}
+
 // ValidateAndEnsureSuperAdmin checks if the user should be a super admin based on email
 // and updates their role if necessary
 func (r *UserRepository) ValidateAndEnsureSuperAdmin(ctx context.Context, u *user.User) error {
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+const taskEditLockColumns = `
+	task_id,
+	field,
+	session_id,
+	user_id,
+	acquired_at,
+	expires_at`
+
+// PresenceRepository handles database operations for board sessions and
+// task edit locks
+type PresenceRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPresenceRepository creates a new presence repository
+func NewPresenceRepository(db *pgxpool.Pool) *PresenceRepository {
+	return &PresenceRepository{db: db}
+}
+
+// CreateSession records a user connecting to a board
+func (r *PresenceRepository) CreateSession(ctx context.Context, boardID uuid.UUID, userID uuid.UUID) (*models.BoardSession, error) {
+	session := models.BoardSession{BoardID: boardID, UserID: userID}
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO board_sessions (board_id, user_id)
+		VALUES ($1, $2)
+		RETURNING id, connected_at
+	`, boardID, userID).Scan(&session.ID, &session.ConnectedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating session: %v", err)
+	}
+	return &session, nil
+}
+
+// EndSession removes a session along with the locks it holds
+func (r *PresenceRepository) EndSession(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM board_sessions WHERE id = $1`, sessionID)
+	if err != nil {
+		return fmt.Errorf("error ending session: %v", err)
+	}
+	return nil
+}
+
+// TouchSession marks a session as still connected. It returns ErrNotFound
+// when the session has already been pruned.
+func (r *PresenceRepository) TouchSession(ctx context.Context, sessionID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE board_sessions
+		SET last_seen_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, sessionID)
+	if err != nil {
+		return fmt.Errorf("error touching session: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PruneBoard removes a board's sessions that haven't been seen within
+// staleAfter, such as those left by a replica that stopped, and its
+// expired locks
+func (r *PresenceRepository) PruneBoard(ctx context.Context, boardID uuid.UUID, staleAfter time.Duration) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM board_sessions
+		WHERE board_id = $1 AND last_seen_at < CURRENT_TIMESTAMP - make_interval(secs => $2)
+	`, boardID, staleAfter.Seconds())
+	if err != nil {
+		return fmt.Errorf("error pruning sessions: %v", err)
+	}
+
+	_, err = r.db.Exec(ctx, `
+		DELETE FROM task_edit_locks
+		WHERE board_id = $1 AND expires_at <= CURRENT_TIMESTAMP
+	`, boardID)
+	if err != nil {
+		return fmt.Errorf("error pruning locks: %v", err)
+	}
+	return nil
+}
+
+// SetSessionTask records which task a session has open; nil closes it. It
+// returns ErrNotFound when the task isn't on the session's board.
+func (r *PresenceRepository) SetSessionTask(ctx context.Context, sessionID uuid.UUID, taskID *uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE board_sessions s
+		SET task_id = $2
+		WHERE s.id = $1
+		AND (
+			$2::uuid IS NULL OR
+			EXISTS (SELECT 1 FROM tasks t WHERE t.id = $2 AND t.board_id = s.board_id)
+		)
+	`, sessionID, taskID)
+	if err != nil {
+		return fmt.Errorf("error setting session task: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AcquireLock takes or renews a session's soft lock on a task field for ttl.
+// When another session holds an unexpired lock on the field, that lock is
+// returned with acquired false. It returns ErrNotFound when the task isn't
+// on the session's board.
+func (r *PresenceRepository) AcquireLock(ctx context.Context, session *models.BoardSession, taskID uuid.UUID, field string, ttl time.Duration) (*models.TaskEditLock, bool, error) {
+	// Renewing keeps the original acquired_at; taking over an expired lock
+	// starts a new one
+	row := r.db.QueryRow(ctx, `
+		INSERT INTO task_edit_locks (task_id, field, board_id, session_id, user_id, expires_at)
+		SELECT id, $2, board_id, $3, $4, CURRENT_TIMESTAMP + make_interval(secs => $5)
+		FROM tasks
+		WHERE id = $1 AND board_id = $6
+		ON CONFLICT (task_id, field) DO UPDATE
+		SET
+			session_id = EXCLUDED.session_id,
+			user_id = EXCLUDED.user_id,
+			acquired_at = CASE
+				WHEN task_edit_locks.session_id = EXCLUDED.session_id THEN task_edit_locks.acquired_at
+				ELSE EXCLUDED.acquired_at
+			END,
+			expires_at = EXCLUDED.expires_at
+		WHERE task_edit_locks.session_id = EXCLUDED.session_id
+		OR task_edit_locks.expires_at <= CURRENT_TIMESTAMP
+		RETURNING `+taskEditLockColumns,
+		taskID, field, session.ID, session.UserID, ttl.Seconds(), session.BoardID)
+	lock, err := scanTaskEditLock(row)
+	if err == nil {
+		return lock, true, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, false, err
+	}
+
+	// Nothing was written: either someone else holds the lock or the task
+	// isn't on the board
+	row = r.db.QueryRow(ctx, `
+		SELECT
+			l.task_id,
+			l.field,
+			l.session_id,
+			l.user_id,
+			l.acquired_at,
+			l.expires_at,
+			u.full_name
+		FROM task_edit_locks l
+		LEFT JOIN users u ON u.id = l.user_id
+		WHERE l.task_id = $1 AND l.field = $2 AND l.board_id = $3
+	`, taskID, field, session.BoardID)
+
+	var holder models.TaskEditLock
+	err = row.Scan(
+		&holder.TaskID,
+		&holder.Field,
+		&holder.SessionID,
+		&holder.UserID,
+		&holder.AcquiredAt,
+		&holder.ExpiresAt,
+		&holder.FullName,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, ErrNotFound
+		}
+		return nil, false, fmt.Errorf("error getting lock: %v", err)
+	}
+	return &holder, false, nil
+}
+
+// ReleaseLock drops a session's lock on a task field, if it holds one
+func (r *PresenceRepository) ReleaseLock(ctx context.Context, sessionID uuid.UUID, taskID uuid.UUID, field string) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM task_edit_locks
+		WHERE task_id = $1 AND field = $2 AND session_id = $3
+	`, taskID, field, sessionID)
+	if err != nil {
+		return fmt.Errorf("error releasing lock: %v", err)
+	}
+	return nil
+}
+
+// GetBoardPresence retrieves a board's live sessions, those seen within
+// staleAfter, and the unexpired locks they hold
+func (r *PresenceRepository) GetBoardPresence(ctx context.Context, boardID uuid.UUID, staleAfter time.Duration) (*models.BoardPresence, error) {
+	presence := &models.BoardPresence{
+		BoardID:  boardID,
+		Sessions: make([]models.BoardSession, 0),
+		Locks:    make([]models.TaskEditLock, 0),
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id, s.user_id, u.full_name, s.task_id, s.connected_at
+		FROM board_sessions s
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.board_id = $1 AND s.last_seen_at >= CURRENT_TIMESTAMP - make_interval(secs => $2)
+		ORDER BY s.connected_at, s.id
+	`, boardID, staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session := models.BoardSession{BoardID: boardID}
+		if err := rows.Scan(&session.ID, &session.UserID, &session.FullName, &session.TaskID, &session.ConnectedAt); err != nil {
+			return nil, fmt.Errorf("error scanning session: %v", err)
+		}
+		presence.Sessions = append(presence.Sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %v", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT
+			l.task_id,
+			l.field,
+			l.session_id,
+			l.user_id,
+			l.acquired_at,
+			l.expires_at,
+			u.full_name
+		FROM task_edit_locks l
+		JOIN board_sessions s ON s.id = l.session_id
+		LEFT JOIN users u ON u.id = l.user_id
+		WHERE l.board_id = $1
+		AND l.expires_at > CURRENT_TIMESTAMP
+		AND s.last_seen_at >= CURRENT_TIMESTAMP - make_interval(secs => $2)
+		ORDER BY l.acquired_at, l.task_id, l.field
+	`, boardID, staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error listing locks: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lock models.TaskEditLock
+		err := rows.Scan(
+			&lock.TaskID,
+			&lock.Field,
+			&lock.SessionID,
+			&lock.UserID,
+			&lock.AcquiredAt,
+			&lock.ExpiresAt,
+			&lock.FullName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning lock: %v", err)
+		}
+		presence.Locks = append(presence.Locks, lock)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating locks: %v", err)
+	}
+
+	return presence, nil
+}
+
+// scanTaskEditLock scans a lock selected with taskEditLockColumns
+func scanTaskEditLock(row pgx.Row) (*models.TaskEditLock, error) {
+	var lock models.TaskEditLock
+	err := row.Scan(
+		&lock.TaskID,
+		&lock.Field,
+		&lock.SessionID,
+		&lock.UserID,
+		&lock.AcquiredAt,
+		&lock.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error scanning lock: %v", err)
+	}
+	return &lock, nil
+}
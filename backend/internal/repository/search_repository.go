@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// Highlights are marked with private-use characters so the text around them
+// can be HTML-escaped before they're swapped for <mark> tags
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// Options for ts_headline. Titles are short, so they're highlighted whole.
+var (
+	titleHeadlineOptions   = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", HighlightAll=true`
+	snippetHeadlineOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", MaxFragments=2, MaxWords=24, MinWords=8, FragmentDelimiter=" … "`
+)
+
+// SearchRepository handles full-text search
+type SearchRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *pgxpool.Pool) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// SearchTasks ranks the tasks the user can access against a web-search
+// style query: words, "quoted phrases", OR and -excluded words. boardID
+// limits the search to one board.
+func (r *SearchRepository) SearchTasks(ctx context.Context, userID uuid.UUID, query string, boardID *uuid.UUID, cursor string, limit int) (*models.TaskSearchPage, error) {
+	args := []interface{}{query, userID}
+	conditions := " AND " + taskAccessCondition(2)
+
+	if boardID != nil {
+		args = append(args, *boardID)
+		conditions += fmt.Sprintf(" AND t.board_id = $%d", len(args))
+	}
+
+	if cursor != "" {
+		rank, cursorID, err := decodeSearchCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, rank, cursorID)
+		conditions += fmt.Sprintf(` AND (
+			ts_rank(t.search_vector, q.query, 1) < $%d OR
+			(ts_rank(t.search_vector, q.query, 1) = $%d AND t.id > $%d)
+		)`, len(args)-1, len(args)-1, len(args))
+	}
+
+	args = append(args, titleHeadlineOptions, snippetHeadlineOptions)
+	titleOptions, snippetOptions := len(args)-1, len(args)
+
+	// Rank and page first so snippets are only built for the page. One
+	// extra row is fetched to know whether there's another page.
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		WITH q AS (
+			SELECT websearch_to_tsquery('english', $1) AS query
+		),
+		ranked AS (
+			SELECT t.id, ts_rank(t.search_vector, q.query, 1) AS rank
+			FROM tasks t
+			CROSS JOIN q
+			WHERE t.search_vector @@ q.query`+conditions+`
+			ORDER BY rank DESC, t.id
+			LIMIT %d
+		)
+		SELECT
+			t.id,
+			t.board_id,
+			t.title,
+			t.status_id,
+			t.updated_at,
+			r.rank,
+			ts_headline('english', t.title, q.query, $%d),
+			ts_headline('english', concat_ws(E'\n', t.description, tc.notes, tc.implementation_details, ac.text, cm.text), q.query, $%d),
+			to_tsvector('english', t.title) @@ q.query,
+			to_tsvector('english', COALESCE(t.description, '')) @@ q.query,
+			to_tsvector('english', COALESCE(tc.notes, '')) @@ q.query,
+			to_tsvector('english', COALESCE(tc.implementation_details, '')) @@ q.query,
+			to_tsvector('english', COALESCE(ac.text, '')) @@ q.query,
+			to_tsvector('english', COALESCE(cm.text, '')) @@ q.query
+		FROM ranked r
+		JOIN tasks t ON t.id = r.id
+		CROSS JOIN q
+		LEFT JOIN task_contents tc ON tc.task_id = t.id
+		LEFT JOIN LATERAL (
+			SELECT string_agg(description, E'\n' ORDER BY order_index) AS text
+			FROM acceptance_criteria
+			WHERE task_id = t.id
+		) ac ON true
+		LEFT JOIN LATERAL (
+			SELECT string_agg(body, E'\n' ORDER BY created_at) AS text
+			FROM task_comments
+			WHERE task_id = t.id
+		) cm ON true
+		ORDER BY r.rank DESC, t.id
+	`, limit+1, titleOptions, snippetOptions), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching tasks: %v", err)
+	}
+	defer rows.Close()
+
+	page := &models.TaskSearchPage{Items: make([]models.TaskSearchResult, 0)}
+	for rows.Next() {
+		var result models.TaskSearchResult
+		var inTitle, inDescription, inNotes, inImplementation, inCriteria, inComments bool
+		err := rows.Scan(
+			&result.TaskID,
+			&result.BoardID,
+			&result.Title,
+			&result.StatusID,
+			&result.UpdatedAt,
+			&result.Rank,
+			&result.TitleHighlight,
+			&result.Snippet,
+			&inTitle,
+			&inDescription,
+			&inNotes,
+			&inImplementation,
+			&inCriteria,
+			&inComments,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning search result: %v", err)
+		}
+
+		result.TitleHighlight = markHighlights(result.TitleHighlight)
+		result.Snippet = markHighlights(result.Snippet)
+
+		result.Matches = make([]string, 0)
+		for _, match := range []struct {
+			matched bool
+			field   string
+		}{
+			{inTitle, models.SearchFieldTitle},
+			{inDescription, models.SearchFieldDescription},
+			{inNotes, models.SearchFieldNotes},
+			{inImplementation, models.SearchFieldImplementationDetails},
+			{inCriteria, models.SearchFieldAcceptanceCriteria},
+			{inComments, models.SearchFieldComments},
+		} {
+			if match.matched {
+				result.Matches = append(result.Matches, match.field)
+			}
+		}
+
+		page.Items = append(page.Items, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %v", err)
+	}
+
+	if len(page.Items) > limit {
+		last := page.Items[limit-1]
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeSearchCursor(last.Rank, last.TaskID)
+	}
+
+	return page, nil
+}
+
+// markHighlights HTML-escapes a ts_headline result and turns its highlight
+// markers into <mark> tags
+func markHighlights(headline string) string {
+	headline = html.EscapeString(headline)
+	headline = strings.ReplaceAll(headline, highlightStart, "<mark>")
+	return strings.ReplaceAll(headline, highlightStop, "</mark>")
+}
+
+// encodeSearchCursor encodes the position after a search result as an
+// opaque cursor. The rank is written exactly so the next page starts at the
+// right result.
+func encodeSearchCursor(rank float32, id uuid.UUID) string {
+	raw := strconv.FormatFloat(float64(rank), 'g', -1, 32) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSearchCursor reverses encodeSearchCursor
+func decodeSearchCursor(cursor string) (float32, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, uuid.Nil, ErrInvalidCursor
+	}
+
+	rankStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return 0, uuid.Nil, ErrInvalidCursor
+	}
+
+	rank, err := strconv.ParseFloat(rankStr, 32)
+	if err != nil {
+		return 0, uuid.Nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return 0, uuid.Nil, ErrInvalidCursor
+	}
+
+	return float32(rank), id, nil
+}
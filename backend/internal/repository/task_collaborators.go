@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	taskmodel "github.com/rafaelzasas/vtasker/backend/internal/models/task"
+)
+
+// ErrCollaboratorExists is returned when a user is already a collaborator on a task
+var ErrCollaboratorExists = errors.New("user is already a collaborator on this task")
+
+// ListCollaborators retrieves the collaborators on a task
+func (r *TaskRepository) ListCollaborators(ctx context.Context, taskID uuid.UUID) ([]taskmodel.Collaborator, error) {
+	collaborators, err := collaboratorsForTasks(ctx, r.db, []uuid.UUID{taskID})
+	if err != nil {
+		return nil, err
+	}
+
+	if collaborators[taskID] == nil {
+		return make([]taskmodel.Collaborator, 0), nil
+	}
+	return collaborators[taskID], nil
+}
+
+// AddCollaborator adds a user to a task with the given role
+func (r *TaskRepository) AddCollaborator(ctx context.Context, taskID uuid.UUID, input *models.AddCollaboratorInput) (*taskmodel.Collaborator, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_collaborators (task_id, user_id, role)
+		VALUES ($1, $2, $3)
+	`, taskID, input.UserID, input.Role)
+	if err != nil {
+		if strings.Contains(err.Error(), "SQLSTATE 23505") {
+			return nil, ErrCollaboratorExists
+		}
+		if strings.Contains(err.Error(), "SQLSTATE 23503") {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error adding collaborator: %v", err)
+	}
+
+	if err := recordTaskActivity(ctx, tx, taskID, models.ActivityCollaboratorAdded, nil, map[string]any{
+		"user_id": input.UserID,
+		"role":    input.Role,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return r.getCollaborator(ctx, taskID, input.UserID)
+}
+
+// UpdateCollaborator changes a collaborator's role on a task
+func (r *TaskRepository) UpdateCollaborator(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, role taskmodel.CollaboratorRole) (*taskmodel.Collaborator, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previousRole taskmodel.CollaboratorRole
+	err = tx.QueryRow(ctx, `
+		SELECT role
+		FROM task_collaborators
+		WHERE task_id = $1 AND user_id = $2
+		FOR UPDATE
+	`, taskID, userID).Scan(&previousRole)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error getting collaborator: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE task_collaborators
+		SET role = $1
+		WHERE task_id = $2 AND user_id = $3
+	`, role, taskID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating collaborator: %v", err)
+	}
+
+	var changes fieldChanges
+	changes.add("role", previousRole, role)
+	if len(changes) > 0 {
+		if err := recordTaskActivity(ctx, tx, taskID, models.ActivityCollaboratorUpdated, changes, map[string]any{"user_id": userID}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return r.getCollaborator(ctx, taskID, userID)
+}
+
+// RemoveCollaborator removes a user from a task
+func (r *TaskRepository) RemoveCollaborator(ctx context.Context, taskID uuid.UUID, userID uuid.UUID) error {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var role taskmodel.CollaboratorRole
+	err = tx.QueryRow(ctx, `
+		DELETE FROM task_collaborators
+		WHERE task_id = $1 AND user_id = $2
+		RETURNING role
+	`, taskID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("error removing collaborator: %v", err)
+	}
+
+	if err := recordTaskActivity(ctx, tx, taskID, models.ActivityCollaboratorRemoved, nil, map[string]any{
+		"user_id": userID,
+		"role":    role,
+	}); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// GetBoardRole returns the role a user has on a board, or an empty role when
+// there is no board or the user isn't a member. The role is worked out by
+// Board.RoleFor, the same as in the handlers.
+func (r *TaskRepository) GetBoardRole(ctx context.Context, boardID *uuid.UUID, userID uuid.UUID) (models.BoardRole, error) {
+	if boardID == nil {
+		return "", nil
+	}
+
+	board := models.Board{ID: *boardID}
+	var memberRole *models.BoardRole
+	err := r.db.QueryRow(ctx, `
+		SELECT b.owner_id, bm.role
+		FROM boards b
+		LEFT JOIN board_members bm ON bm.board_id = b.id AND bm.user_id = $2
+		WHERE b.id = $1
+	`, boardID, userID).Scan(&board.OwnerID, &memberRole)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error getting board role: %v", err)
+	}
+
+	if memberRole != nil {
+		board.Members = []models.BoardMember{{BoardID: board.ID, UserID: userID, Role: *memberRole}}
+	}
+
+	return board.RoleFor(userID), nil
+}
+
+// getCollaborator loads a single collaborator with their user details
+func (r *TaskRepository) getCollaborator(ctx context.Context, taskID uuid.UUID, userID uuid.UUID) (*taskmodel.Collaborator, error) {
+	collaborators, err := r.ListCollaborators(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range collaborators {
+		if collaborators[i].UserID == userID {
+			return &collaborators[i], nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// collaboratorsForTasks loads the collaborators on each of the given tasks
+func collaboratorsForTasks(ctx context.Context, db *pgxpool.Pool, taskIDs []uuid.UUID) (map[uuid.UUID][]taskmodel.Collaborator, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			tc.task_id,
+			tc.user_id,
+			tc.role,
+			tc.created_at,
+			u.full_name,
+			u.email,
+			COALESCE(u.avatar_url, '')
+		FROM task_collaborators tc
+		JOIN users u ON u.id = tc.user_id
+		WHERE tc.task_id = ANY($1)
+		ORDER BY tc.created_at, u.full_name
+	`, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying task collaborators: %v", err)
+	}
+	defer rows.Close()
+
+	collaborators := make(map[uuid.UUID][]taskmodel.Collaborator)
+	for rows.Next() {
+		var collab taskmodel.Collaborator
+		var user models.User
+		err := rows.Scan(
+			&collab.TaskID,
+			&collab.UserID,
+			&collab.Role,
+			&collab.CreatedAt,
+			&user.FullName,
+			&user.Email,
+			&user.AvatarURL,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning task collaborator: %v", err)
+		}
+		user.ID = collab.UserID
+		collab.User = &user
+		collaborators[collab.TaskID] = append(collaborators[collab.TaskID], collab)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task collaborators: %v", err)
+	}
+
+	return collaborators, nil
+}
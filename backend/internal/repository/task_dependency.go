@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// ErrDependencyCycle is returned when a dependency edge would make a task
+// (transitively) block itself
+var ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+// AddDependency records that taskID is blocked by dependencyID. Adding an
+// edge that already exists returns the existing edge.
+func (r *TaskRepository) AddDependency(ctx context.Context, taskID uuid.UUID, dependencyID uuid.UUID) (*models.TaskDependency, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize graph changes so two concurrent inserts can't close a cycle
+	// that neither of them sees on its own
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('task_dependencies'))`); err != nil {
+		return nil, fmt.Errorf("error locking dependency graph: %v", err)
+	}
+
+	if err := checkDependencyCycle(ctx, tx, taskID, dependencyID); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO task_dependencies (task_id, dependency_id)
+		VALUES ($1, $2)
+		ON CONFLICT (task_id, dependency_id) DO NOTHING
+	`, taskID, dependencyID)
+	if err != nil {
+		return nil, fmt.Errorf("error adding dependency: %v", err)
+	}
+
+	// The activity goes on the blocked task's feed
+	if result.RowsAffected() > 0 {
+		if err := recordTaskActivity(ctx, tx, taskID, models.ActivityDependencyAdded, nil, map[string]any{"dependency_id": dependencyID}); err != nil {
+			return nil, err
+		}
+	}
+
+	dep, err := getDependency(ctx, tx, taskID, dependencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return dep, nil
+}
+
+// checkDependencyCycle returns ErrDependencyCycle when making taskID blocked
+// by dependencyID would close a cycle, which is the case if the blocking task
+// already (transitively) depends on the blocked one
+func checkDependencyCycle(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, dependencyID uuid.UUID) error {
+	if taskID == dependencyID {
+		return ErrDependencyCycle
+	}
+
+	var createsCycle bool
+	err := tx.QueryRow(ctx, `
+		WITH RECURSIVE reachable(id) AS (
+			SELECT dependency_id FROM task_dependencies WHERE task_id = $1
+			UNION
+			SELECT td.dependency_id
+			FROM task_dependencies td
+			JOIN reachable r ON td.task_id = r.id
+		)
+		SELECT EXISTS (SELECT 1 FROM reachable WHERE id = $2)
+	`, dependencyID, taskID).Scan(&createsCycle)
+	if err != nil {
+		return fmt.Errorf("error checking dependency cycle: %v", err)
+	}
+	if createsCycle {
+		return ErrDependencyCycle
+	}
+	return nil
+}
+
+// GetDependencyBetween returns the edge between two tasks, whichever
+// direction it points
+func (r *TaskRepository) GetDependencyBetween(ctx context.Context, taskID uuid.UUID, otherID uuid.UUID) (*models.TaskDependency, error) {
+	var dep models.TaskDependency
+	err := r.db.QueryRow(ctx, `
+		SELECT id, task_id, dependency_id, created_at
+		FROM task_dependencies
+		WHERE (task_id = $1 AND dependency_id = $2)
+		   OR (task_id = $2 AND dependency_id = $1)
+	`, taskID, otherID).Scan(&dep.ID, &dep.TaskID, &dep.DependencyID, &dep.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error loading dependency: %v", err)
+	}
+	return &dep, nil
+}
+
+// RemoveDependency removes the edge recording that taskID is blocked by
+// dependencyID
+func (r *TaskRepository) RemoveDependency(ctx context.Context, taskID uuid.UUID, dependencyID uuid.UUID) error {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		DELETE FROM task_dependencies
+		WHERE task_id = $1 AND dependency_id = $2
+	`, taskID, dependencyID)
+	if err != nil {
+		return fmt.Errorf("error removing dependency: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := recordTaskActivity(ctx, tx, taskID, models.ActivityDependencyRemoved, nil, map[string]any{"dependency_id": dependencyID}); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// GetDependencyGraph walks the dependency graph up to depth edges away from a
+// task in both directions. Tasks on boards the user can't access are left out.
+func (r *TaskRepository) GetDependencyGraph(ctx context.Context, taskID uuid.UUID, depth int, userID uuid.UUID) (*models.DependencyGraph, error) {
+	query := `
+		WITH RECURSIVE upstream(id, depth) AS (
+			SELECT $1::uuid, 0
+			UNION
+			SELECT td.dependency_id, u.depth + 1
+			FROM task_dependencies td
+			JOIN upstream u ON td.task_id = u.id
+			WHERE u.depth < $2
+		), downstream(id, depth) AS (
+			SELECT $1::uuid, 0
+			UNION
+			SELECT td.task_id, d.depth + 1
+			FROM task_dependencies td
+			JOIN downstream d ON td.dependency_id = d.id
+			WHERE d.depth < $2
+		), nodes AS (
+			SELECT id, MIN(depth) AS depth, '` + models.DependencyDirectionUpstream + `' AS direction
+			FROM upstream WHERE depth > 0 GROUP BY id
+			UNION ALL
+			SELECT id, MIN(depth) AS depth, '` + models.DependencyDirectionDownstream + `' AS direction
+			FROM downstream WHERE depth > 0 GROUP BY id
+		)
+		SELECT t.id, t.title, t.status_id, t.board_id, n.direction, n.depth
+		FROM nodes n
+		JOIN tasks t ON t.id = n.id
+		WHERE ` + taskAccessCondition(3) + `
+		ORDER BY n.direction, n.depth, t.title`
+
+	rows, err := r.db.Query(ctx, query, taskID, depth, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying dependency graph: %v", err)
+	}
+	defer rows.Close()
+
+	graph := &models.DependencyGraph{
+		TaskID: taskID,
+		Depth:  depth,
+		Nodes:  make([]models.DependencyNode, 0),
+		Edges:  make([]models.TaskDependency, 0),
+	}
+	ids := []uuid.UUID{taskID}
+	for rows.Next() {
+		var node models.DependencyNode
+		err := rows.Scan(
+			&node.ID,
+			&node.Title,
+			&node.StatusID,
+			&node.BoardID,
+			&node.Direction,
+			&node.Depth,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning dependency node: %v", err)
+		}
+		graph.Nodes = append(graph.Nodes, node)
+		ids = append(ids, node.ID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dependency nodes: %v", err)
+	}
+
+	// Only return edges between tasks that made it into the graph
+	edgeRows, err := r.db.Query(ctx, `
+		SELECT id, task_id, dependency_id, created_at
+		FROM task_dependencies
+		WHERE task_id = ANY($1) AND dependency_id = ANY($1)
+		ORDER BY created_at
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error querying dependency edges: %v", err)
+	}
+	defer edgeRows.Close()
+
+	for edgeRows.Next() {
+		var dep models.TaskDependency
+		if err := edgeRows.Scan(&dep.ID, &dep.TaskID, &dep.DependencyID, &dep.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning dependency edge: %v", err)
+		}
+		graph.Edges = append(graph.Edges, dep)
+	}
+
+	if err = edgeRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dependency edges: %v", err)
+	}
+
+	return graph, nil
+}
+
+// getDependency loads a single edge, mapping a missing row to ErrNotFound
+func getDependency(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, dependencyID uuid.UUID) (*models.TaskDependency, error) {
+	var dep models.TaskDependency
+	err := tx.QueryRow(ctx, `
+		SELECT id, task_id, dependency_id, created_at
+		FROM task_dependencies
+		WHERE task_id = $1 AND dependency_id = $2
+	`, taskID, dependencyID).Scan(&dep.ID, &dep.TaskID, &dep.DependencyID, &dep.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error loading dependency: %v", err)
+	}
+	return &dep, nil
+}
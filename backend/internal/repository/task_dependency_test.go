@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestCheckDependencyCycle(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	// blockedBy maps each task to the tasks blocking it
+	graph := func(blockedBy map[uuid.UUID][]uuid.UUID) func(sql string, args ...any) pgx.Row {
+		return func(sql string, args ...any) pgx.Row {
+			from, target := args[0].(uuid.UUID), args[1].(uuid.UUID)
+			seen := map[uuid.UUID]bool{}
+			queue := append([]uuid.UUID(nil), blockedBy[from]...)
+			for len(queue) > 0 {
+				id := queue[0]
+				queue = queue[1:]
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				queue = append(queue, blockedBy[id]...)
+			}
+			return fakeRow{values: []any{seen[target]}}
+		}
+	}
+	chain := graph(map[uuid.UUID][]uuid.UUID{a: {b}, b: {c}})
+
+	tests := []struct {
+		name       string
+		queryRow   func(sql string, args ...any) pgx.Row
+		task       uuid.UUID
+		dependency uuid.UUID
+		wantErr    error
+	}{
+		{"task blocking itself", rowsInOrder(), a, a, ErrDependencyCycle},
+		{"reversing an edge", chain, b, a, ErrDependencyCycle},
+		{"closing a longer cycle", chain, c, a, ErrDependencyCycle},
+		{"extending the chain", chain, c, d, nil},
+		{"adding a shortcut", chain, a, c, nil},
+		{"edge between unrelated tasks", chain, d, a, nil},
+		{"existing edge", chain, a, b, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDependencyCycle(context.Background(), &fakeTx{queryRow: tt.queryRow}, tt.task, tt.dependency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkDependencyCycle() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("query error", func(t *testing.T) {
+		tx := &fakeTx{queryRow: rowsInOrder(fakeRow{err: errors.New("connection reset")})}
+		err := checkDependencyCycle(context.Background(), tx, a, b)
+		if err == nil || errors.Is(err, ErrDependencyCycle) {
+			t.Errorf("checkDependencyCycle() = %v, want a query error", err)
+		}
+	})
+}
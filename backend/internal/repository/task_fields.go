@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/taskquery"
+)
+
+// TaskFieldNames lists the task fields a sparse task response can pick
+var TaskFieldNames = []string{
+	"id",
+	"title",
+	"description",
+	"status_id",
+	"priority_id",
+	"type_id",
+	"owner_id",
+	"parent_id",
+	"board_id",
+	"order_index",
+	"content",
+	"labels",
+	"collaborators",
+	"progress",
+	"mentions",
+	"attachments",
+	"created_at",
+	"updated_at",
+}
+
+// Relations that can be embedded in tasks
+const (
+	IncludeOwner    = "owner"
+	IncludeAssignee = "assignee"
+	IncludeLabels   = "labels"
+	IncludeCriteria = "criteria"
+	IncludeSubtasks = "subtasks"
+	IncludeStatus   = "status"
+)
+
+// TaskIncludes lists the relations that can be embedded in tasks
+var TaskIncludes = []string{
+	IncludeOwner,
+	IncludeAssignee,
+	IncludeLabels,
+	IncludeCriteria,
+	IncludeSubtasks,
+	IncludeStatus,
+}
+
+// TaskFields picks the parts of tasks to load and return. The zero value is
+// every field, with the content's acceptance criteria, and no embedded
+// relations.
+type TaskFields struct {
+	// Only are the fields to return, or nil for every field
+	Only map[string]bool
+	// Include are the relations to embed
+	Include map[string]bool
+}
+
+// ParseTaskFields parses comma-separated lists of fields and relations to
+// include. An empty field list is every field.
+func ParseTaskFields(fields string, include string) (TaskFields, error) {
+	var parsed TaskFields
+
+	if fields != "" {
+		parsed.Only = map[string]bool{"id": true}
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if !containsString(TaskFieldNames, field) {
+				return TaskFields{}, fmt.Errorf("unknown field %q", field)
+			}
+			parsed.Only[field] = true
+		}
+	}
+
+	if include != "" {
+		parsed.Include = make(map[string]bool)
+		for _, relation := range strings.Split(include, ",") {
+			relation = strings.TrimSpace(relation)
+			if !containsString(TaskIncludes, relation) {
+				return TaskFields{}, fmt.Errorf("unknown include %q", relation)
+			}
+			parsed.Include[relation] = true
+		}
+	}
+
+	return parsed, nil
+}
+
+// IsSparse reports whether only some fields are returned
+func (f TaskFields) IsSparse() bool {
+	return f.Only != nil
+}
+
+// Returns reports whether a key of a task's JSON is returned
+func (f TaskFields) Returns(key string) bool {
+	switch key {
+	case IncludeOwner, IncludeAssignee, IncludeStatus, IncludeSubtasks:
+		return f.Include[key]
+	case "labels":
+		return f.loads(key) || f.Include[IncludeLabels]
+	case "content":
+		return f.loads(key) || f.Include[IncludeCriteria]
+	}
+	return f.loads(key)
+}
+
+// ReturnsCriteria reports whether the content's acceptance criteria are
+// returned. Full responses always have them; sparse ones only on request.
+func (f TaskFields) ReturnsCriteria() bool {
+	return f.Only == nil || f.Include[IncludeCriteria]
+}
+
+// loads reports whether a task field is loaded
+func (f TaskFields) loads(field string) bool {
+	return f.Only == nil || f.Only[field]
+}
+
+// subtasks returns the fields embedded subtasks are loaded with: the same
+// fields, without relations of their own
+func (f TaskFields) subtasks() TaskFields {
+	return TaskFields{Only: f.Only}
+}
+
+// needsContent reports whether tasks have to be joined with their content:
+// for the content itself, an embedded assignee, or a sort or query term on
+// content columns
+func (filters TaskFilters) needsContent() bool {
+	fields := filters.Fields
+	if fields.Returns("content") || fields.Include[IncludeAssignee] {
+		return true
+	}
+	if strings.TrimPrefix(filters.Sort, "-") == "due_date" {
+		return true
+	}
+	if filters.Query != nil {
+		for _, term := range filters.Query.Terms {
+			if term.Field == taskquery.FieldAssignee || term.Field == taskquery.FieldDue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetTaskFields returns a single task with only the parts fields picks
+// loaded, the same way ListTasks loads them. It returns ErrNotFound when the
+// task doesn't exist or the user can't see its board.
+func (r *TaskRepository) GetTaskFields(ctx context.Context, taskID uuid.UUID, fields TaskFields, userID uuid.UUID) (*models.Task, error) {
+	page, err := r.ListTasks(ctx, TaskFilters{Fields: fields, taskID: &taskID}, userID, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, ErrNotFound
+	}
+	return page.Items[0], nil
+}
+
+// EmbedRelations loads the relations fields includes into tasks: their
+// owner, assignee, status and subtasks. Labels and criteria are loaded with
+// the tasks. Subtasks are limited to those the user can see, in board order.
+func (r *TaskRepository) EmbedRelations(ctx context.Context, tasks []*models.Task, fields TaskFields, userID uuid.UUID) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	if fields.Include[IncludeOwner] || fields.Include[IncludeAssignee] {
+		var userIDs []uuid.UUID
+		for _, task := range tasks {
+			if fields.Include[IncludeOwner] && task.OwnerID != nil {
+				userIDs = append(userIDs, *task.OwnerID)
+			}
+			if fields.Include[IncludeAssignee] && task.Content.Assignee != nil {
+				userIDs = append(userIDs, *task.Content.Assignee)
+			}
+		}
+
+		users, err := usersByID(ctx, r.db, userIDs)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if fields.Include[IncludeOwner] && task.OwnerID != nil {
+				task.Owner = users[*task.OwnerID]
+			}
+			if fields.Include[IncludeAssignee] && task.Content.Assignee != nil {
+				task.Assignee = users[*task.Content.Assignee]
+			}
+		}
+	}
+
+	if fields.Include[IncludeStatus] {
+		statusIDs := make([]int32, len(tasks))
+		for i, task := range tasks {
+			statusIDs[i] = task.StatusID
+		}
+
+		statuses, err := statusesByID(ctx, r.db, statusIDs)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			task.Status = statuses[task.StatusID]
+		}
+	}
+
+	if fields.Include[IncludeSubtasks] {
+		parents := make(map[uuid.UUID]*models.Task, len(tasks))
+		parentIDs := make([]uuid.UUID, len(tasks))
+		for i, task := range tasks {
+			parents[task.ID] = task
+			parentIDs[i] = task.ID
+			task.Subtasks = make([]*models.Task, 0)
+		}
+
+		page, err := r.ListTasks(ctx, TaskFilters{Fields: fields.subtasks(), parentIDs: parentIDs}, userID, "", 0)
+		if err != nil {
+			return err
+		}
+		for _, subtask := range page.Items {
+			if parent, ok := parents[*subtask.ParentID]; ok {
+				parent.Subtasks = append(parent.Subtasks, subtask)
+			}
+		}
+	}
+
+	return nil
+}
+
+// usersByID loads the public details of the given users
+func usersByID(ctx context.Context, db *pgxpool.Pool, userIDs []uuid.UUID) (map[uuid.UUID]*models.UserResponse, error) {
+	users := make(map[uuid.UUID]*models.UserResponse)
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT id, email, full_name, created_at
+		FROM users
+		WHERE id = ANY($1)
+	`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying users: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.UserResponse
+		if err := rows.Scan(&user.ID, &user.Email, &user.FullName, &user.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning user: %v", err)
+		}
+		users[user.ID] = &user
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %v", err)
+	}
+
+	return users, nil
+}
+
+// statusesByID loads the given task statuses
+func statusesByID(ctx context.Context, db *pgxpool.Pool, statusIDs []int32) (map[int32]*models.TaskStatus, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+taskStatusColumns+`
+		FROM task_statuses
+		WHERE id = ANY($1)
+	`, statusIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying task statuses: %v", err)
+	}
+	defer rows.Close()
+
+	statuses := make(map[int32]*models.TaskStatus)
+	for rows.Next() {
+		status, err := scanTaskStatus(rows)
+		if err != nil {
+			return nil, err
+		}
+		statuses[status.ID] = status
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task statuses: %v", err)
+	}
+
+	return statuses, nil
+}
+
+// containsString reports whether a list has a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidPosition is returned when before_id or after_id don't name other
+// tasks in the target column, or name them the wrong way round
+var ErrInvalidPosition = errors.New("before_id and after_id must be other tasks in the target column, in order")
+
+const (
+	// taskRankGap is the space left between neighbouring tasks in a column
+	taskRankGap = 1024.0
+
+	// minTaskRankGap is how close two ranks can get before the column is rebalanced
+	minTaskRankGap = 1e-6
+)
+
+// taskPlacement says where a task goes in its column. Without a position,
+// BeforeID or AfterID the task goes to the end of the column.
+type taskPlacement struct {
+	Position *int
+	BeforeID *uuid.UUID
+	AfterID  *uuid.UUID
+}
+
+// lockTaskColumn serializes rank changes within a column so two concurrent
+// moves can't pick the same gap
+func lockTaskColumn(ctx context.Context, tx pgx.Tx, boardID *uuid.UUID, statusID int32) error {
+	_, err := tx.Exec(ctx, `
+		SELECT pg_advisory_xact_lock(hashtext('task_column:' || COALESCE($1::uuid::text, '') || ':' || $2::int::text))
+	`, boardID, statusID)
+	if err != nil {
+		return fmt.Errorf("error locking task column: %v", err)
+	}
+	return nil
+}
+
+// rankTask works out the rank for taskID when it's placed in the column of
+// boardID and statusID. The column is rebalanced first if the gap the task
+// goes into has become too narrow.
+func rankTask(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, boardID *uuid.UUID, statusID int32, placement taskPlacement) (float64, error) {
+	lower, upper, err := rankBounds(ctx, tx, taskID, boardID, statusID, placement)
+	if err != nil {
+		return 0, err
+	}
+
+	if gapTooNarrow(lower, upper) {
+		if err := rebalanceTaskColumn(ctx, tx, taskID, boardID, statusID); err != nil {
+			return 0, err
+		}
+		lower, upper, err = rankBounds(ctx, tx, taskID, boardID, statusID, placement)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return rankBetween(lower, upper), nil
+}
+
+// gapTooNarrow reports whether the gap between two neighbouring ranks has
+// been used up, so the column has to be rebalanced before a task goes in it
+func gapTooNarrow(lower, upper *float64) bool {
+	return lower != nil && upper != nil && *upper-*lower < minTaskRankGap
+}
+
+// rankBetween returns the rank for a task placed between lower and upper. A
+// task on its own gets taskRankGap, one at either end of the column goes
+// taskRankGap past its neighbour and one between two tasks goes halfway.
+func rankBetween(lower, upper *float64) float64 {
+	switch {
+	case lower == nil && upper == nil:
+		return taskRankGap
+	case lower == nil:
+		return *upper - taskRankGap
+	case upper == nil:
+		return *lower + taskRankGap
+	default:
+		return *lower + (*upper-*lower)/2
+	}
+}
+
+// rankBounds returns the ranks of the tasks the placed task ends up between.
+// A nil bound means the task is first or last in the column.
+func rankBounds(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, boardID *uuid.UUID, statusID int32, placement taskPlacement) (*float64, *float64, error) {
+	if placement.BeforeID != nil || placement.AfterID != nil {
+		var lower, upper *float64
+		if placement.AfterID != nil {
+			rank, err := columnTaskRank(ctx, tx, *placement.AfterID, taskID, boardID, statusID)
+			if err != nil {
+				return nil, nil, err
+			}
+			lower = &rank
+		}
+		if placement.BeforeID != nil {
+			rank, err := columnTaskRank(ctx, tx, *placement.BeforeID, taskID, boardID, statusID)
+			if err != nil {
+				return nil, nil, err
+			}
+			upper = &rank
+		}
+
+		switch {
+		case lower != nil && upper != nil:
+			if *lower >= *upper {
+				return nil, nil, ErrInvalidPosition
+			}
+		case lower != nil:
+			next, err := neighbourRank(ctx, tx, `order_index > $4 ORDER BY order_index`, taskID, boardID, statusID, *lower)
+			if err != nil {
+				return nil, nil, err
+			}
+			upper = next
+		default:
+			prev, err := neighbourRank(ctx, tx, `order_index < $4 ORDER BY order_index DESC`, taskID, boardID, statusID, *upper)
+			if err != nil {
+				return nil, nil, err
+			}
+			lower = prev
+		}
+		return lower, upper, nil
+	}
+
+	ranks, err := columnRanks(ctx, tx, taskID, boardID, statusID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lower, upper := positionBounds(ranks, placement.Position)
+	return lower, upper, nil
+}
+
+// positionBounds returns the ranks either side of a position in a column
+// with the given ranks. Positions past either end are clamped, and no
+// position means the end of the column.
+func positionBounds(ranks []float64, position *int) (*float64, *float64) {
+	index := len(ranks)
+	if position != nil && *position < index {
+		index = *position
+		if index < 0 {
+			index = 0
+		}
+	}
+
+	var lower, upper *float64
+	if index > 0 {
+		lower = &ranks[index-1]
+	}
+	if index < len(ranks) {
+		upper = &ranks[index]
+	}
+	return lower, upper
+}
+
+// columnTaskRank returns the rank of a neighbour task, which has to be in the
+// column and can't be the task being placed
+func columnTaskRank(ctx context.Context, tx pgx.Tx, id uuid.UUID, taskID uuid.UUID, boardID *uuid.UUID, statusID int32) (float64, error) {
+	var rank float64
+	err := tx.QueryRow(ctx, `
+		SELECT order_index
+		FROM tasks
+		WHERE id = $1
+			AND id <> $2
+			AND board_id IS NOT DISTINCT FROM $3
+			AND status_id = $4
+	`, id, taskID, boardID, statusID).Scan(&rank)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrInvalidPosition
+		}
+		return 0, fmt.Errorf("error getting task rank: %v", err)
+	}
+	return rank, nil
+}
+
+// neighbourRank returns the first rank in the column matching condition, or
+// nil when there isn't one. The condition can refer to rank as $4.
+func neighbourRank(ctx context.Context, tx pgx.Tx, condition string, taskID uuid.UUID, boardID *uuid.UUID, statusID int32, rank float64) (*float64, error) {
+	var neighbour float64
+	err := tx.QueryRow(ctx, `
+		SELECT order_index
+		FROM tasks
+		WHERE id <> $1
+			AND board_id IS NOT DISTINCT FROM $2
+			AND status_id = $3
+			AND `+condition+`
+		LIMIT 1
+	`, taskID, boardID, statusID, rank).Scan(&neighbour)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting neighbouring task rank: %v", err)
+	}
+	return &neighbour, nil
+}
+
+// columnRanks returns the ranks of the other tasks in a column in order
+func columnRanks(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, boardID *uuid.UUID, statusID int32) ([]float64, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT order_index
+		FROM tasks
+		WHERE id <> $1
+			AND board_id IS NOT DISTINCT FROM $2
+			AND status_id = $3
+		ORDER BY order_index, created_at, id
+	`, taskID, boardID, statusID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying column ranks: %v", err)
+	}
+	defer rows.Close()
+
+	var ranks []float64
+	for rows.Next() {
+		var rank float64
+		if err := rows.Scan(&rank); err != nil {
+			return nil, fmt.Errorf("error scanning column rank: %v", err)
+		}
+		ranks = append(ranks, rank)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating column ranks: %v", err)
+	}
+
+	return ranks, nil
+}
+
+// rebalanceTaskColumn spreads the other tasks in a column back out to
+// taskRankGap apart, keeping their order. It only runs when repeated moves
+// into the same spot have used up the gap there.
+func rebalanceTaskColumn(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, boardID *uuid.UUID, statusID int32) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE tasks t
+		SET order_index = ranked.position * $4::double precision
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY order_index, created_at, id) AS position
+			FROM tasks
+			WHERE id <> $1
+				AND board_id IS NOT DISTINCT FROM $2
+				AND status_id = $3
+		) ranked
+		WHERE t.id = ranked.id
+	`, taskID, boardID, statusID, taskRankGap)
+	if err != nil {
+		return fmt.Errorf("error rebalancing task column: %v", err)
+	}
+	return nil
+}
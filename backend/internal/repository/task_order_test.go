@@ -0,0 +1,74 @@
+package repository
+
+import "testing"
+
+func TestTaskRankOrdering(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	column := []float64{1024, 2048, 3072}
+
+	tests := []struct {
+		name      string
+		ranks     []float64
+		position  *int
+		want      float64
+		rebalance bool
+	}{
+		{name: "empty column", ranks: nil, want: taskRankGap},
+		{name: "empty column with position", ranks: nil, position: intPtr(3), want: taskRankGap},
+		{name: "no position goes last", ranks: column, want: 3072 + taskRankGap},
+		{name: "first", ranks: column, position: intPtr(0), want: 1024 - taskRankGap},
+		{name: "negative position goes first", ranks: column, position: intPtr(-2), want: 1024 - taskRankGap},
+		{name: "between first and second", ranks: column, position: intPtr(1), want: 1536},
+		{name: "between second and third", ranks: column, position: intPtr(2), want: 2560},
+		{name: "position past the end goes last", ranks: column, position: intPtr(10), want: 3072 + taskRankGap},
+		{name: "first rank can go negative", ranks: []float64{0}, position: intPtr(0), want: -taskRankGap},
+		{name: "narrow gap", ranks: []float64{1, 1 + minTaskRankGap/2}, position: intPtr(1), want: 1 + minTaskRankGap/4, rebalance: true},
+		{name: "equal ranks", ranks: []float64{5, 5}, position: intPtr(1), want: 5, rebalance: true},
+		{name: "gap just wide enough", ranks: []float64{0, 2 * minTaskRankGap}, position: intPtr(1), want: minTaskRankGap},
+		{name: "narrow gap at the end doesn't matter", ranks: []float64{1, 1 + minTaskRankGap/2}, want: 1 + minTaskRankGap/2 + taskRankGap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lower, upper := positionBounds(tt.ranks, tt.position)
+
+			if got := gapTooNarrow(lower, upper); got != tt.rebalance {
+				t.Errorf("gapTooNarrow() = %v, want %v", got, tt.rebalance)
+			}
+
+			got := rankBetween(lower, upper)
+			if got != tt.want {
+				t.Errorf("rankBetween() = %v, want %v", got, tt.want)
+			}
+			if lower != nil && !tt.rebalance && got <= *lower {
+				t.Errorf("rank %v isn't after the task before it (%v)", got, *lower)
+			}
+			if upper != nil && !tt.rebalance && got >= *upper {
+				t.Errorf("rank %v isn't before the task after it (%v)", got, *upper)
+			}
+		})
+	}
+}
+
+func TestTaskRankAfterRebalance(t *testing.T) {
+	// Moving a task into the same spot again and again halves the gap each
+	// time until it's too narrow, and a rebalanced column has room again
+	lower, upper := 1024.0, 2048.0
+	moves := 0
+	for !gapTooNarrow(&lower, &upper) {
+		upper = rankBetween(&lower, &upper)
+		moves++
+	}
+	if moves < 20 {
+		t.Errorf("gap ran out after %d moves, want at least 20", moves)
+	}
+
+	rebalanced := []float64{taskRankGap, 2 * taskRankGap, 3 * taskRankGap}
+	l, u := positionBounds(rebalanced, func() *int { i := 1; return &i }())
+	if gapTooNarrow(l, u) {
+		t.Fatal("rebalanced column still too narrow")
+	}
+	if got := rankBetween(l, u); got != 1.5*taskRankGap {
+		t.Errorf("rank after rebalance = %v, want %v", got, 1.5*taskRankGap)
+	}
+}
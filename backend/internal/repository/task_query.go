@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/taskquery"
+)
+
+// taskQueryConditions turns a parsed task query into SQL conditions on tasks
+// aliased as t, their content as tc and their status as ts. Every value from
+// the query is appended to args as a parameter; none is written into the SQL.
+func taskQueryConditions(query *taskquery.Query, userID uuid.UUID, args []interface{}) (string, []interface{}) {
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var sql strings.Builder
+	for _, term := range query.Terms {
+		condition := taskTermCondition(term, userID, param)
+		if term.Negated {
+			// A missing value doesn't match the term, so it matches its negation
+			condition = "NOT COALESCE(" + condition + ", false)"
+		}
+		sql.WriteString(" AND " + condition)
+	}
+
+	return sql.String(), args
+}
+
+// taskTermCondition returns the SQL condition for one query term
+func taskTermCondition(term taskquery.Term, userID uuid.UUID, param func(interface{}) string) string {
+	switch term.Field {
+	case taskquery.FieldText:
+		toQuery := "plainto_tsquery"
+		if term.Phrase {
+			toQuery = "phraseto_tsquery"
+		}
+		return fmt.Sprintf("(t.search_vector @@ %s('english', %s))", toQuery, param(term.Values[0]))
+
+	case taskquery.FieldStatus:
+		return "(ts.code = ANY(" + param(term.Values) + "))"
+
+	case taskquery.FieldCategory:
+		return "(ts.category = ANY(" + param(term.Values) + "))"
+
+	case taskquery.FieldPriority:
+		return `EXISTS (
+			SELECT 1 FROM task_priorities tp
+			WHERE tp.id = t.priority_id AND tp.code = ANY(` + param(term.Values) + `)
+		)`
+
+	case taskquery.FieldType:
+		return `EXISTS (
+			SELECT 1 FROM task_types tt
+			WHERE tt.id = t.type_id AND tt.code = ANY(` + param(term.Values) + `)
+		)`
+
+	case taskquery.FieldLabel:
+		var alternatives []string
+		names := withoutNone(term.Values)
+		if len(names) > 0 {
+			alternatives = append(alternatives, `EXISTS (
+				SELECT 1 FROM task_labels tl
+				JOIN board_labels bl ON bl.id = tl.label_id
+				WHERE tl.task_id = t.id AND LOWER(bl.name) = ANY(`+param(names)+`)
+			)`)
+		}
+		if len(names) < len(term.Values) {
+			alternatives = append(alternatives, "NOT EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = t.id)")
+		}
+		return "(" + strings.Join(alternatives, " OR ") + ")"
+
+	case taskquery.FieldAssignee:
+		return userTermCondition("tc.assignee", term.Values, userID, param)
+
+	case taskquery.FieldOwner:
+		return userTermCondition("t.owner_id", term.Values, userID, param)
+
+	case taskquery.FieldBoard:
+		return idTermCondition("t.board_id", term.Values, param)
+
+	case taskquery.FieldParent:
+		return idTermCondition("t.parent_id", term.Values, param)
+
+	case taskquery.FieldDue:
+		return dateTermCondition("tc.due_date", term, param)
+
+	case taskquery.FieldCreated:
+		return dateTermCondition("t.created_at", term, param)
+
+	case taskquery.FieldUpdated:
+		return dateTermCondition("t.updated_at", term, param)
+	}
+
+	return "false"
+}
+
+// userTermCondition matches a user column against me, none, handles and
+// email addresses
+func userTermCondition(column string, values []string, userID uuid.UUID, param func(interface{}) string) string {
+	var alternatives, others []string
+	for _, value := range values {
+		switch value {
+		case taskquery.Me:
+			alternatives = append(alternatives, column+" = "+param(userID))
+		case taskquery.None:
+			alternatives = append(alternatives, column+" IS NULL")
+		default:
+			others = append(others, value)
+		}
+	}
+	if len(others) > 0 {
+		p := param(others)
+		alternatives = append(alternatives, fmt.Sprintf(
+			"%s IN (SELECT u.id FROM users u WHERE u.handle = ANY(%s) OR LOWER(u.email) = ANY(%s))",
+			column, p, p,
+		))
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// idTermCondition matches a UUID column against IDs and none
+func idTermCondition(column string, values []string, param func(interface{}) string) string {
+	var alternatives []string
+	var ids []uuid.UUID
+	for _, value := range values {
+		if value == taskquery.None {
+			alternatives = append(alternatives, column+" IS NULL")
+			continue
+		}
+		if id, err := uuid.Parse(value); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		alternatives = append(alternatives, column+" = ANY("+param(ids)+")")
+	}
+	if len(alternatives) == 0 {
+		return "false"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// dateTermCondition compares a timestamp column with a date term's range
+func dateTermCondition(column string, term taskquery.Term, param func(interface{}) string) string {
+	if len(term.Values) == 1 && term.Values[0] == taskquery.None {
+		return "(" + column + " IS NULL)"
+	}
+
+	switch term.Op {
+	case taskquery.OpLess:
+		return "(" + column + " < " + param(term.From) + ")"
+	case taskquery.OpLessEqual:
+		return "(" + column + " < " + param(term.To) + ")"
+	case taskquery.OpGreater:
+		return "(" + column + " >= " + param(term.To) + ")"
+	case taskquery.OpGreaterEqual:
+		return "(" + column + " >= " + param(term.From) + ")"
+	default:
+		return "(" + column + " >= " + param(term.From) + " AND " + column + " < " + param(term.To) + ")"
+	}
+}
+
+// withoutNone returns values without taskquery.None
+func withoutNone(values []string) []string {
+	var filtered []string
+	for _, value := range values {
+		if value != taskquery.None {
+			filtered = append(filtered, value)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/taskquery"
+)
+
+// squash collapses whitespace so conditions can be compared on one line
+func squash(sql string) string {
+	return strings.TrimSpace(regexp.MustCompile(`\s+`).ReplaceAllString(sql, " "))
+}
+
+func TestTaskQueryConditions(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	boardID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	from := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name     string
+		query    string
+		terms    []taskquery.Term
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "empty",
+			query:    "",
+			wantSQL:  "",
+			wantArgs: []interface{}{"prior"},
+		},
+		{
+			name:     "status",
+			query:    "status:todo,done",
+			wantSQL:  "AND (ts.code = ANY($2))",
+			wantArgs: []interface{}{"prior", []string{"todo", "done"}},
+		},
+		{
+			name:     "terms are ANDed and negation wraps one term",
+			query:    "category:done -priority:low",
+			wantSQL:  "AND (ts.category = ANY($2)) AND NOT COALESCE(EXISTS ( SELECT 1 FROM task_priorities tp WHERE tp.id = t.priority_id AND tp.code = ANY($3) ), false)",
+			wantArgs: []interface{}{"prior", []string{"done"}, []string{"low"}},
+		},
+		{
+			name:     "text and phrase",
+			query:    `login "reset password"`,
+			wantSQL:  "AND (t.search_vector @@ plainto_tsquery('english', $2)) AND (t.search_vector @@ phraseto_tsquery('english', $3))",
+			wantArgs: []interface{}{"prior", "login", "reset password"},
+		},
+		{
+			name:     "label or no labels",
+			query:    "label:backend,none",
+			wantSQL:  "AND (EXISTS ( SELECT 1 FROM task_labels tl JOIN board_labels bl ON bl.id = tl.label_id WHERE tl.task_id = t.id AND LOWER(bl.name) = ANY($2) ) OR NOT EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = t.id))",
+			wantArgs: []interface{}{"prior", []string{"backend"}},
+		},
+		{
+			name:     "assignee me, none and handles",
+			query:    "assignee:me,none,ana",
+			wantSQL:  "AND (tc.assignee = $2 OR tc.assignee IS NULL OR tc.assignee IN (SELECT u.id FROM users u WHERE u.handle = ANY($3) OR LOWER(u.email) = ANY($3)))",
+			wantArgs: []interface{}{"prior", userID, []string{"ana"}},
+		},
+		{
+			name:     "board",
+			query:    "board:" + boardID.String() + ",none",
+			wantSQL:  "AND (t.board_id IS NULL OR t.board_id = ANY($2))",
+			wantArgs: []interface{}{"prior", []uuid.UUID{boardID}},
+		},
+		{
+			name:     "no due date",
+			query:    "due:none",
+			wantSQL:  "AND (tc.due_date IS NULL)",
+			wantArgs: []interface{}{"prior"},
+		},
+		{
+			name: "whole day",
+			terms: []taskquery.Term{
+				{Field: taskquery.FieldCreated, Op: taskquery.OpEqual, Values: []string{"today"}, From: from, To: to},
+			},
+			wantSQL:  "AND (t.created_at >= $2 AND t.created_at < $3)",
+			wantArgs: []interface{}{"prior", from, to},
+		},
+		{
+			name: "date comparisons",
+			terms: []taskquery.Term{
+				{Field: taskquery.FieldDue, Op: taskquery.OpLess, Values: []string{"today"}, From: from, To: to},
+				{Field: taskquery.FieldDue, Op: taskquery.OpLessEqual, Values: []string{"today"}, From: from, To: to},
+				{Field: taskquery.FieldUpdated, Op: taskquery.OpGreater, Values: []string{"today"}, From: from, To: to},
+				{Field: taskquery.FieldUpdated, Op: taskquery.OpGreaterEqual, Values: []string{"today"}, From: from, To: to},
+			},
+			wantSQL:  "AND (tc.due_date < $2) AND (tc.due_date < $3) AND (t.updated_at >= $4) AND (t.updated_at >= $5)",
+			wantArgs: []interface{}{"prior", from, to, to, from},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &taskquery.Query{Terms: tt.terms}
+			if tt.terms == nil {
+				parsed, err := taskquery.Parse(tt.query)
+				if err != nil {
+					t.Fatalf("Parse(%q) error = %v", tt.query, err)
+				}
+				query = parsed
+			}
+
+			sql, args := taskQueryConditions(query, userID, []interface{}{"prior"})
+			if got := squash(sql); got != tt.wantSQL {
+				t.Errorf("SQL =\n%s\nwant\n%s", got, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestTaskQueryConditionsNeverInlineValues(t *testing.T) {
+	query, err := taskquery.Parse(`'; DROP TABLE tasks; -- label:"x') OR true --"`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	sql, _ := taskQueryConditions(query, uuid.New(), nil)
+	if strings.Contains(sql, "DROP") || strings.Contains(sql, "OR true") {
+		t.Errorf("query values were written into the SQL: %s", sql)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 
@@ -11,6 +12,8 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	taskmodel "github.com/rafaelzasas/vtasker/backend/internal/models/task"
+	"github.com/rafaelzasas/vtasker/backend/internal/taskquery"
 )
 
 // TaskRepository handles database operations for tasks
@@ -24,6 +27,20 @@ type TaskFilters struct {
 	Priority models.PriorityCode
 	Type     models.TypeCode
 	BoardID  *uuid.UUID
+	ParentID *uuid.UUID
+	Label    string
+	// Query is a parsed task query whose terms must all match as well
+	Query *taskquery.Query
+	// Sort is a field from TaskSortFields, with a leading - to sort
+	// descending. Empty is the board order.
+	Sort string
+	// Fields picks the parts of each task to load
+	Fields TaskFields
+	// parentIDs limits tasks to the subtasks of these tasks, for embedding
+	// subtasks
+	parentIDs []uuid.UUID
+	// taskID limits tasks to a single task, for loading one task's fields
+	taskID *uuid.UUID
 }
 
 // NewTaskRepository creates a new task repository
@@ -54,6 +71,7 @@ func (r *TaskRepository) GetTask(ctx context.Context, id string) (*models.Task,
 			t.type_id,
 			t.owner_id,
 			t.parent_id,
+			t.board_id,
 			t.order_index,
 			t.created_at,
 			t.updated_at,
@@ -76,6 +94,7 @@ func (r *TaskRepository) GetTask(ctx context.Context, id string) (*models.Task,
 		&task.TypeID,
 		&task.OwnerID,
 		&task.ParentID,
+		&task.BoardID,
 		&task.OrderIndex,
 		&task.CreatedAt,
 		&task.UpdatedAt,
@@ -190,6 +209,53 @@ func (r *TaskRepository) GetTask(ctx context.Context, id string) (*models.Task,
 		return nil, fmt.Errorf("error iterating acceptance criteria rows: %v", err)
 	}
 
+	// Get labels
+	labels, err := labelsForTasks(ctx, r.db, []uuid.UUID{task.ID})
+	if err != nil {
+		return nil, err
+	}
+	task.Labels = labels[task.ID]
+	if task.Labels == nil {
+		task.Labels = make([]models.Label, 0)
+	}
+
+	// Get collaborators
+	collaborators, err := collaboratorsForTasks(ctx, r.db, []uuid.UUID{task.ID})
+	if err != nil {
+		return nil, err
+	}
+	task.Collaborators = collaborators[task.ID]
+	if task.Collaborators == nil {
+		task.Collaborators = make([]taskmodel.Collaborator, 0)
+	}
+
+	// Get progress rollup
+	progress, err := progressForTasks(ctx, r.db, []uuid.UUID{task.ID})
+	if err != nil {
+		return nil, err
+	}
+	task.Progress = progress[task.ID]
+
+	// Get mentions
+	mentions, err := mentionsForTasks(ctx, r.db, []uuid.UUID{task.ID})
+	if err != nil {
+		return nil, err
+	}
+	task.Mentions = mentions[task.ID]
+	if task.Mentions == nil {
+		task.Mentions = make([]models.Mention, 0)
+	}
+
+	// Get attachments
+	taskAttachments, err := attachmentsForTasks(ctx, r.db, []uuid.UUID{task.ID})
+	if err != nil {
+		return nil, err
+	}
+	task.Attachments = taskAttachments[task.ID]
+	if task.Attachments == nil {
+		task.Attachments = make([]models.Attachment, 0)
+	}
+
 	return &task, nil
 }
 
@@ -204,6 +270,28 @@ func (r *TaskRepository) CreateTask(ctx context.Context, input *models.CreateTas
 	}
 	defer tx.Rollback(ctx)
 
+	if task.ParentID != nil {
+		if err := validateParent(ctx, tx, nil, *task.ParentID, task.BoardID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateWorkflowStatus(ctx, tx, task.BoardID, task.StatusID); err != nil {
+		return nil, err
+	}
+	if err := enforceWIPLimit(ctx, tx, task.BoardID, task.StatusID); err != nil {
+		return nil, err
+	}
+
+	// New tasks go to the end of their column
+	if err := lockTaskColumn(ctx, tx, task.BoardID, task.StatusID); err != nil {
+		return nil, err
+	}
+	task.OrderIndex, err = rankTask(ctx, tx, task.ID, task.BoardID, task.StatusID, taskPlacement{})
+	if err != nil {
+		return nil, err
+	}
+
 	// Create task
 	query := `
 		INSERT INTO tasks (
@@ -214,11 +302,12 @@ func (r *TaskRepository) CreateTask(ctx context.Context, input *models.CreateTas
 			priority_id,
 			type_id,
 			owner_id,
+			parent_id,
 			board_id,
 			order_index,
 			created_at,
 			updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id`
 
 	err = tx.QueryRow(ctx, query,
@@ -229,6 +318,7 @@ func (r *TaskRepository) CreateTask(ctx context.Context, input *models.CreateTas
 		task.PriorityID,
 		task.TypeID,
 		task.OwnerID,
+		task.ParentID,
 		task.BoardID,
 		task.OrderIndex,
 		task.CreatedAt,
@@ -307,6 +397,30 @@ func (r *TaskRepository) CreateTask(ctx context.Context, input *models.CreateTas
 		}
 	}
 
+	if err := recordTaskActivity(ctx, tx, task.ID, models.ActivityCreated, nil, map[string]any{
+		"title":     task.Title,
+		"status_id": task.StatusID,
+	}); err != nil {
+		return nil, err
+	}
+	if err := enqueueWebhookEvent(ctx, tx, task.BoardID, models.WebhookTaskCreated, map[string]any{"task": newTaskEventData(task)}); err != nil {
+		return nil, err
+	}
+	if err := notifyTaskChanges(ctx, tx, nil, task, input.Content.Assignee); err != nil {
+		return nil, err
+	}
+
+	// Resolve @mentions in the task's text
+	if err := saveMentions(ctx, tx, task, nil, models.MentionFieldDescription, task.Description); err != nil {
+		return nil, err
+	}
+	if err := saveMentions(ctx, tx, task, nil, models.MentionFieldNotes, input.Content.Notes); err != nil {
+		return nil, err
+	}
+	if err := saveMentions(ctx, tx, task, nil, models.MentionFieldImplementationDetails, input.Content.ImplementationDetails); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("error committing transaction: %v", err)
@@ -317,17 +431,38 @@ func (r *TaskRepository) CreateTask(ctx context.Context, input *models.CreateTas
 
 // UpdateTask updates an existing task
 func (r *TaskRepository) UpdateTask(ctx context.Context, id string, input *models.UpdateTaskInput, userID uuid.UUID) (*models.Task, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the task before loading it. The permission, transition, WIP and
+	// done gate checks below start from the locked status and board, which
+	// are the ones the task still has when this commits.
+	lockedStatusID, lockedBoardID, err := lockTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
 	task, err := r.GetTask(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	task.StatusID, task.BoardID = lockedStatusID, lockedBoardID
 
 	// Check if user has permission to update the task
-	if !task.CanUserEdit(userID) {
-		return nil, fmt.Errorf("user does not have permission to update this task")
+	boardRole, err := r.GetBoardRole(ctx, task.BoardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.CanUserEdit(userID, boardRole) {
+		return nil, ErrForbidden
 	}
 
 	// Update fields if provided in input
+	before := *task
+	previousStatusID := task.StatusID
 	if input.Title != nil {
 		task.Title = *input.Title
 	}
@@ -344,18 +479,77 @@ func (r *TaskRepository) UpdateTask(ctx context.Context, id string, input *model
 		task.TypeID = *input.TypeID
 	}
 	// Only update board_id if explicitly provided in input
+	boardChanged := false
 	if input.BoardID != nil {
+		boardChanged = task.BoardID == nil || *task.BoardID != *input.BoardID
 		task.BoardID = input.BoardID
 	}
 	// Keep existing board_id if not provided in input
 	// This ensures we don't accidentally set it to null
 
-	// Start transaction
-	tx, err := r.db.Begin(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error starting transaction: %v", err)
+	if input.RemoveParent {
+		task.ParentID = nil
+	} else if input.ParentID != nil {
+		task.ParentID = input.ParentID
+	}
+
+	// Re-check the parent whenever the task moves in the hierarchy or to another board
+	if task.ParentID != nil && (input.ParentID != nil || boardChanged) {
+		if err := lockTaskHierarchy(ctx, tx); err != nil {
+			return nil, err
+		}
+		if err := validateParent(ctx, tx, &task.ID, *task.ParentID, task.BoardID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the task within its board's workflow. Moving to another board
+	// isn't a transition, but the status has to exist on the new board.
+	if task.StatusID != previousStatusID || boardChanged {
+		if err := validateWorkflowStatus(ctx, tx, task.BoardID, task.StatusID); err != nil {
+			return nil, err
+		}
+	}
+	if task.StatusID != previousStatusID && !boardChanged {
+		if err := checkStatusTransition(ctx, tx, task.BoardID, previousStatusID, task.StatusID); err != nil {
+			return nil, err
+		}
+	}
+
+	// A task entering another column counts against that column's WIP limit
+	if task.StatusID != previousStatusID || boardChanged {
+		if err := enforceWIPLimit(ctx, tx, task.BoardID, task.StatusID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Re-rank the task when it's reordered or enters another column; tasks
+	// entering a column without a position go to the end of it
+	if input.Order != nil || input.BeforeID != nil || input.AfterID != nil || task.StatusID != previousStatusID || boardChanged {
+		if err := lockTaskColumn(ctx, tx, task.BoardID, task.StatusID); err != nil {
+			return nil, err
+		}
+		task.OrderIndex, err = rankTask(ctx, tx, task.ID, task.BoardID, task.StatusID, taskPlacement{
+			Position: input.Order,
+			BeforeID: input.BeforeID,
+			AfterID:  input.AfterID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Enforce the definition of done when the task changes status. Board
+	// admins can override it; on tasks without a board the task admin can.
+	if task.StatusID != previousStatusID {
+		canOverride := boardRole == models.BoardRoleAdmin
+		if task.BoardID == nil {
+			canOverride = task.CanUserAdmin(userID, boardRole)
+		}
+		if err := enforceDoneGate(ctx, tx, task, input, userID, canOverride); err != nil {
+			return nil, err
+		}
 	}
-	defer tx.Rollback(ctx)
 
 	// Update task
 	query := `
@@ -367,8 +561,10 @@ func (r *TaskRepository) UpdateTask(ctx context.Context, id string, input *model
 			priority_id = $4,
 			type_id = $5,
 			board_id = COALESCE($6, board_id),  -- Use COALESCE to keep existing board_id if not provided
+			parent_id = $7,
+			order_index = $8,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $7`
+		WHERE id = $9`
 
 	_, err = tx.Exec(ctx, query,
 		task.Title,
@@ -377,6 +573,8 @@ func (r *TaskRepository) UpdateTask(ctx context.Context, id string, input *model
 		task.PriorityID,
 		task.TypeID,
 		task.BoardID,  // This will be null if not provided in input
+		task.ParentID,
+		task.OrderIndex,
 		task.ID,
 	)
 
@@ -384,6 +582,13 @@ func (r *TaskRepository) UpdateTask(ctx context.Context, id string, input *model
 		return nil, fmt.Errorf("error updating task: %v", err)
 	}
 
+	// Subtasks follow their parent to the new board
+	if boardChanged {
+		if err := moveSubtreeToBoard(ctx, tx, task.ID, task.BoardID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update task content if provided
 	if input.Content != nil {
 		contentQuery := `
@@ -425,6 +630,55 @@ func (r *TaskRepository) UpdateTask(ctx context.Context, id string, input *model
 		}
 	}
 
+	changes := taskChanges(&before, task, input.Content)
+	if err := recordTaskActivity(ctx, tx, task.ID, models.ActivityUpdated, changes, nil); err != nil {
+		return nil, err
+	}
+	if err := enqueueTaskUpdateEvents(ctx, tx, &before, task, changes); err != nil {
+		return nil, err
+	}
+
+	// Content is replaced as a whole, so the assignee only changes with it
+	assignee := before.Content.Assignee
+	if input.Content != nil {
+		assignee = input.Content.Assignee
+	}
+	if err := notifyTaskChanges(ctx, tx, &before, task, assignee); err != nil {
+		return nil, err
+	}
+
+	// Re-resolve @mentions in text that was saved. Moving to another board
+	// changes who can be mentioned, so every field is re-resolved then, and
+	// mentions in comments of people who can't see the new board are dropped.
+	if input.Description != nil || boardChanged {
+		if err := saveMentions(ctx, tx, task, nil, models.MentionFieldDescription, task.Description); err != nil {
+			return nil, err
+		}
+	}
+	if input.Content != nil || boardChanged {
+		notes, implementationDetails := before.Content.Notes, before.Content.ImplementationDetails
+		if input.Content != nil {
+			notes, implementationDetails = "", ""
+			if input.Content.Notes != nil {
+				notes = *input.Content.Notes
+			}
+			if input.Content.ImplementationDetails != nil {
+				implementationDetails = *input.Content.ImplementationDetails
+			}
+		}
+		if err := saveMentions(ctx, tx, task, nil, models.MentionFieldNotes, notes); err != nil {
+			return nil, err
+		}
+		if err := saveMentions(ctx, tx, task, nil, models.MentionFieldImplementationDetails, implementationDetails); err != nil {
+			return nil, err
+		}
+	}
+	if boardChanged && task.BoardID != nil {
+		if err := pruneBoardMentions(ctx, tx, *task.BoardID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Commit transaction
 	if err = tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("error committing transaction: %v", err)
@@ -433,10 +687,63 @@ func (r *TaskRepository) UpdateTask(ctx context.Context, id string, input *model
 	return r.GetTask(ctx, id)
 }
 
+// lockTask locks a task's row until the end of tx and returns its status and
+// board as of the lock. Concurrent updates of the task wait for it, and reads
+// made after it see the latest committed task.
+func lockTask(ctx context.Context, tx pgx.Tx, id string) (int32, *uuid.UUID, error) {
+	taskID, err := uuid.Parse(id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("task not found")
+	}
+
+	var statusID int32
+	var boardID *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT status_id, board_id FROM tasks WHERE id = $1 FOR UPDATE`, taskID).Scan(&statusID, &boardID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, fmt.Errorf("task not found")
+		}
+		return 0, nil, fmt.Errorf("error locking task: %v", err)
+	}
+	return statusID, boardID, nil
+}
+
 // DeleteTask deletes a task by ID
 func (r *TaskRepository) DeleteTask(ctx context.Context, id string) error {
+	taskID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("task not found")
+	}
+
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Record the deletion first, while the task's board can still be looked up
+	var title string
+	var boardID *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT title, board_id FROM tasks WHERE id = $1`, taskID).Scan(&title, &boardID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("task not found")
+		}
+		return fmt.Errorf("error getting task: %v", err)
+	}
+	if err := recordTaskActivity(ctx, tx, taskID, models.ActivityDeleted, nil, map[string]any{"title": title}); err != nil {
+		return err
+	}
+	err = enqueueWebhookEvent(ctx, tx, boardID, models.WebhookTaskDeleted, map[string]any{
+		"task": map[string]any{"id": taskID, "title": title, "board_id": boardID},
+	})
+	if err != nil {
+		return err
+	}
+
 	query := `DELETE FROM tasks WHERE id = $1`
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := tx.Exec(ctx, query, taskID)
 	if err != nil {
 		return fmt.Errorf("error deleting task: %v", err)
 	}
@@ -445,12 +752,54 @@ func (r *TaskRepository) DeleteTask(ctx context.Context, id string) error {
 		return fmt.Errorf("task not found")
 	}
 
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
 	return nil
 }
 
 // GetTasks retrieves all tasks with optional filtering
 func (r *TaskRepository) GetTasks(ctx context.Context, filters TaskFilters, userID uuid.UUID) ([]*models.Task, error) {
-	var tasks []*models.Task
+	page, err := r.ListTasks(ctx, filters, userID, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// ListTasks retrieves a page of tasks with optional filtering, in the order
+// of filters.Sort. A limit of 0 returns every task.
+func (r *TaskRepository) ListTasks(ctx context.Context, filters TaskFilters, userID uuid.UUID, cursor string, limit int) (*models.TaskPage, error) {
+	tasks := make([]*models.Task, 0)
+	var sortKeys []string
+
+	keyset, err := taskKeyset(filters.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only join the content when it's needed; otherwise its columns are NULL
+	contentColumns := `
+			NULL::text,
+			NULL::text,
+			NULL::text,
+			NULL::jsonb,
+			NULL::timestamptz,
+			NULL::uuid,`
+	contentJoin := ""
+	if filters.needsContent() {
+		contentColumns = `
+			tc.description as content_description,
+			tc.implementation_details,
+			tc.notes,
+			tc.attachments,
+			tc.due_date,
+			tc.assignee,`
+		contentJoin = `
+		LEFT JOIN task_contents tc ON t.id = tc.task_id`
+	}
 
 	// First get all tasks with their content
 	query := `
@@ -466,15 +815,10 @@ func (r *TaskRepository) GetTasks(ctx context.Context, filters TaskFilters, user
 			t.board_id,
 			t.order_index,
 			t.created_at,
-			t.updated_at,
-			tc.description as content_description,
-			tc.implementation_details,
-			tc.notes,
-			tc.attachments,
-			tc.due_date,
-			tc.assignee
-		FROM tasks t
-		LEFT JOIN task_contents tc ON t.id = tc.task_id
+			t.updated_at,` + contentColumns + `
+			` + keyset.KeyColumn() + `
+		FROM tasks t` + contentJoin + `
+		LEFT JOIN task_statuses ts ON ts.id = t.status_id
 		WHERE 1=1`
 
 	args := []interface{}{}
@@ -482,20 +826,44 @@ func (r *TaskRepository) GetTasks(ctx context.Context, filters TaskFilters, user
 
 	// Add filters
 	if filters.Status != "" {
-		query += fmt.Sprintf(" AND t.status_id = $%d", argNum)
+		query += fmt.Sprintf(" AND ts.code = $%d", argNum)
 		args = append(args, filters.Status)
 		argNum++
 	}
 	if filters.Priority != "" {
-		query += fmt.Sprintf(" AND t.priority_id = $%d", argNum)
+		query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM task_priorities tp WHERE tp.id = t.priority_id AND tp.code = $%d)", argNum)
 		args = append(args, filters.Priority)
 		argNum++
 	}
 	if filters.Type != "" {
-		query += fmt.Sprintf(" AND t.type_id = $%d", argNum)
+		query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM task_types tt WHERE tt.id = t.type_id AND tt.code = $%d)", argNum)
 		args = append(args, filters.Type)
 		argNum++
 	}
+	if filters.Label != "" {
+		query += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM task_labels tl
+			JOIN board_labels bl ON bl.id = tl.label_id
+			WHERE tl.task_id = t.id AND LOWER(bl.name) = LOWER($%d)
+		)`, argNum)
+		args = append(args, filters.Label)
+		argNum++
+	}
+	if filters.ParentID != nil {
+		query += fmt.Sprintf(" AND t.parent_id = $%d", argNum)
+		args = append(args, filters.ParentID)
+		argNum++
+	}
+	if filters.parentIDs != nil {
+		query += fmt.Sprintf(" AND t.parent_id = ANY($%d)", argNum)
+		args = append(args, filters.parentIDs)
+		argNum++
+	}
+	if filters.taskID != nil {
+		query += fmt.Sprintf(" AND t.id = $%d", argNum)
+		args = append(args, filters.taskID)
+		argNum++
+	}
 	if filters.BoardID != nil {
 		query += fmt.Sprintf(" AND t.board_id = $%d", argNum)
 		args = append(args, filters.BoardID)
@@ -513,16 +881,30 @@ func (r *TaskRepository) GetTasks(ctx context.Context, filters TaskFilters, user
 		argNum += 3
 	} else {
 		// If no board specified, only show tasks from boards the user has access to
-		query += fmt.Sprintf(` AND (
-			t.board_id IS NULL OR
-			EXISTS (SELECT 1 FROM boards b WHERE b.id = t.board_id AND (
-				b.is_public = true OR
-				b.owner_id = $%d OR
-				EXISTS (SELECT 1 FROM board_members bm WHERE bm.board_id = b.id AND bm.user_id = $%d)
-			))
-		)`, argNum, argNum+1)
-		args = append(args, userID, userID)
-		argNum += 2
+		query += " AND " + taskAccessCondition(argNum)
+		args = append(args, userID)
+		argNum++
+	}
+	if filters.Query != nil {
+		var conditions string
+		conditions, args = taskQueryConditions(filters.Query, userID, args)
+		query += conditions
+		argNum = len(args) + 1
+	}
+	if cursor != "" {
+		key, err := keyset.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND " + keyset.After(argNum)
+		args = append(args, key)
+		argNum++
+	}
+
+	query += keyset.OrderBy()
+	if limit > 0 {
+		// Fetch one extra row to know whether there's another page
+		query += fmt.Sprintf(" LIMIT %d", limit+1)
 	}
 
 	rows, err := r.db.Query(ctx, query, args...)
@@ -531,14 +913,13 @@ func (r *TaskRepository) GetTasks(ctx context.Context, filters TaskFilters, user
 	}
 	defer rows.Close()
 
-	taskMap := make(map[uuid.UUID]*models.Task)
-
 	for rows.Next() {
 		var task models.Task
 		var contentDescription, implementationDetails, notes sql.NullString
 		var attachments []byte
 		var dueDate sql.NullTime
 		var assignee sql.NullString
+		var sortKey string
 
 		err := rows.Scan(
 			&task.ID,
@@ -559,6 +940,7 @@ func (r *TaskRepository) GetTasks(ctx context.Context, filters TaskFilters, user
 			&attachments,
 			&dueDate,
 			&assignee,
+			&sortKey,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning task row: %v", err)
@@ -591,138 +973,158 @@ func (r *TaskRepository) GetTasks(ctx context.Context, filters TaskFilters, user
 			task.Content.Assignee = &assigneeUUID
 		}
 
-		taskMap[task.ID] = &task
 		tasks = append(tasks, &task)
+		sortKeys = append(sortKeys, sortKey)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating task rows: %v", err)
 	}
 
-	// Get acceptance criteria for all tasks
-	if len(tasks) > 0 {
-		acQuery := `
-			SELECT 
-				id,
-				task_id,
-				description,
-				completed,
-				completed_at,
-				completed_by,
-				order_index,
-				category,
-				notes,
-				created_at,
-				updated_at
-			FROM acceptance_criteria
-			WHERE task_id = ANY($1)
-			ORDER BY task_id, order_index`
+	page := &models.TaskPage{}
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+		page.NextCursor = keyset.Cursor(sortKeys[limit-1])
+	}
+	page.Items = tasks
 
-		taskIDs := make([]uuid.UUID, len(tasks))
-		for i, task := range tasks {
-			taskIDs[i] = task.ID
-		}
+	if len(tasks) == 0 {
+		return page, nil
+	}
+
+	taskIDs := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = task.ID
+	}
+	fields := filters.Fields
 
-		acRows, err := r.db.Query(ctx, acQuery, taskIDs)
+	// Get acceptance criteria for all tasks
+	if fields.ReturnsCriteria() {
+		criteria, err := criteriaForTasks(ctx, r.db, taskIDs)
 		if err != nil {
-			return nil, fmt.Errorf("error querying acceptance criteria: %v", err)
-		}
-		defer acRows.Close()
-
-		for acRows.Next() {
-			var ac models.AcceptanceCriterion
-			var taskID uuid.UUID
-			var category, notes sql.NullString
-			var completedAt sql.NullTime
-			var completedBy sql.NullString
-
-			err := acRows.Scan(
-				&ac.ID,
-				&taskID,
-				&ac.Description,
-				&ac.Completed,
-				&completedAt,
-				&completedBy,
-				&ac.Order,
-				&category,
-				&notes,
-				&ac.CreatedAt,
-				&ac.UpdatedAt,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("error scanning acceptance criterion row: %v", err)
+			return nil, err
+		}
+		for _, task := range tasks {
+			if taskCriteria, ok := criteria[task.ID]; ok {
+				task.Content.AcceptanceCriteria = taskCriteria
 			}
+		}
+	}
 
-			if category.Valid {
-				ac.Category = &category.String
-			}
-			if notes.Valid {
-				ac.Notes = &notes.String
-			}
-			if completedAt.Valid {
-				ac.CompletedAt = &completedAt.Time
-			}
-			if completedBy.Valid {
-				completedByUUID, err := uuid.Parse(completedBy.String)
-				if err != nil {
-					return nil, fmt.Errorf("error parsing completed_by UUID: %v", err)
-				}
-				ac.CompletedBy = &completedByUUID
+	// Get labels for all tasks
+	if fields.Returns("labels") {
+		labels, err := labelsForTasks(ctx, r.db, taskIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			task.Labels = labels[task.ID]
+			if task.Labels == nil {
+				task.Labels = make([]models.Label, 0)
 			}
+		}
+	}
 
-			if task, ok := taskMap[taskID]; ok {
-				task.Content.AcceptanceCriteria = append(task.Content.AcceptanceCriteria, ac)
+	// Get collaborators for all tasks
+	if fields.Returns("collaborators") {
+		collaborators, err := collaboratorsForTasks(ctx, r.db, taskIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			task.Collaborators = collaborators[task.ID]
+			if task.Collaborators == nil {
+				task.Collaborators = make([]taskmodel.Collaborator, 0)
 			}
 		}
+	}
 
-		if err = acRows.Err(); err != nil {
-			return nil, fmt.Errorf("error iterating acceptance criteria rows: %v", err)
+	// Get progress rollups for all tasks
+	if fields.Returns("progress") {
+		progress, err := progressForTasks(ctx, r.db, taskIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			task.Progress = progress[task.ID]
 		}
 	}
 
-	return tasks, nil
-}
-
-// ListTaskStatuses returns a list of all task statuses
-func (r *TaskRepository) ListTaskStatuses(ctx context.Context) ([]models.TaskStatus, error) {
-	query := `
-		SELECT id, code, name, description, display_order, created_at, updated_at
-		FROM task_statuses
-		ORDER BY display_order
-	`
-	rows, err := r.db.Query(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("error listing task statuses: %v", err)
+	// Get mentions for all tasks
+	if fields.Returns("mentions") {
+		mentions, err := mentionsForTasks(ctx, r.db, taskIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			task.Mentions = mentions[task.ID]
+			if task.Mentions == nil {
+				task.Mentions = make([]models.Mention, 0)
+			}
+		}
 	}
-	defer rows.Close()
 
-	var statuses []models.TaskStatus
-	for rows.Next() {
-		var status models.TaskStatus
-		err := rows.Scan(
-			&status.ID,
-			&status.Code,
-			&status.Name,
-			&status.Description,
-			&status.DisplayOrder,
-			&status.CreatedAt,
-			&status.UpdatedAt,
-		)
+	// Get attachments for all tasks
+	if fields.Returns("attachments") {
+		taskAttachments, err := attachmentsForTasks(ctx, r.db, taskIDs)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning task status: %v", err)
+			return nil, err
+		}
+		for _, task := range tasks {
+			task.Attachments = taskAttachments[task.ID]
+			if task.Attachments == nil {
+				task.Attachments = make([]models.Attachment, 0)
+			}
 		}
-		statuses = append(statuses, status)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating task statuses: %v", err)
+	if err := r.EmbedRelations(ctx, tasks, fields, userID); err != nil {
+		return nil, err
 	}
 
-	if statuses == nil {
-		statuses = make([]models.TaskStatus, 0)
+	return page, nil
+}
+
+// taskAccessCondition returns an SQL condition that limits tasks aliased as t
+// to those without a board or on a board the user bound to $argNum can access
+func taskAccessCondition(argNum int) string {
+	return boardAccessCondition("t.board_id", fmt.Sprintf("$%d", argNum))
+}
+
+// boardAccessCondition returns an SQL condition that holds when the board
+// boardExpr is NULL or can be accessed by the user userExpr: it's public, or
+// the user owns it or is a member. Notifications and mentions use it to skip
+// users who can't see the board a task is on.
+func boardAccessCondition(boardExpr string, userExpr string) string {
+	return fmt.Sprintf(`(
+			%[1]s IS NULL OR
+			EXISTS (SELECT 1 FROM boards b WHERE b.id = %[1]s AND (
+				b.is_public = true OR
+				b.owner_id = %[2]s OR
+				EXISTS (SELECT 1 FROM board_members bm WHERE bm.board_id = b.id AND bm.user_id = %[2]s)
+			))
+		)`, boardExpr, userExpr)
+}
+
+// ListTaskStatuses returns the statuses of a board's workflow, or the global
+// statuses when boardID is nil
+func (r *TaskRepository) ListTaskStatuses(ctx context.Context, boardID *uuid.UUID) ([]models.TaskStatus, error) {
+	return listWorkflowStatuses(ctx, r.db, boardID)
+}
+
+// GetBoardColumn returns a board column with its task count and WIP limit
+func (r *TaskRepository) GetBoardColumn(ctx context.Context, boardID uuid.UUID, statusID int32) (*models.BoardColumn, error) {
+	columns, err := columnsForBoard(ctx, r.db, boardID)
+	if err != nil {
+		return nil, err
 	}
 
-	return statuses, nil
+	for i := range columns {
+		if columns[i].StatusID == statusID {
+			return &columns[i], nil
+		}
+	}
+	return nil, ErrNotFound
 }
 
 // ListTaskPriorities returns a list of all task priorities
@@ -0,0 +1,38 @@
+package repository
+
+// taskSortFields maps the fields tasks can be sorted by to the SQL they sort
+// on, with tasks aliased as t, their content as tc and their status as ts
+var taskSortFields = SortFields{
+	// Columns in workflow order, then tasks by rank within each column
+	"position": {
+		{Expr: "ts.display_order", Type: "integer"},
+		{Expr: "t.status_id", Type: "integer"},
+		{Expr: "t.order_index", Type: "double precision"},
+		{Expr: "t.created_at", Type: "timestamptz"},
+	},
+	"created_at": {{Expr: "t.created_at", Type: "timestamptz"}},
+	"updated_at": {{Expr: "t.updated_at", Type: "timestamptz"}},
+	"due_date": {{
+		Expr: "tc.due_date",
+		Type: "timestamptz",
+		Last: [2]string{"'infinity'::timestamptz", "'-infinity'::timestamptz"},
+	}},
+	"priority": {{Expr: "(SELECT tp.display_order FROM task_priorities tp WHERE tp.id = t.priority_id)", Type: "integer"}},
+	"title":    {{Expr: "LOWER(t.title)", Type: "text"}},
+}
+
+// TaskSortFields lists the fields tasks can be sorted by
+var TaskSortFields = taskSortFields.Names()
+
+// taskKeyset returns the keyset for a task sort: a field from
+// TaskSortFields, with a leading - to sort descending. An empty sort is the
+// board order.
+func taskKeyset(sort string) (*Keyset, error) {
+	return taskSortFields.Keyset(sort, "position", SortKey{Expr: "t.id", Type: "uuid"})
+}
+
+// ValidateTaskSort checks a task sort
+func ValidateTaskSort(sort string) error {
+	_, err := taskKeyset(sort)
+	return err
+}
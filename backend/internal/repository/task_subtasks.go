@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// ErrInvalidParent is returned when a parent task doesn't exist or is on a
+// different board than its subtask
+var ErrInvalidParent = errors.New("parent task must exist and be on the same board")
+
+// ErrParentCycle is returned when a task would be moved under one of its own subtasks
+var ErrParentCycle = errors.New("task can't be a subtask of itself or of its own subtasks")
+
+// lockTaskHierarchy serializes changes to parent links so two concurrent
+// re-parents can't close a cycle that neither of them sees on its own
+func lockTaskHierarchy(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('task_hierarchy'))`); err != nil {
+		return fmt.Errorf("error locking task hierarchy: %v", err)
+	}
+	return nil
+}
+
+// validateParent checks that parentID can be the parent of a task on boardID.
+// taskID is nil for tasks that don't exist yet and can't have subtasks.
+func validateParent(ctx context.Context, tx pgx.Tx, taskID *uuid.UUID, parentID uuid.UUID, boardID *uuid.UUID) error {
+	var parentBoardID *uuid.UUID
+	err := tx.QueryRow(ctx, `SELECT board_id FROM tasks WHERE id = $1`, parentID).Scan(&parentBoardID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidParent
+		}
+		return fmt.Errorf("error loading parent task: %v", err)
+	}
+
+	if (parentBoardID == nil) != (boardID == nil) ||
+		(parentBoardID != nil && *parentBoardID != *boardID) {
+		return ErrInvalidParent
+	}
+
+	if taskID == nil {
+		return nil
+	}
+	if *taskID == parentID {
+		return ErrParentCycle
+	}
+
+	// The new parent must not be somewhere below the task already
+	var isDescendant bool
+	err = tx.QueryRow(ctx, `
+		WITH RECURSIVE ancestors(id) AS (
+			SELECT parent_id FROM tasks WHERE id = $1
+			UNION
+			SELECT t.parent_id
+			FROM tasks t
+			JOIN ancestors a ON t.id = a.id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+	`, parentID, *taskID).Scan(&isDescendant)
+	if err != nil {
+		return fmt.Errorf("error checking task hierarchy: %v", err)
+	}
+	if isDescendant {
+		return ErrParentCycle
+	}
+
+	return nil
+}
+
+// moveSubtreeToBoard moves every descendant of a task to the given board so
+// a task and its subtasks always share a board
+func moveSubtreeToBoard(ctx context.Context, tx pgx.Tx, taskID uuid.UUID, boardID *uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM tasks WHERE parent_id = $1
+			UNION
+			SELECT t.id
+			FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+		)
+		UPDATE tasks
+		SET board_id = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (SELECT id FROM subtree)
+	`, taskID, boardID)
+	if err != nil {
+		return fmt.Errorf("error moving subtasks: %v", err)
+	}
+	return nil
+}
+
+// progressForTasks computes the completion rollup across the subtree of each
+// of the given tasks
+func progressForTasks(ctx context.Context, db *pgxpool.Pool, taskIDs []uuid.UUID) (map[uuid.UUID]*models.TaskProgress, error) {
+	rows, err := db.Query(ctx, `
+		WITH RECURSIVE subtree(root_id, id, depth) AS (
+			SELECT id, id, 0 FROM tasks WHERE id = ANY($1)
+			UNION ALL
+			SELECT s.root_id, t.id, s.depth + 1
+			FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+		)
+		SELECT
+			s.root_id,
+			COALESCE(SUM(ac.total), 0)::int,
+			COALESCE(SUM(ac.completed), 0)::int,
+			COUNT(*) FILTER (WHERE s.depth > 0),
+			COUNT(*) FILTER (WHERE s.depth > 0 AND ts.category = 'done')
+		FROM subtree s
+		JOIN tasks t ON t.id = s.id
+		LEFT JOIN task_statuses ts ON ts.id = t.status_id
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE completed) AS completed
+			FROM acceptance_criteria
+			WHERE task_id = s.id
+		) ac ON true
+		GROUP BY s.root_id
+	`, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying task progress: %v", err)
+	}
+	defer rows.Close()
+
+	progress := make(map[uuid.UUID]*models.TaskProgress)
+	for rows.Next() {
+		var taskID uuid.UUID
+		var p models.TaskProgress
+		err := rows.Scan(
+			&taskID,
+			&p.AcceptanceCriteria.Total,
+			&p.AcceptanceCriteria.Completed,
+			&p.Subtasks.Total,
+			&p.Subtasks.Completed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning task progress: %v", err)
+		}
+		p.CalculatePercentage()
+		progress[taskID] = &p
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task progress: %v", err)
+	}
+
+	return progress, nil
+}
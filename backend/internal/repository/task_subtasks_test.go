@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestValidateParent(t *testing.T) {
+	taskID, parentID := uuid.New(), uuid.New()
+	boardID, otherBoardID := uuid.New(), uuid.New()
+	noBoard := (*uuid.UUID)(nil)
+
+	parentOn := func(board *uuid.UUID) fakeRow { return fakeRow{values: []any{board}} }
+	descendant := func(is bool) fakeRow { return fakeRow{values: []any{is}} }
+
+	tests := []struct {
+		name    string
+		rows    []fakeRow
+		taskID  *uuid.UUID
+		parent  uuid.UUID
+		boardID *uuid.UUID
+		wantErr error
+	}{
+		{"missing parent", []fakeRow{{err: pgx.ErrNoRows}}, &taskID, parentID, &boardID, ErrInvalidParent},
+		{"parent on another board", []fakeRow{parentOn(&otherBoardID)}, &taskID, parentID, &boardID, ErrInvalidParent},
+		{"parent without a board", []fakeRow{parentOn(noBoard)}, &taskID, parentID, &boardID, ErrInvalidParent},
+		{"parent on a board for a task without one", []fakeRow{parentOn(&boardID)}, &taskID, parentID, nil, ErrInvalidParent},
+		{"new subtask", []fakeRow{parentOn(&boardID)}, nil, parentID, &boardID, nil},
+		{"new subtask without a board", []fakeRow{parentOn(noBoard)}, nil, parentID, nil, nil},
+		{"task as its own parent", []fakeRow{parentOn(&boardID)}, &taskID, taskID, &boardID, ErrParentCycle},
+		{"moving under its own subtask", []fakeRow{parentOn(&boardID), descendant(true)}, &taskID, parentID, &boardID, ErrParentCycle},
+		{"re-parenting", []fakeRow{parentOn(&boardID), descendant(false)}, &taskID, parentID, &boardID, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{queryRow: rowsInOrder(tt.rows...)}
+			err := validateParent(context.Background(), tx, tt.taskID, tt.parent, tt.boardID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateParent() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
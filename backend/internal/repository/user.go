@@ -34,6 +34,10 @@ type UserRepository interface {
 	// List retrieves all users with optional filtering
 	List(ctx context.Context, filter UserFilter) ([]*user.User, error)
 
+	// ListPage retrieves a page of users with optional filtering, in the
+	// order of a sort from UserSortFields. A limit of 0 returns every user.
+	ListPage(ctx context.Context, filter UserFilter, sort string, cursor string, limit int) (*user.UserPage, error)
+
 	// GetPool returns the database connection pool
 	GetPool() *pgxpool.Pool
 }
@@ -47,3 +51,26 @@ type UserFilter struct {
 	OrderBy  string
 	OrderDir string
 }
+
+// userSortFields maps the fields users can be sorted by to the SQL they sort
+// on, with users aliased as u
+var userSortFields = SortFields{
+	"created_at": {{Expr: "u.created_at", Type: "timestamptz"}},
+	"email":      {{Expr: "LOWER(u.email)", Type: "text"}},
+	"full_name":  {{Expr: "LOWER(u.full_name)", Type: "text"}},
+	"last_login_at": {{
+		Expr: "u.last_login_at",
+		Type: "timestamptz",
+		Last: [2]string{"'infinity'::timestamptz", "'-infinity'::timestamptz"},
+	}},
+}
+
+// UserSortFields lists the fields users can be sorted by
+var UserSortFields = userSortFields.Names()
+
+// UserKeyset returns the keyset for a user sort: a field from
+// UserSortFields, with a leading - to sort descending. Users are oldest
+// first by default.
+func UserKeyset(sort string) (*Keyset, error) {
+	return userSortFields.Keyset(sort, "created_at", SortKey{Expr: "u.id", Type: "uuid"})
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// viewSelect selects saved views the user bound to $1 can see, with whether
+// they've pinned them
+const viewSelect = `
+		SELECT
+			v.id,
+			v.owner_id,
+			v.board_id,
+			v.name,
+			v.query,
+			v.sort,
+			v.columns,
+			v.visibility,
+			EXISTS (SELECT 1 FROM saved_view_pins p WHERE p.view_id = v.id AND p.user_id = $1) AS pinned,
+			v.created_at,
+			v.updated_at
+		FROM saved_views v
+		WHERE (
+			v.owner_id = $1 OR (
+				v.visibility = 'board' AND
+				EXISTS (SELECT 1 FROM boards b WHERE b.id = v.board_id AND (
+					b.is_public = true OR
+					b.owner_id = $1 OR
+					EXISTS (SELECT 1 FROM board_members bm WHERE bm.board_id = b.id AND bm.user_id = $1)
+				))
+			)
+		)`
+
+// ViewRepository handles database operations for saved views
+type ViewRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewViewRepository creates a new saved view repository
+func NewViewRepository(db *pgxpool.Pool) *ViewRepository {
+	return &ViewRepository{db: db}
+}
+
+// ListViews returns the views the user can see, pinned views first. boardID
+// limits them to the views of one board.
+func (r *ViewRepository) ListViews(ctx context.Context, userID uuid.UUID, boardID *uuid.UUID) ([]models.SavedView, error) {
+	query := viewSelect
+	args := []interface{}{userID}
+	if boardID != nil {
+		query += ` AND v.board_id = $2`
+		args = append(args, *boardID)
+	}
+	query += ` ORDER BY pinned DESC, LOWER(v.name), v.id`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing views: %v", err)
+	}
+	defer rows.Close()
+
+	views := make([]models.SavedView, 0)
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *view)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating views: %v", err)
+	}
+
+	return views, nil
+}
+
+// GetView returns a view the user can see
+func (r *ViewRepository) GetView(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.SavedView, error) {
+	view, err := scanView(r.db.QueryRow(ctx, viewSelect+` AND v.id = $2`, userID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return view, nil
+}
+
+// CreateView saves a new view, pinning it for its owner if it's marked pinned
+func (r *ViewRepository) CreateView(ctx context.Context, view *models.SavedView) error {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO saved_views (id, owner_id, board_id, name, query, sort, columns, visibility)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`,
+		view.ID,
+		view.OwnerID,
+		view.BoardID,
+		view.Name,
+		view.Query,
+		view.Sort,
+		view.Columns,
+		view.Visibility,
+	).Scan(&view.CreatedAt, &view.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating view: %v", err)
+	}
+
+	if view.Pinned {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO saved_view_pins (view_id, user_id) VALUES ($1, $2)
+		`, view.ID, view.OwnerID)
+		if err != nil {
+			return fmt.Errorf("error pinning view: %v", err)
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateView changes a view's settings. Unset fields are left as they are.
+func (r *ViewRepository) UpdateView(ctx context.Context, id uuid.UUID, input *models.UpdateViewInput) error {
+	// A nil slice would be stored as a JSON null rather than left alone
+	var columns interface{}
+	if input.Columns != nil {
+		columns = input.Columns
+	}
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE saved_views
+		SET
+			name = COALESCE($1, name),
+			query = COALESCE($2, query),
+			sort = COALESCE($3, sort),
+			columns = COALESCE($4, columns),
+			visibility = COALESCE($5, visibility)
+		WHERE id = $6
+	`, input.Name, input.Query, input.Sort, columns, input.Visibility, id)
+	if err != nil {
+		return fmt.Errorf("error updating view: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteView deletes a view
+func (r *ViewRepository) DeleteView(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM saved_views WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting view: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetPinned pins or unpins a view for a user
+func (r *ViewRepository) SetPinned(ctx context.Context, id uuid.UUID, userID uuid.UUID, pinned bool) error {
+	var err error
+	if pinned {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO saved_view_pins (view_id, user_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, id, userID)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			DELETE FROM saved_view_pins WHERE view_id = $1 AND user_id = $2
+		`, id, userID)
+	}
+	if err != nil {
+		return fmt.Errorf("error pinning view: %v", err)
+	}
+	return nil
+}
+
+// scanView scans a row of viewSelect
+func scanView(row pgx.Row) (*models.SavedView, error) {
+	var view models.SavedView
+	err := row.Scan(
+		&view.ID,
+		&view.OwnerID,
+		&view.BoardID,
+		&view.Name,
+		&view.Query,
+		&view.Sort,
+		&view.Columns,
+		&view.Visibility,
+		&view.Pinned,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error scanning view: %v", err)
+	}
+	if view.Columns == nil {
+		view.Columns = make([]string, 0)
+	}
+	return &view, nil
+}
@@ -0,0 +1,512 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+const webhookColumns = `
+	id,
+	board_id,
+	url,
+	secret,
+	events,
+	is_active,
+	created_by,
+	created_at,
+	updated_at`
+
+const webhookDeliveryColumns = `
+	id,
+	webhook_id,
+	event_id,
+	event_type,
+	payload,
+	status,
+	attempts,
+	next_attempt_at,
+	last_attempt_at,
+	response_status,
+	last_error,
+	delivered_at,
+	created_at`
+
+// WebhookRepository handles database operations for webhooks and their
+// delivery outbox
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// ListWebhooks retrieves the webhooks on a board
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, boardID uuid.UUID) ([]models.Webhook, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE board_id = $1
+		ORDER BY created_at
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	webhooks := make([]models.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = ""
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhooks: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook retrieves a webhook on a board, without its secret
+func (r *WebhookRepository) GetWebhook(ctx context.Context, boardID uuid.UUID, id uuid.UUID) (*models.Webhook, error) {
+	webhook, err := scanWebhook(r.db.QueryRow(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE board_id = $1 AND id = $2
+	`, boardID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// CreateWebhook subscribes a URL to a board's events. The returned webhook
+// includes its secret, generated if the input doesn't have one.
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, boardID uuid.UUID, input *models.CreateWebhookInput, userID uuid.UUID) (*models.Webhook, error) {
+	secret := ""
+	if input.Secret != nil {
+		secret = *input.Secret
+	} else {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	return scanWebhook(r.db.QueryRow(ctx, `
+		INSERT INTO webhooks (board_id, url, secret, events, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+webhookColumns,
+		boardID,
+		input.URL,
+		secret,
+		input.Events,
+		isActive,
+		userID,
+	))
+}
+
+// UpdateWebhook changes a webhook's URL, events, secret or active flag
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, boardID uuid.UUID, id uuid.UUID, input *models.UpdateWebhookInput) (*models.Webhook, error) {
+	var events []string
+	if len(input.Events) > 0 {
+		events = input.Events
+	}
+
+	webhook, err := scanWebhook(r.db.QueryRow(ctx, `
+		UPDATE webhooks
+		SET
+			url = COALESCE($1, url),
+			events = COALESCE($2, events),
+			secret = COALESCE($3, secret),
+			is_active = COALESCE($4, is_active)
+		WHERE board_id = $5 AND id = $6
+		RETURNING `+webhookColumns,
+		input.URL,
+		events,
+		input.Secret,
+		input.IsActive,
+		boardID,
+		id,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, boardID uuid.UUID, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		DELETE FROM webhooks
+		WHERE board_id = $1 AND id = $2
+	`, boardID, id)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %v", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves a page of a webhook's delivery log, newest first,
+// optionally only deliveries with the given status
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID uuid.UUID, status models.WebhookDeliveryStatus, cursor string, limit int) (*models.WebhookDeliveryPage, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1`
+	args := []interface{}{webhookID}
+
+	if status != "" {
+		query += fmt.Sprintf(" AND status = $%d", len(args)+1)
+		args = append(args, status)
+	}
+	keyset := newestFirst("created_at", "id")
+	if cursor != "" {
+		key, err := keyset.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, key)
+		query += " AND " + keyset.After(len(args))
+	}
+
+	// Fetch one extra row to know whether there's another page
+	query += keyset.OrderBy() + fmt.Sprintf(" LIMIT %d", limit+1)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	page := &models.WebhookDeliveryPage{Items: make([]models.WebhookDelivery, 0, limit)}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, *delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %v", err)
+	}
+
+	if len(page.Items) > limit {
+		last := page.Items[limit-1]
+		page.Items = page.Items[:limit]
+		page.NextCursor = keyset.CursorAt(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// Redeliver queues a fresh delivery of the same event, leaving the original
+// in the log as it was
+func (r *WebhookRepository) Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	return scanWebhookDelivery(r.db.QueryRow(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT webhook_id, event_id, event_type, payload
+		FROM webhook_deliveries
+		WHERE webhook_id = $1 AND id = $2
+		RETURNING `+webhookDeliveryColumns,
+		webhookID,
+		deliveryID,
+	))
+}
+
+// ClaimDueDeliveries picks up to limit deliveries that are due and pushes
+// their next attempt out by lease, so other dispatchers skip them while
+// they're being sent. A delivery whose dispatcher dies mid-send is retried
+// once the lease runs out.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingWebhookDelivery, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+			AND d.id IN (
+				SELECT dd.id
+				FROM webhook_deliveries dd
+				JOIN webhooks ww ON ww.id = dd.webhook_id
+				WHERE dd.status = 'pending'
+					AND dd.next_attempt_at <= CURRENT_TIMESTAMP
+					AND ww.is_active
+				ORDER BY dd.next_attempt_at
+				LIMIT $1
+				FOR UPDATE OF dd SKIP LOCKED
+			)
+		RETURNING d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempts, w.url, w.secret
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.PendingWebhookDelivery
+	for rows.Next() {
+		var delivery models.PendingWebhookDelivery
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Attempts,
+			&delivery.URL,
+			&delivery.Secret,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %v", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// MarkDelivered records a successful attempt
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id uuid.UUID, responseStatus int) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET
+			status = 'delivered',
+			attempts = attempts + 1,
+			last_attempt_at = CURRENT_TIMESTAMP,
+			response_status = $1,
+			last_error = NULL,
+			delivered_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, responseStatus, id)
+	if err != nil {
+		return fmt.Errorf("error marking webhook delivered: %v", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt. The delivery is retried at
+// nextAttemptAt, or moved to the dead-letter state when that's nil.
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id uuid.UUID, responseStatus *int, lastError string, nextAttemptAt *time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET
+			status = CASE WHEN $1::timestamptz IS NULL THEN 'dead' ELSE 'pending' END,
+			attempts = attempts + 1,
+			last_attempt_at = CURRENT_TIMESTAMP,
+			next_attempt_at = COALESCE($1, next_attempt_at),
+			response_status = $2,
+			last_error = $3
+		WHERE id = $4
+	`, nextAttemptAt, responseStatus, lastError, id)
+	if err != nil {
+		return fmt.Errorf("error marking webhook delivery failed: %v", err)
+	}
+	return nil
+}
+
+// enqueueWebhookEvent adds a delivery to the outbox for every active webhook
+// on the board subscribed to eventType. It runs in the caller's transaction,
+// so an event is only sent if the change it describes commits.
+func enqueueWebhookEvent(ctx context.Context, tx pgx.Tx, boardID *uuid.UUID, eventType string, data any) error {
+	if boardID == nil {
+		return nil
+	}
+
+	event := models.WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		BoardID:   *boardID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling webhook event: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT id, $2, $3::text, $4
+		FROM webhooks
+		WHERE board_id = $1
+			AND is_active
+			AND $3::text = ANY(events)
+	`, *boardID, event.ID, eventType, payload)
+	if err != nil {
+		return fmt.Errorf("error queueing webhook event: %v", err)
+	}
+
+	return nil
+}
+
+// taskEventData is the task as it appears in webhook payloads
+type taskEventData struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	StatusID    int32      `json:"status_id"`
+	PriorityID  int32      `json:"priority_id"`
+	TypeID      int32      `json:"type_id"`
+	BoardID     *uuid.UUID `json:"board_id,omitempty"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	OrderIndex  float64    `json:"order_index"`
+}
+
+// newTaskEventData snapshots a task for a webhook payload
+func newTaskEventData(task *models.Task) taskEventData {
+	return taskEventData{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		StatusID:    task.StatusID,
+		PriorityID:  task.PriorityID,
+		TypeID:      task.TypeID,
+		BoardID:     task.BoardID,
+		ParentID:    task.ParentID,
+		OrderIndex:  task.OrderIndex,
+	}
+}
+
+// taskPosition is where a task sits, as reported by task.moved events
+type taskPosition struct {
+	BoardID    *uuid.UUID `json:"board_id,omitempty"`
+	StatusID   int32      `json:"status_id"`
+	OrderIndex float64    `json:"order_index"`
+}
+
+// enqueueTaskUpdateEvents queues task.moved when an update changed the
+// task's board, column or rank, and task.updated when it changed anything
+// else. A task moved to another board is reported to both boards.
+func enqueueTaskUpdateEvents(ctx context.Context, tx pgx.Tx, before, after *models.Task, changes []models.FieldChange) error {
+	var fieldChanges []models.FieldChange
+	moved := false
+	for _, change := range changes {
+		switch change.Field {
+		case "status_id", "board_id", "order_index":
+			moved = true
+		default:
+			fieldChanges = append(fieldChanges, change)
+		}
+	}
+
+	task := newTaskEventData(after)
+	if moved {
+		data := map[string]any{
+			"task": task,
+			"from": taskPosition{BoardID: before.BoardID, StatusID: before.StatusID, OrderIndex: before.OrderIndex},
+			"to":   taskPosition{BoardID: after.BoardID, StatusID: after.StatusID, OrderIndex: after.OrderIndex},
+		}
+		if err := enqueueWebhookEvent(ctx, tx, after.BoardID, models.WebhookTaskMoved, data); err != nil {
+			return err
+		}
+		if before.BoardID != nil && (after.BoardID == nil || *before.BoardID != *after.BoardID) {
+			if err := enqueueWebhookEvent(ctx, tx, before.BoardID, models.WebhookTaskMoved, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(fieldChanges) > 0 {
+		data := map[string]any{"task": task, "changes": fieldChanges}
+		if err := enqueueWebhookEvent(ctx, tx, after.BoardID, models.WebhookTaskUpdated, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateWebhookSecret returns a random secret for signing payloads
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %v", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// scanWebhook scans a row selected with webhookColumns
+func scanWebhook(row pgx.Row) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.BoardID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.IsActive,
+		&webhook.CreatedBy,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error scanning webhook: %v", err)
+	}
+
+	return &webhook, nil
+}
+
+// scanWebhookDelivery scans a row selected with webhookDeliveryColumns
+func scanWebhookDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastAttemptAt,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error scanning webhook delivery: %v", err)
+	}
+
+	return &delivery, nil
+}
@@ -0,0 +1,574 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// ErrStatusCodeExists is returned when a board already has a status with the given code
+var ErrStatusCodeExists = errors.New("status code already exists")
+
+// ErrStatusInUse is returned when deleting a status that tasks are still in
+var ErrStatusInUse = errors.New("status is still used by tasks")
+
+// ErrInvalidStatus is returned when a status isn't part of the task's board workflow
+var ErrInvalidStatus = errors.New("status isn't part of this board's workflow")
+
+// ErrTransitionNotAllowed is returned when a board's workflow doesn't allow a status change
+var ErrTransitionNotAllowed = errors.New("board workflow doesn't allow this status change")
+
+// WIPLimitError is returned when a task would enter a column that's at its hard WIP limit
+type WIPLimitError struct {
+	StatusID int32
+	Limit    int32
+	Count    int32
+}
+
+func (e *WIPLimitError) Error() string {
+	return "column is at its WIP limit"
+}
+
+const taskStatusColumns = `
+	id,
+	board_id,
+	code,
+	name,
+	description,
+	color,
+	icon,
+	category,
+	display_order,
+	created_at,
+	updated_at`
+
+// WorkflowRepository handles database operations for board statuses and transitions
+type WorkflowRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWorkflowRepository creates a new workflow repository
+func NewWorkflowRepository(db *pgxpool.Pool) *WorkflowRepository {
+	return &WorkflowRepository{db: db}
+}
+
+// ListStatuses retrieves the statuses of a board's workflow in display order.
+// Boards that don't define their own statuses use the global ones, which is
+// also what a nil boardID returns.
+func (r *WorkflowRepository) ListStatuses(ctx context.Context, boardID *uuid.UUID) ([]models.TaskStatus, error) {
+	return listWorkflowStatuses(ctx, r.db, boardID)
+}
+
+// CreateStatus adds a status to a board's workflow. The first status a board
+// creates replaces the global workflow for that board.
+func (r *WorkflowRepository) CreateStatus(ctx context.Context, boardID uuid.UUID, input *models.CreateStatusInput) (*models.TaskStatus, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Transitions between global statuses stop applying once the board has its own
+	_, err = tx.Exec(ctx, `
+		DELETE FROM board_status_transitions bst
+		USING task_statuses ts
+		WHERE bst.board_id = $1
+			AND ts.id = bst.from_status_id
+			AND ts.board_id IS NULL
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error clearing global transitions: %v", err)
+	}
+
+	status, err := scanTaskStatus(tx.QueryRow(ctx, `
+		INSERT INTO task_statuses (
+			board_id,
+			code,
+			name,
+			description,
+			color,
+			icon,
+			category,
+			display_order
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, COALESCE($8, MAX(display_order) + 1, 0)
+		FROM task_statuses
+		WHERE board_id = $1
+		RETURNING `+taskStatusColumns,
+		boardID,
+		input.Code,
+		input.Name,
+		input.Description,
+		input.Color,
+		input.Icon,
+		input.Category,
+		input.DisplayOrder,
+	))
+	if err != nil {
+		if strings.Contains(err.Error(), "SQLSTATE 23505") {
+			return nil, ErrStatusCodeExists
+		}
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return status, nil
+}
+
+// UpdateStatus changes a status in a board's own workflow
+func (r *WorkflowRepository) UpdateStatus(ctx context.Context, boardID uuid.UUID, statusID int32, input *models.UpdateStatusInput) (*models.TaskStatus, error) {
+	status, err := scanTaskStatus(r.db.QueryRow(ctx, `
+		UPDATE task_statuses
+		SET
+			code = COALESCE($1, code),
+			name = COALESCE($2, name),
+			description = COALESCE($3, description),
+			color = COALESCE($4, color),
+			icon = COALESCE($5, icon),
+			category = COALESCE($6, category),
+			display_order = COALESCE($7, display_order)
+		WHERE id = $8 AND board_id = $9
+		RETURNING `+taskStatusColumns,
+		input.Code,
+		input.Name,
+		input.Description,
+		input.Color,
+		input.Icon,
+		input.Category,
+		input.DisplayOrder,
+		statusID,
+		boardID,
+	))
+	if err != nil {
+		if strings.Contains(err.Error(), "SQLSTATE 23505") {
+			return nil, ErrStatusCodeExists
+		}
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// DeleteStatus removes a status from a board's own workflow along with its transitions
+func (r *WorkflowRepository) DeleteStatus(ctx context.Context, boardID uuid.UUID, statusID int32) error {
+	result, err := r.db.Exec(ctx, `
+		DELETE FROM task_statuses
+		WHERE id = $1 AND board_id = $2
+	`, statusID, boardID)
+	if err != nil {
+		if strings.Contains(err.Error(), "SQLSTATE 23503") {
+			return ErrStatusInUse
+		}
+		return fmt.Errorf("error deleting status: %v", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListTransitions retrieves a board's allowed status transitions
+func (r *WorkflowRepository) ListTransitions(ctx context.Context, boardID uuid.UUID) ([]models.StatusTransition, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT bst.from_status_id, bst.to_status_id
+		FROM board_status_transitions bst
+		JOIN task_statuses f ON f.id = bst.from_status_id
+		JOIN task_statuses t ON t.id = bst.to_status_id
+		WHERE bst.board_id = $1
+		ORDER BY f.display_order, t.display_order
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transitions: %v", err)
+	}
+	defer rows.Close()
+
+	transitions := make([]models.StatusTransition, 0)
+	for rows.Next() {
+		var transition models.StatusTransition
+		if err := rows.Scan(&transition.FromStatusID, &transition.ToStatusID); err != nil {
+			return nil, fmt.Errorf("error scanning transition: %v", err)
+		}
+		transitions = append(transitions, transition)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transitions: %v", err)
+	}
+
+	return transitions, nil
+}
+
+// SetTransitions replaces a board's transition matrix. Every status in it must
+// be part of the board's workflow.
+func (r *WorkflowRepository) SetTransitions(ctx context.Context, boardID uuid.UUID, transitions []models.StatusTransition) ([]models.StatusTransition, error) {
+	// Start transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	fromIDs := make([]int32, 0, len(transitions))
+	toIDs := make([]int32, 0, len(transitions))
+	for _, transition := range transitions {
+		if transition.FromStatusID == transition.ToStatusID {
+			return nil, ErrInvalidInput
+		}
+		for _, statusID := range []int32{transition.FromStatusID, transition.ToStatusID} {
+			if err := validateWorkflowStatus(ctx, tx, &boardID, statusID); err != nil {
+				return nil, err
+			}
+		}
+		fromIDs = append(fromIDs, transition.FromStatusID)
+		toIDs = append(toIDs, transition.ToStatusID)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM board_status_transitions WHERE board_id = $1`, boardID); err != nil {
+		return nil, fmt.Errorf("error clearing transitions: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO board_status_transitions (board_id, from_status_id, to_status_id)
+		SELECT $1, v.from_status_id, v.to_status_id
+		FROM unnest($2::int[], $3::int[]) AS v(from_status_id, to_status_id)
+		ON CONFLICT DO NOTHING
+	`, boardID, fromIDs, toIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error setting transitions: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return r.ListTransitions(ctx, boardID)
+}
+
+// ListWIPLimits retrieves the WIP limits set on a board's columns
+func (r *WorkflowRepository) ListWIPLimits(ctx context.Context, boardID uuid.UUID) ([]models.WIPLimit, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT w.board_id, w.status_id, w.limit_count, w.mode, w.created_at, w.updated_at
+		FROM board_wip_limits w
+		JOIN task_statuses s ON s.id = w.status_id
+		WHERE w.board_id = $1
+		ORDER BY s.display_order, s.id
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing WIP limits: %v", err)
+	}
+	defer rows.Close()
+
+	limits := make([]models.WIPLimit, 0)
+	for rows.Next() {
+		limit, err := scanWIPLimit(rows)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, *limit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating WIP limits: %v", err)
+	}
+
+	return limits, nil
+}
+
+// SetWIPLimit creates or replaces the WIP limit on one of a board's columns
+func (r *WorkflowRepository) SetWIPLimit(ctx context.Context, boardID uuid.UUID, statusID int32, input *models.SetWIPLimitInput) (*models.WIPLimit, error) {
+	if err := validateWorkflowStatus(ctx, r.db, &boardID, statusID); err != nil {
+		return nil, err
+	}
+
+	mode := input.Mode
+	if mode == "" {
+		mode = models.WIPLimitSoft
+	}
+
+	return scanWIPLimit(r.db.QueryRow(ctx, `
+		INSERT INTO board_wip_limits (board_id, status_id, limit_count, mode)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (board_id, status_id) DO UPDATE SET
+			limit_count = EXCLUDED.limit_count,
+			mode = EXCLUDED.mode
+		RETURNING board_id, status_id, limit_count, mode, created_at, updated_at
+	`, boardID, statusID, input.Limit, mode))
+}
+
+// DeleteWIPLimit removes the WIP limit from one of a board's columns
+func (r *WorkflowRepository) DeleteWIPLimit(ctx context.Context, boardID uuid.UUID, statusID int32) error {
+	result, err := r.db.Exec(ctx, `
+		DELETE FROM board_wip_limits
+		WHERE board_id = $1 AND status_id = $2
+	`, boardID, statusID)
+	if err != nil {
+		return fmt.Errorf("error deleting WIP limit: %v", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// listWorkflowStatuses returns a board's own statuses, or the global statuses
+// when the board has none or boardID is nil
+func listWorkflowStatuses(ctx context.Context, db *pgxpool.Pool, boardID *uuid.UUID) ([]models.TaskStatus, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+taskStatusColumns+`
+		FROM task_statuses s
+		WHERE s.board_id = $1 OR (
+			s.board_id IS NULL AND
+			NOT EXISTS (SELECT 1 FROM task_statuses b WHERE b.board_id = $1)
+		)
+		ORDER BY display_order, id
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing task statuses: %v", err)
+	}
+	defer rows.Close()
+
+	statuses := make([]models.TaskStatus, 0)
+	for rows.Next() {
+		status, err := scanTaskStatus(rows)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task statuses: %v", err)
+	}
+
+	return statuses, nil
+}
+
+// statusInWorkflow reports whether a status belongs to the workflow a board
+// uses. Tasks without a board use the global workflow.
+func statusInWorkflow(ctx context.Context, q queryRower, boardID *uuid.UUID, statusID int32) (bool, error) {
+	var ok bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM task_statuses s
+			WHERE s.id = $2 AND (
+				s.board_id = $1 OR (
+					s.board_id IS NULL AND
+					NOT EXISTS (SELECT 1 FROM task_statuses b WHERE b.board_id = $1)
+				)
+			)
+		)
+	`, boardID, statusID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("error checking workflow status: %v", err)
+	}
+	return ok, nil
+}
+
+// validateWorkflowStatus returns ErrInvalidStatus unless the status belongs to
+// the workflow the board uses
+func validateWorkflowStatus(ctx context.Context, q queryRower, boardID *uuid.UUID, statusID int32) error {
+	ok, err := statusInWorkflow(ctx, q, boardID, statusID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidStatus
+	}
+	return nil
+}
+
+// checkStatusTransition returns ErrTransitionNotAllowed when the board
+// restricts transitions and doesn't allow moving from one status to the
+// other. Tasks left in a status from outside the workflow, for example after
+// the board replaced the global statuses, can move to any workflow status.
+func checkStatusTransition(ctx context.Context, q queryRower, boardID *uuid.UUID, fromStatusID, toStatusID int32) error {
+	if boardID == nil {
+		return nil
+	}
+
+	inWorkflow, err := statusInWorkflow(ctx, q, boardID, fromStatusID)
+	if err != nil {
+		return err
+	}
+	if !inWorkflow {
+		return nil
+	}
+
+	var allowed bool
+	err = q.QueryRow(ctx, `
+		SELECT
+			NOT EXISTS (SELECT 1 FROM board_status_transitions WHERE board_id = $1) OR
+			EXISTS (
+				SELECT 1
+				FROM board_status_transitions
+				WHERE board_id = $1 AND from_status_id = $2 AND to_status_id = $3
+			)
+	`, boardID, fromStatusID, toStatusID).Scan(&allowed)
+	if err != nil {
+		return fmt.Errorf("error checking status transition: %v", err)
+	}
+
+	if !allowed {
+		return ErrTransitionNotAllowed
+	}
+	return nil
+}
+
+// enforceWIPLimit returns a WIPLimitError when a task is about to enter a
+// column that's already at its hard limit. It has to run before the task is
+// written so the task isn't counted yet; locking the limit row keeps
+// concurrent moves from both squeezing into the last slot.
+func enforceWIPLimit(ctx context.Context, tx pgx.Tx, boardID *uuid.UUID, statusID int32) error {
+	if boardID == nil {
+		return nil
+	}
+
+	var limit int32
+	var mode models.WIPLimitMode
+	err := tx.QueryRow(ctx, `
+		SELECT limit_count, mode
+		FROM board_wip_limits
+		WHERE board_id = $1 AND status_id = $2
+		FOR UPDATE
+	`, boardID, statusID).Scan(&limit, &mode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("error getting WIP limit: %v", err)
+	}
+
+	if mode != models.WIPLimitHard {
+		return nil
+	}
+
+	var count int32
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*)::int
+		FROM tasks
+		WHERE board_id = $1 AND status_id = $2
+	`, boardID, statusID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("error counting column tasks: %v", err)
+	}
+
+	if count >= limit {
+		return &WIPLimitError{StatusID: statusID, Limit: limit, Count: count}
+	}
+	return nil
+}
+
+// columnsForBoard returns the columns of a board's workflow with their task
+// counts and WIP limits
+func columnsForBoard(ctx context.Context, db *pgxpool.Pool, boardID uuid.UUID) ([]models.BoardColumn, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			s.id,
+			s.code,
+			s.name,
+			s.category,
+			(SELECT COUNT(*) FROM tasks t WHERE t.board_id = $1 AND t.status_id = s.id)::int,
+			w.limit_count,
+			w.mode
+		FROM task_statuses s
+		LEFT JOIN board_wip_limits w ON w.board_id = $1 AND w.status_id = s.id
+		WHERE s.board_id = $1 OR (
+			s.board_id IS NULL AND
+			NOT EXISTS (SELECT 1 FROM task_statuses b WHERE b.board_id = $1)
+		)
+		ORDER BY s.display_order, s.id
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting board columns: %v", err)
+	}
+	defer rows.Close()
+
+	columns := make([]models.BoardColumn, 0)
+	for rows.Next() {
+		var column models.BoardColumn
+		err := rows.Scan(
+			&column.StatusID,
+			&column.Code,
+			&column.Name,
+			&column.Category,
+			&column.TaskCount,
+			&column.WIPLimit,
+			&column.WIPMode,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning board column: %v", err)
+		}
+		column.OverLimit = column.WIPLimit != nil && column.TaskCount > *column.WIPLimit
+		columns = append(columns, column)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating board columns: %v", err)
+	}
+
+	return columns, nil
+}
+
+// scanWIPLimit scans a WIP limit row, mapping a missing row to ErrNotFound
+func scanWIPLimit(row pgx.Row) (*models.WIPLimit, error) {
+	var limit models.WIPLimit
+	err := row.Scan(
+		&limit.BoardID,
+		&limit.StatusID,
+		&limit.Limit,
+		&limit.Mode,
+		&limit.CreatedAt,
+		&limit.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error scanning WIP limit: %v", err)
+	}
+
+	return &limit, nil
+}
+
+// scanTaskStatus scans a row selected with taskStatusColumns
+func scanTaskStatus(row pgx.Row) (*models.TaskStatus, error) {
+	var status models.TaskStatus
+	err := row.Scan(
+		&status.ID,
+		&status.BoardID,
+		&status.Code,
+		&status.Name,
+		&status.Description,
+		&status.Color,
+		&status.Icon,
+		&status.Category,
+		&status.DisplayOrder,
+		&status.CreatedAt,
+		&status.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error scanning task status: %v", err)
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestEnforceWIPLimit(t *testing.T) {
+	boardID := uuid.New()
+	limit := func(n int32, mode models.WIPLimitMode) fakeRow { return fakeRow{values: []any{n, mode}} }
+	count := func(n int32) fakeRow { return fakeRow{values: []any{n}} }
+
+	tests := []struct {
+		name    string
+		boardID *uuid.UUID
+		rows    []fakeRow
+		want    *WIPLimitError
+	}{
+		{"task without a board", nil, nil, nil},
+		{"column without a limit", &boardID, []fakeRow{{err: pgx.ErrNoRows}}, nil},
+		{"soft limit", &boardID, []fakeRow{limit(2, models.WIPLimitSoft)}, nil},
+		{"hard limit with room", &boardID, []fakeRow{limit(3, models.WIPLimitHard), count(2)}, nil},
+		{"hard limit reached", &boardID, []fakeRow{limit(3, models.WIPLimitHard), count(3)}, &WIPLimitError{StatusID: 2, Limit: 3, Count: 3}},
+		{"hard limit exceeded", &boardID, []fakeRow{limit(1, models.WIPLimitHard), count(4)}, &WIPLimitError{StatusID: 2, Limit: 1, Count: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{queryRow: rowsInOrder(tt.rows...)}
+			err := enforceWIPLimit(context.Background(), tx, tt.boardID, 2)
+
+			var wipErr *WIPLimitError
+			if errors.As(err, &wipErr) {
+				if !reflect.DeepEqual(wipErr, tt.want) {
+					t.Errorf("enforceWIPLimit() = %+v, want %+v", wipErr, tt.want)
+				}
+				return
+			}
+			if err != nil || tt.want != nil {
+				t.Errorf("enforceWIPLimit() = %v, want %+v", err, tt.want)
+			}
+		})
+	}
+}
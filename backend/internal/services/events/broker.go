@@ -0,0 +1,170 @@
+// Package events fans board change notifications out to the streams and
+// sockets open on this replica
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres notification channels. The payload is always a board ID.
+const (
+	// ActivityChannel announces new rows in a board's activity feed
+	ActivityChannel = "board_activity"
+	// PresenceChannel announces changes to a board's sessions and edit locks
+	PresenceChannel = "board_presence"
+)
+
+// channels are the notification channels the broker listens on
+var channels = []string{ActivityChannel, PresenceChannel}
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Broker listens for board notifications on one dedicated connection and
+// wakes up every subscriber of the channel and board they're about.
+// Because every replica listens to the same channel, a change written by
+// any replica reaches streams held open by all of them.
+type Broker struct {
+	pool *pgxpool.Pool
+
+	mu          sync.Mutex
+	subscribers map[topic]map[chan struct{}]struct{}
+	start       sync.Once
+}
+
+// topic is one board on one notification channel
+type topic struct {
+	channel string
+	boardID uuid.UUID
+}
+
+// NewBroker creates a new board event broker
+func NewBroker(pool *pgxpool.Pool) *Broker {
+	return &Broker{
+		pool:        pool,
+		subscribers: make(map[topic]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives a value whenever a board is
+// announced on a notification channel, along with a function that ends the
+// subscription. Wake-ups are coalesced, so a subscriber should read
+// everything new on each one.
+func (b *Broker) Subscribe(channel string, boardID uuid.UUID) (<-chan struct{}, func()) {
+	b.start.Do(func() {
+		go b.Run(context.Background())
+	})
+
+	key := topic{channel: channel, boardID: boardID}
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.subscribers[key] == nil {
+		b.subscribers[key] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[key][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers[key], ch)
+		if len(b.subscribers[key]) == 0 {
+			delete(b.subscribers, key)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// Run listens for notifications until ctx is cancelled, reconnecting when
+// the connection drops
+func (b *Broker) Run(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Board event listener stopped: %v; reconnecting in %s", err, delay)
+
+		// Notifications may have been missed while disconnected
+		b.notifyAll()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// listen holds a connection on the channels and dispatches notifications
+// until it fails
+func (b *Broker) listen(ctx context.Context) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection is left listening, so take it out of the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	for _, channel := range channels {
+		if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+			return err
+		}
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		boardID, err := uuid.Parse(notification.Payload)
+		if err != nil {
+			continue
+		}
+		b.notify(topic{channel: notification.Channel, boardID: boardID})
+	}
+}
+
+// notify wakes up a topic's subscribers without blocking on slow ones
+func (b *Broker) notify(key topic) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[key] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// notifyAll wakes up every subscriber
+func (b *Broker) notifyAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+const (
+	// MaxEmailAttempts is how many times a notification email is tried
+	// before it's given up on
+	MaxEmailAttempts = 5
+
+	// baseEmailRetryDelay is the wait after the first failed attempt; it
+	// doubles with every attempt after that
+	baseEmailRetryDelay = time.Minute
+
+	// dueSoonWindow is how far ahead of its due date a task's assignee is
+	// reminded about it
+	dueSoonWindow = 24 * time.Hour
+
+	pollInterval = 30 * time.Second
+	batchSize    = 20
+	sendTimeout  = 30 * time.Second
+)
+
+// NotifierConfig configures a Notifier
+type NotifierConfig struct {
+	// AppURL is the frontend's base URL, used for links in emails
+	AppURL string
+	// DigestHour is the hour of the day, in UTC, daily digests go out
+	DigestHour int
+}
+
+// Notifier emails pending notifications, creates due-soon reminders and
+// sends daily digests. Several notifiers can run against the same database.
+type Notifier struct {
+	repo   *repository.NotificationRepository
+	mailer Mailer
+	config NotifierConfig
+}
+
+// NewNotifier creates a new notifier
+func NewNotifier(pool *pgxpool.Pool, mailer Mailer, config NotifierConfig) *Notifier {
+	config.AppURL = strings.TrimRight(config.AppURL, "/")
+	return &Notifier{
+		repo:   repository.NewNotificationRepository(pool),
+		mailer: mailer,
+		config: config,
+	}
+}
+
+// Run sends email until ctx is cancelled
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := n.repo.CreateDueSoonNotifications(ctx, dueSoonWindow); err != nil {
+			log.Printf("Failed to create due soon notifications: %v", err)
+		}
+		n.sendDueEmails(ctx)
+		n.sendDigests(ctx, time.Now().UTC())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDueEmails claims and sends one batch of notification emails
+func (n *Notifier) sendDueEmails(ctx context.Context) {
+	emails, err := n.repo.ClaimDueEmails(ctx, batchSize, 2*sendTimeout)
+	if err != nil {
+		log.Printf("Failed to claim notification emails: %v", err)
+		return
+	}
+
+	for _, email := range emails {
+		err := n.sendNotification(ctx, email)
+		if err == nil {
+			if err := n.repo.MarkEmailSent(ctx, email.ID); err != nil {
+				log.Printf("Failed to record notification email %s: %v", email.ID, err)
+			}
+			continue
+		}
+
+		log.Printf("Failed to email notification %s: %v", email.ID, err)
+		var nextAttemptAt *time.Time
+		if attempts := email.Attempts + 1; attempts < MaxEmailAttempts {
+			next := time.Now().Add(baseEmailRetryDelay << (attempts - 1))
+			nextAttemptAt = &next
+		}
+		if err := n.repo.MarkEmailFailed(ctx, email.ID, nextAttemptAt); err != nil {
+			log.Printf("Failed to record notification email %s: %v", email.ID, err)
+		}
+	}
+}
+
+// notificationEmailData is what notification email templates are rendered with
+type notificationEmailData struct {
+	Name        string
+	ActorName   string
+	TaskTitle   string
+	TaskURL     string
+	DueDate     *time.Time
+	Excerpt     string
+	SettingsURL string
+}
+
+// sendNotification renders and sends one notification email
+func (n *Notifier) sendNotification(ctx context.Context, email models.PendingNotificationEmail) error {
+	var payload struct {
+		TaskTitle string     `json:"task_title"`
+		DueDate   *time.Time `json:"due_date"`
+		Excerpt   string     `json:"excerpt"`
+	}
+	if len(email.Data) > 0 {
+		if err := json.Unmarshal(email.Data, &payload); err != nil {
+			return fmt.Errorf("error unmarshaling notification data: %v", err)
+		}
+	}
+
+	data := notificationEmailData{
+		Name:        email.FullName,
+		TaskTitle:   payload.TaskTitle,
+		DueDate:     payload.DueDate,
+		Excerpt:     payload.Excerpt,
+		SettingsURL: n.config.AppURL + "/settings",
+	}
+	if email.ActorName != nil {
+		data.ActorName = *email.ActorName
+	}
+	if email.TaskID != nil && email.BoardSlug != nil {
+		data.TaskURL = n.taskURL(*email.BoardSlug, email.TaskID.String())
+	}
+
+	var subject string
+	switch email.Type {
+	case models.NotificationTaskAssigned:
+		subject = "You were assigned to " + data.TaskTitle
+	case models.NotificationTaskDueSoon:
+		subject = "Due soon: " + data.TaskTitle
+	case models.NotificationMentioned:
+		subject = "You were mentioned on " + data.TaskTitle
+		if data.ActorName != "" {
+			subject = data.ActorName + " mentioned you on " + data.TaskTitle
+		}
+	default:
+		return fmt.Errorf("notification type %s isn't emailed", email.Type)
+	}
+
+	html, text, err := render(email.Type, data)
+	if err != nil {
+		return err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	return n.mailer.Send(sendCtx, Message{To: email.Email, Subject: subject, HTML: html, Text: text})
+}
+
+// digestTaskData is a task as listed in a digest email
+type digestTaskData struct {
+	models.DigestTask
+	URL string
+}
+
+// digestEmailData is what digest email templates are rendered with. The
+// More counts are the tasks left off a capped list.
+type digestEmailData struct {
+	Name            string
+	Overdue         []digestTaskData
+	DueThisWeek     []digestTaskData
+	MoreOverdue     int
+	MoreDueThisWeek int
+	SettingsURL     string
+}
+
+// sendDigests sends today's digests once the digest hour has come. Each
+// user's digest is claimed before it's sent, so a digest that fails to send
+// is skipped for the day rather than sent twice.
+func (n *Notifier) sendDigests(ctx context.Context, now time.Time) {
+	if now.Hour() < n.config.DigestHour {
+		return
+	}
+
+	for {
+		userIDs, err := n.repo.ClaimDigests(ctx, now, batchSize)
+		if err != nil {
+			log.Printf("Failed to claim digests: %v", err)
+			return
+		}
+		if len(userIDs) == 0 {
+			return
+		}
+
+		for _, userID := range userIDs {
+			digest, err := n.repo.GetDigest(ctx, userID, now)
+			if err != nil {
+				log.Printf("Failed to build digest for %s: %v", userID, err)
+				continue
+			}
+			if err := n.sendDigest(ctx, digest); err != nil {
+				log.Printf("Failed to send digest to %s: %v", userID, err)
+			}
+		}
+	}
+}
+
+// sendDigest renders and sends a digest. Users with nothing overdue or due
+// this week aren't emailed.
+func (n *Notifier) sendDigest(ctx context.Context, digest *models.Digest) error {
+	if digest.OverdueCount == 0 && digest.DueThisWeekCount == 0 {
+		return nil
+	}
+
+	data := digestEmailData{
+		Name:            digest.FullName,
+		Overdue:         n.digestTasks(digest.Overdue),
+		DueThisWeek:     n.digestTasks(digest.DueThisWeek),
+		MoreOverdue:     max(digest.OverdueCount-len(digest.Overdue), 0),
+		MoreDueThisWeek: max(digest.DueThisWeekCount-len(digest.DueThisWeek), 0),
+		SettingsURL:     n.config.AppURL + "/settings",
+	}
+
+	subject := fmt.Sprintf("Your tasks: %d overdue, %d due this week", digest.OverdueCount, digest.DueThisWeekCount)
+	html, text, err := render(models.NotificationDailyDigest, data)
+	if err != nil {
+		return err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	return n.mailer.Send(sendCtx, Message{To: digest.Email, Subject: subject, HTML: html, Text: text})
+}
+
+// digestTasks adds links to a digest's tasks
+func (n *Notifier) digestTasks(tasks []models.DigestTask) []digestTaskData {
+	data := make([]digestTaskData, 0, len(tasks))
+	for _, task := range tasks {
+		data = append(data, digestTaskData{
+			DigestTask: task,
+			URL:        n.taskURL(task.BoardSlug, task.ID.String()),
+		})
+	}
+	return data
+}
+
+// taskURL links to a task in the frontend
+func (n *Notifier) taskURL(boardSlug string, taskID string) string {
+	return n.config.AppURL + "/b/" + boardSlug + "/" + taskID
+}
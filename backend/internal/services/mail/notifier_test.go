@@ -0,0 +1,109 @@
+package mail
+
+import (
+	"context"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+// readOnlyEmail parses the single .eml file a FileMailer wrote to dir
+func readOnlyEmail(t *testing.T, dir string) (*mail.Message, string) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("wrote %d emails, want 1", len(files))
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("error parsing email: %v", err)
+	}
+	return msg, string(data)
+}
+
+func TestSendDigestReportsTotalsOfCappedLists(t *testing.T) {
+	dir := t.TempDir()
+	n := &Notifier{
+		mailer: NewFileMailer(dir, "vTasker <no-reply@example.com>"),
+		config: NotifierConfig{AppURL: "https://app.example.com"},
+	}
+
+	due := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	task := models.DigestTask{
+		ID:         uuid.New(),
+		Title:      "Ship the release",
+		BoardName:  "Platform",
+		BoardSlug:  "platform",
+		StatusName: "In Progress",
+		DueDate:    due,
+	}
+	digest := &models.Digest{
+		UserID:           uuid.New(),
+		Email:            "ana@example.com",
+		FullName:         "Ana",
+		Overdue:          []models.DigestTask{task},
+		OverdueCount:     250,
+		DueThisWeek:      []models.DigestTask{},
+		DueThisWeekCount: 0,
+	}
+
+	if err := n.sendDigest(context.Background(), digest); err != nil {
+		t.Fatalf("sendDigest() error = %v", err)
+	}
+
+	msg, raw := readOnlyEmail(t, dir)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Your tasks: 250 overdue, 0 due this week"; subject != want {
+		t.Errorf("subject = %q, want %q", subject, want)
+	}
+	if to := msg.Header.Get("To"); to != "ana@example.com" {
+		t.Errorf("To = %q, want ana@example.com", to)
+	}
+
+	// Bodies are quoted-printable, but these lines don't need escaping
+	for _, want := range []string{
+		"Ship the release",
+		"https://app.example.com/b/platform/" + task.ID.String(),
+		"...and 249 more",
+	} {
+		if !strings.Contains(raw, want) {
+			t.Errorf("email doesn't contain %q", want)
+		}
+	}
+	if strings.Contains(raw, "Due this week") {
+		t.Error("email lists an empty due this week section")
+	}
+}
+
+func TestSendDigestSkipsEmptyDigests(t *testing.T) {
+	dir := t.TempDir()
+	n := &Notifier{mailer: NewFileMailer(dir, "no-reply@example.com")}
+
+	digest := &models.Digest{Email: "ana@example.com", Overdue: []models.DigestTask{}, DueThisWeek: []models.DigestTask{}}
+	if err := n.sendDigest(context.Background(), digest); err != nil {
+		t.Fatalf("sendDigest() error = %v", err)
+	}
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.eml")); len(files) != 0 {
+		t.Errorf("wrote %d emails for an empty digest, want none", len(files))
+	}
+}
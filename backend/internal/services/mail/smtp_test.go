@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSMTPMailerClosesConnectionWhenContextEnds(t *testing.T) {
+	// A server that accepts connections but never greets the client
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Read returns once the mailer closes its end
+		conn.Read(make([]byte, 1))
+		close(closed)
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := NewSMTPMailer(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: addr.Port,
+		From: "no-reply@example.com",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err = mailer.Send(ctx, Message{To: "ana@example.com", Subject: "Hi", Text: "Hi", HTML: "<p>Hi</p>"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the mailer left its connection open")
+	}
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templateFuncs = map[string]any{
+	"formatDate": formatDate,
+}
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.html.tmpl"))
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.txt.tmpl"))
+)
+
+// render executes the HTML and text templates for an email
+func render(name string, data any) (string, string, error) {
+	var html, text bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return "", "", fmt.Errorf("error rendering %s email: %v", name, err)
+	}
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return "", "", fmt.Errorf("error rendering %s email: %v", name, err)
+	}
+	return html.String(), text.String(), nil
+}
+
+// formatDate formats a time or time pointer for an email, in UTC
+func formatDate(value any) string {
+	switch t := value.(type) {
+	case time.Time:
+		return t.UTC().Format("Mon Jan 2, 15:04 UTC")
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format("Mon Jan 2, 15:04 UTC")
+	default:
+		return ""
+	}
+}
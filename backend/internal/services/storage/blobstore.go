@@ -0,0 +1,73 @@
+// Package storage stores uploaded files
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// ErrBlobNotFound is returned when a blob doesn't exist in the store
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobInfo describes a blob being stored
+type BlobInfo struct {
+	Size        int64
+	ContentType string
+	// SHA256 is the hex-encoded digest of the content
+	SHA256 string
+}
+
+// BlobStore stores blobs under opaque keys. Keys are chosen by the caller
+// and use / as a separator.
+type BlobStore interface {
+	// Put stores a blob, replacing any blob with the same key
+	Put(ctx context.Context, key string, body io.Reader, info BlobInfo) error
+	// Get opens a blob for reading
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob. Deleting a missing blob isn't an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStoreFromEnv creates the store chosen by STORAGE_DRIVER: "local",
+// the default, keeps blobs under STORAGE_DIR and "s3" keeps them in
+// S3_BUCKET on any S3-compatible service
+func NewBlobStoreFromEnv() (BlobStore, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "./data/blobs"
+		}
+		return NewLocalStore(dir), nil
+	case "s3":
+		config := S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}
+		if config.Bucket == "" {
+			return nil, fmt.Errorf("S3_BUCKET is required for the s3 storage driver")
+		}
+		if config.Region == "" {
+			config.Region = "us-east-1"
+		}
+		if config.Endpoint == "" {
+			config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+		}
+		if pathStyle := os.Getenv("S3_USE_PATH_STYLE"); pathStyle != "" {
+			var err error
+			if config.UsePathStyle, err = strconv.ParseBool(pathStyle); err != nil {
+				return nil, fmt.Errorf("invalid S3_USE_PATH_STYLE: %v", err)
+			}
+		}
+		return NewS3Store(config)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+)
+
+const (
+	// ThumbnailSize is the longest edge of a thumbnail, in pixels
+	ThumbnailSize = 320
+
+	// maxThumbnailPixels caps the images that get thumbnails, since decoding
+	// takes several bytes per pixel whatever the file's size
+	maxThumbnailPixels = 25_000_000
+
+	thumbnailJPEGQuality = 80
+)
+
+// thumbnail is an encoded thumbnail image
+type thumbnail struct {
+	data        []byte
+	contentType string
+	width       int
+	height      int
+}
+
+// makeThumbnail decodes an image and scales it down to fit in a
+// ThumbnailSize square. Opaque thumbnails are encoded as JPEG and those with
+// transparency as PNG. Animated GIFs use their first frame.
+func makeThumbnail(data []byte) (*thumbnail, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errImageUnsupported, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxThumbnailPixels {
+		return nil, fmt.Errorf("%w: image is %dx%d", errImageUnsupported, config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errImageUnsupported, err)
+	}
+
+	bounds := src.Bounds()
+	width, height := thumbnailDimensions(bounds.Dx(), bounds.Dy(), ThumbnailSize)
+	dst := resizeImage(src, width, height)
+
+	result := &thumbnail{width: width, height: height}
+	var buf bytes.Buffer
+	if dst.Opaque() {
+		result.contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailJPEGQuality})
+	} else {
+		result.contentType = "image/png"
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error encoding thumbnail: %v", err)
+	}
+	result.data = buf.Bytes()
+
+	return result, nil
+}
+
+// thumbnailDimensions fits width by height into a size square, keeping the
+// aspect ratio. Images that already fit aren't scaled up.
+func thumbnailDimensions(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, max(height*size/width, 1)
+	}
+	return max(width*size/height, 1), size
+}
+
+// resizeImage scales src down to width by height by averaging the block of
+// source pixels behind each destination pixel. Neither dimension may be
+// larger than the source's.
+func resizeImage(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	// Convert to RGBA first; image/draw has fast paths from the decoders'
+	// formats and it leaves the loop below with plain premultiplied bytes
+	rgba, ok := src.(*image.RGBA)
+	if !ok || rgba.Rect.Min != (image.Point{}) {
+		rgba = image.NewRGBA(image.Rect(0, 0, srcWidth, srcHeight))
+		draw.Draw(rgba, rgba.Rect, src, bounds.Min, draw.Src)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, (y+1)*srcHeight/height
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, (x+1)*srcWidth/width
+
+			var r, g, b, a uint64
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[rgba.PixOffset(x0, sy):rgba.PixOffset(x1, sy)]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					b += uint64(row[i+2])
+					a += uint64(row[i+3])
+				}
+			}
+
+			n := uint64((x1 - x0) * (y1 - y0))
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8((r + n/2) / n)
+			dst.Pix[i+1] = uint8((g + n/2) / n)
+			dst.Pix[i+2] = uint8((b + n/2) / n)
+			dst.Pix[i+3] = uint8((a + n/2) / n)
+		}
+	}
+
+	return dst
+}
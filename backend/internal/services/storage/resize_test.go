@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnailDimensions(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantW, wantH  int
+	}{
+		{"landscape", 1600, 900, 320, 180},
+		{"portrait", 900, 1600, 180, 320},
+		{"square", 1000, 1000, 320, 320},
+		{"already fits", 200, 100, 200, 100},
+		{"exactly fits", 320, 320, 320, 320},
+		{"tiny is not upscaled", 1, 1, 1, 1},
+		{"one edge too long", 321, 10, 320, 9},
+		{"odd size rounds down", 1001, 333, 320, 106},
+		{"very wide keeps one row", 10000, 2, 320, 1},
+		{"very tall keeps one column", 3, 5000, 1, 320},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := thumbnailDimensions(tt.width, tt.height, ThumbnailSize)
+			if w != tt.wantW || h != tt.wantH {
+				t.Errorf("thumbnailDimensions(%d, %d) = %dx%d, want %dx%d", tt.width, tt.height, w, h, tt.wantW, tt.wantH)
+			}
+			if w > tt.width || h > tt.height {
+				t.Errorf("thumbnailDimensions(%d, %d) = %dx%d scales the image up", tt.width, tt.height, w, h)
+			}
+		})
+	}
+}
+
+func TestResizeImageAveragesBlocks(t *testing.T) {
+	// A 4x2 image whose left half is black and right half is white
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.RGBA{A: 255}
+			if x >= 2 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	dst := resizeImage(src, 2, 1)
+	if got := dst.RGBAAt(0, 0); got != (color.RGBA{A: 255}) {
+		t.Errorf("left pixel = %v, want black", got)
+	}
+	if got := dst.RGBAAt(1, 0); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("right pixel = %v, want white", got)
+	}
+
+	// Averaging the whole image mixes the halves
+	dst = resizeImage(src, 1, 1)
+	if got := dst.RGBAAt(0, 0); got != (color.RGBA{R: 128, G: 128, B: 128, A: 255}) {
+		t.Errorf("single pixel = %v, want mid grey", got)
+	}
+}
+
+func TestResizeImageOddSizes(t *testing.T) {
+	// Source bounds that don't start at the origin and don't divide evenly
+	src := image.NewNRGBA(image.Rect(5, 7, 5+333, 7+101))
+	for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+		for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+			src.Set(x, y, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+		}
+	}
+
+	width, height := thumbnailDimensions(333, 101, 100)
+	if width != 100 || height != 30 {
+		t.Fatalf("thumbnailDimensions() = %dx%d, want 100x30", width, height)
+	}
+
+	dst := resizeImage(src, width, height)
+	if dst.Rect != image.Rect(0, 0, 100, 30) {
+		t.Fatalf("resized bounds = %v, want 100x30 at the origin", dst.Rect)
+	}
+	// Every destination pixel covers at least one source pixel, so a flat
+	// image stays flat
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if got := dst.RGBAAt(x, y); got != (color.RGBA{R: 10, G: 20, B: 30, A: 255}) {
+				t.Fatalf("pixel (%d, %d) = %v, want the source color", x, y, got)
+			}
+		}
+	}
+}
+
+func TestResizeImageKeepsTransparency(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	dst := resizeImage(src, 5, 5)
+	if dst.Opaque() {
+		t.Error("a transparent image resized to an opaque one")
+	}
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+const (
+	sweepInterval = time.Minute
+	sweepBatch    = 50
+	deleteTimeout = 30 * time.Second
+
+	// maxSweepRetryDelay caps the wait between attempts at removing a blob
+	maxSweepRetryDelay = 6 * time.Hour
+)
+
+// Sweeper removes the blobs of deleted attachments from storage. Deleting an
+// attachment, or the task or board it's on, queues its blob in the database,
+// so blobs are cleaned up however the row went away. Several sweepers can
+// run against the same database.
+type Sweeper struct {
+	repo  *repository.AttachmentRepository
+	store BlobStore
+}
+
+// NewSweeper creates a new blob sweeper
+func NewSweeper(pool *pgxpool.Pool, store BlobStore) *Sweeper {
+	return &Sweeper{
+		repo:  repository.NewAttachmentRepository(pool, 0),
+		store: store,
+	}
+}
+
+// Run removes queued blobs until ctx is cancelled
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep claims and removes one batch of blobs
+func (s *Sweeper) sweep(ctx context.Context) {
+	deletions, err := s.repo.ClaimBlobDeletions(ctx, sweepBatch, sweepBatch*deleteTimeout)
+	if err != nil {
+		log.Printf("Failed to claim blob deletions: %v", err)
+		return
+	}
+
+	for _, deletion := range deletions {
+		deleteCtx, cancel := context.WithTimeout(ctx, deleteTimeout)
+		err := s.store.Delete(deleteCtx, deletion.StorageKey)
+		cancel()
+
+		if err == nil {
+			err = s.repo.CompleteBlobDeletion(ctx, deletion.ID)
+		} else {
+			log.Printf("Failed to delete blob %s: %v", deletion.StorageKey, err)
+			delay := time.Minute << min(deletion.Attempts, 16)
+			err = s.repo.RetryBlobDeletion(ctx, deletion.ID, time.Now().Add(min(delay, maxSweepRetryDelay)))
+		}
+		if err != nil {
+			log.Printf("Failed to record blob deletion %d: %v", deletion.ID, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+const (
+	thumbnailInterval = 5 * time.Second
+	thumbnailBatch    = 10
+	thumbnailTimeout  = time.Minute
+
+	// maxThumbnailAttempts is how many times a thumbnail is tried before
+	// it's marked failed
+	maxThumbnailAttempts = 5
+
+	// maxThumbnailRetryDelay caps the wait between attempts at a thumbnail
+	maxThumbnailRetryDelay = time.Hour
+)
+
+// errImageUnsupported marks images that will never get a thumbnail, so
+// they aren't retried
+var errImageUnsupported = errors.New("image can't be thumbnailed")
+
+// thumbnailTypes are the content types the standard library can decode
+var thumbnailTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// CanThumbnail reports whether attachments of the content type get a
+// thumbnail
+func CanThumbnail(contentType string) bool {
+	return thumbnailTypes[contentType]
+}
+
+// ThumbnailKey is the key a thumbnail is stored under, next to its original
+func ThumbnailKey(storageKey string) string {
+	return storageKey + ".thumb"
+}
+
+// Thumbnailer generates thumbnails for image attachments in the background.
+// Several thumbnailers can run against the same database; each attachment is
+// claimed by one of them at a time.
+type Thumbnailer struct {
+	repo  *repository.AttachmentRepository
+	store BlobStore
+}
+
+// NewThumbnailer creates a new thumbnailer
+func NewThumbnailer(pool *pgxpool.Pool, store BlobStore) *Thumbnailer {
+	return &Thumbnailer{
+		repo:  repository.NewAttachmentRepository(pool, 0),
+		store: store,
+	}
+}
+
+// Run generates pending thumbnails until ctx is cancelled
+func (t *Thumbnailer) Run(ctx context.Context) {
+	ticker := time.NewTicker(thumbnailInterval)
+	defer ticker.Stop()
+
+	for {
+		t.generateDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// generateDue claims and generates one batch of thumbnails. They're made one
+// at a time to bound the memory that decoding takes.
+func (t *Thumbnailer) generateDue(ctx context.Context) {
+	jobs, err := t.repo.ClaimThumbnails(ctx, thumbnailBatch, thumbnailBatch*thumbnailTimeout)
+	if err != nil {
+		log.Printf("Failed to claim thumbnails: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		jobCtx, cancel := context.WithTimeout(ctx, thumbnailTimeout)
+		err := t.generate(jobCtx, job)
+		cancel()
+		if err == nil {
+			continue
+		}
+
+		log.Printf("Failed to generate thumbnail for attachment %s: %v", job.AttachmentID, err)
+		var nextAttemptAt *time.Time
+		if !errors.Is(err, errImageUnsupported) && job.Attempts+1 < maxThumbnailAttempts {
+			delay := min(time.Minute<<job.Attempts, maxThumbnailRetryDelay)
+			next := time.Now().Add(delay)
+			nextAttemptAt = &next
+		}
+		if err := t.repo.FailThumbnail(ctx, job.AttachmentID, nextAttemptAt); err != nil {
+			log.Printf("Failed to record thumbnail failure for attachment %s: %v", job.AttachmentID, err)
+		}
+	}
+}
+
+// generate makes and stores one attachment's thumbnail
+func (t *Thumbnailer) generate(ctx context.Context, job models.ThumbnailJob) error {
+	body, err := t.store.Get(ctx, job.StorageKey)
+	if err != nil {
+		if errors.Is(err, ErrBlobNotFound) {
+			return fmt.Errorf("%w: %v", errImageUnsupported, err)
+		}
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(body, job.Size))
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("error reading image: %v", err)
+	}
+
+	thumbnail, err := makeThumbnail(data)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(thumbnail.data)
+	key := ThumbnailKey(job.StorageKey)
+	err = t.store.Put(ctx, key, bytes.NewReader(thumbnail.data), BlobInfo{
+		Size:        int64(len(thumbnail.data)),
+		ContentType: thumbnail.contentType,
+		SHA256:      hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return fmt.Errorf("error storing thumbnail: %v", err)
+	}
+
+	recorded, err := t.repo.CompleteThumbnail(ctx, job.AttachmentID, key, thumbnail.contentType, thumbnail.width, thumbnail.height)
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		// The attachment was deleted while its thumbnail was being made
+		if err := t.store.Delete(ctx, key); err != nil {
+			log.Printf("Failed to remove orphaned thumbnail %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+// Package webhooks delivers queued webhook events to their subscribers
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+	"github.com/rafaelzasas/vtasker/backend/internal/repository"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it's dead-lettered
+	MaxAttempts = 8
+
+	// baseRetryDelay is the wait after the first failed attempt; it doubles
+	// with every attempt after that
+	baseRetryDelay = 30 * time.Second
+
+	// maxRetryDelay caps the wait between attempts
+	maxRetryDelay = 6 * time.Hour
+
+	pollInterval   = 5 * time.Second
+	batchSize      = 20
+	requestTimeout = 10 * time.Second
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-VTasker-Event"
+	HeaderDelivery  = "X-VTasker-Delivery"
+	HeaderTimestamp = "X-VTasker-Timestamp"
+	HeaderSignature = "X-VTasker-Signature"
+)
+
+// Dispatcher sends due deliveries from the webhook outbox. Several
+// dispatchers can run against the same database; each delivery is claimed
+// by one of them at a time.
+type Dispatcher struct {
+	repo   *repository.WebhookRepository
+	client *http.Client
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(pool *pgxpool.Pool) *Dispatcher {
+	return &Dispatcher{
+		repo:   repository.NewWebhookRepository(pool),
+		client: newClient(),
+	}
+}
+
+// Delivery errors recorded on the delivery log. They're fixed strings so
+// nothing an endpoint sends back, and nothing about the network the
+// dispatcher runs in, is shown to board admins.
+var (
+	errBlockedAddress = errors.New("destination address is not allowed")
+	errRequestFailed  = errors.New("request failed")
+	errBadStatus      = errors.New("endpoint returned a non-2xx status")
+)
+
+// newClient returns the HTTP client deliveries are sent with. Webhook URLs
+// are chosen by board admins, so the client refuses to connect to internal
+// addresses and doesn't follow redirects, which could point anywhere.
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: requestTimeout,
+		// Checked on the resolved address at connect time, so a hostname
+		// that re-resolves to an internal address is still refused
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return errBlockedAddress
+			}
+			if ip := net.ParseIP(host); ip == nil || IsBlockedIP(ip) {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would make the connection on our behalf, past the dial check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   requestTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// blockedNets are IPv4 ranges that aren't public but that the net.IP methods
+// don't cover: "this network", which reaches the local host on some systems,
+// and shared address space, which cloud providers use for internal services
+var blockedNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// IsBlockedIP reports whether ip is an address webhooks can't be sent to:
+// loopback, private, shared, link-local, multicast or unspecified
+func IsBlockedIP(ip net.IP) bool {
+	if ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() {
+		return true
+	}
+	for _, blocked := range blockedNets {
+		if blocked.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run sends due deliveries until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatchDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchDue claims and sends one batch of due deliveries
+func (d *Dispatcher) dispatchDue(ctx context.Context) {
+	// Claim deliveries for longer than a send can take so a slow endpoint
+	// isn't sent the same delivery twice
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, batchSize, 2*requestTimeout)
+	if err != nil {
+		log.Printf("Failed to claim webhook deliveries: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery models.PendingWebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+// deliver makes one attempt at a delivery and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.PendingWebhookDelivery) {
+	responseStatus, err := d.send(ctx, delivery)
+	if err == nil {
+		if err := d.repo.MarkDelivered(ctx, delivery.ID, *responseStatus); err != nil {
+			log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	var nextAttemptAt *time.Time
+	attempts := delivery.Attempts + 1
+	if attempts < MaxAttempts {
+		next := time.Now().Add(RetryDelay(attempts))
+		nextAttemptAt = &next
+	}
+
+	if err := d.repo.MarkFailed(ctx, delivery.ID, responseStatus, err.Error(), nextAttemptAt); err != nil {
+		log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// send POSTs a delivery's payload. It returns the response status when there
+// was a response, and one of the fixed delivery errors unless the status
+// was 2xx.
+func (d *Dispatcher) send(ctx context.Context, delivery models.PendingWebhookDelivery) (*int, error) {
+	if !models.IsValidWebhookURL(delivery.URL) {
+		return nil, errBlockedAddress
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		log.Printf("Invalid webhook request for delivery %s: %v", delivery.ID, err)
+		return nil, errRequestFailed
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "vTasker-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedAddress) {
+			return nil, errBlockedAddress
+		}
+		log.Printf("Webhook delivery %s failed: %v", delivery.ID, err)
+		return nil, errRequestFailed
+	}
+	// The body is never read; only the status is recorded
+	resp.Body.Close()
+
+	status := resp.StatusCode
+	if status < 200 || status > 299 {
+		return &status, errBadStatus
+	}
+
+	return &status, nil
+}
+
+// Sign returns the signature header value for a payload: the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the webhook's secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// RetryDelay returns how long to wait after a delivery's nth failed attempt
+func RetryDelay(attempt int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
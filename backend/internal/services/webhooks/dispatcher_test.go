@@ -0,0 +1,145 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rafaelzasas/vtasker/backend/internal/models"
+)
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"::ffff:127.0.0.1", true},
+		{"224.0.0.1", true},
+		{"0.1.2.3", true},
+		{"0.255.255.255", true},
+		{"::ffff:0.1.2.3", true},
+		{"100.64.0.1", true},
+		{"100.100.100.200", true},
+		{"100.127.255.255", true},
+		{"::ffff:100.64.0.1", true},
+		{"100.63.255.255", false},
+		{"100.128.0.1", false},
+		{"1.0.0.1", false},
+		{"93.184.216.34", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsBlockedIP(net.ParseIP(tt.ip)); got != tt.blocked {
+			t.Errorf("IsBlockedIP(%s) = %v, want %v", tt.ip, got, tt.blocked)
+		}
+	}
+}
+
+func TestSendRefusesInternalAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	d := &Dispatcher{client: newClient()}
+	status, err := d.send(context.Background(), models.PendingWebhookDelivery{
+		ID:      uuid.New(),
+		URL:     server.URL,
+		Payload: []byte(`{}`),
+	})
+	if !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("send() error = %v, want %v", err, errBlockedAddress)
+	}
+	if status != nil {
+		t.Errorf("send() status = %d, want none", *status)
+	}
+	if called {
+		t.Error("send() reached a loopback server")
+	}
+}
+
+func TestSendRefusesNonHTTPSchemes(t *testing.T) {
+	d := &Dispatcher{client: newClient()}
+	for _, rawURL := range []string{"file:///etc/passwd", "gopher://example.com", "ftp://example.com/x"} {
+		if _, err := d.send(context.Background(), models.PendingWebhookDelivery{URL: rawURL}); !errors.Is(err, errBlockedAddress) {
+			t.Errorf("send(%q) error = %v, want %v", rawURL, err, errBlockedAddress)
+		}
+	}
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	via := httptest.NewRequest(http.MethodPost, "http://example.com/hook", nil)
+	next := httptest.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data", nil)
+	if err := newClient().CheckRedirect(next, []*http.Request{via}); !errors.Is(err, http.ErrUseLastResponse) {
+		t.Errorf("CheckRedirect() = %v, want %v", err, http.ErrUseLastResponse)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Expected signatures computed independently with
+	// printf '%s' "<timestamp>.<body>" | openssl dgst -sha256 -hmac "<secret>"
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"payload", `{"event":"task.created","board_id":"42"}`, "sha256=361baa8cfd1f623d923195467a25a28c860f4ad5d0c73dc6d8c0a618573cf46c"},
+		{"empty body", "", "sha256=5967f3c560522fa40cf2876ebc3c3a08551dd6959aaade3b413460591895bdcc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign("whsec_test", 1700000000, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if Sign("whsec_test", 1700000001, []byte("{}")) == Sign("whsec_test", 1700000000, []byte("{}")) {
+		t.Error("Sign() doesn't cover the timestamp")
+	}
+	if Sign("whsec_other", 1700000000, []byte("{}")) == Sign("whsec_test", 1700000000, []byte("{}")) {
+		t.Error("Sign() doesn't cover the secret")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 8 * time.Minute},
+		{8, 64 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{12, 6 * time.Hour},
+		{1000, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := RetryDelay(tt.attempt); got != tt.want {
+			t.Errorf("RetryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,446 @@
+// Package taskquery parses the task filter language used by GET /tasks?q=
+// and saved views, for example:
+//
+//	status:in-progress assignee:me due<7d label:backend -type:chore
+//
+// A query is a list of terms that must all match. A term is a field, an
+// operator and a value, or free text searched in the task's text. Words that
+// only look like a term, such as "fix:" or "http://example.com", are free
+// text. Any term can be negated with a leading -.
+package taskquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// maxTerms caps the number of terms in a query
+const maxTerms = 50
+
+// Field is what a term filters on
+type Field string
+
+const (
+	// FieldText searches the task's title, description, notes and comments
+	FieldText     Field = ""
+	FieldStatus   Field = "status"
+	FieldCategory Field = "category"
+	FieldPriority Field = "priority"
+	FieldType     Field = "type"
+	FieldLabel    Field = "label"
+	FieldAssignee Field = "assignee"
+	FieldOwner    Field = "owner"
+	FieldBoard    Field = "board"
+	FieldParent   Field = "parent"
+	FieldDue      Field = "due"
+	FieldCreated  Field = "created"
+	FieldUpdated  Field = "updated"
+)
+
+// fields are the fields that can be named in a query
+var fields = map[Field]bool{
+	FieldStatus:   true,
+	FieldCategory: true,
+	FieldPriority: true,
+	FieldType:     true,
+	FieldLabel:    true,
+	FieldAssignee: true,
+	FieldOwner:    true,
+	FieldBoard:    true,
+	FieldParent:   true,
+	FieldDue:      true,
+	FieldCreated:  true,
+	FieldUpdated:  true,
+}
+
+// IsDate reports whether the field holds a date and can be compared
+func (f Field) IsDate() bool {
+	return f == FieldDue || f == FieldCreated || f == FieldUpdated
+}
+
+// Op is how a term compares its field to its values
+type Op string
+
+const (
+	OpEqual        Op = ":"
+	OpLess         Op = "<"
+	OpLessEqual    Op = "<="
+	OpGreater      Op = ">"
+	OpGreaterEqual Op = ">="
+)
+
+// None is the value that matches tasks without a value for the field, as in
+// assignee:none
+const None = "none"
+
+// Me is the value that stands for the user running the query
+const Me = "me"
+
+// Term is one condition of a query
+type Term struct {
+	Field   Field
+	Op      Op
+	Negated bool
+	// Values are the alternatives the field can match; a text term has one.
+	// Values are lowercased for every field but text.
+	Values []string
+	// From and To bound a date term's value as a half-open range: a whole day
+	// for dates, a single instant otherwise
+	From time.Time
+	To   time.Time
+	// Phrase is set for quoted text terms, whose words must appear in order
+	Phrase bool
+	// Pos is the term's offset in the query, in characters
+	Pos int
+}
+
+// Query is a parsed query. Its terms must all match.
+type Query struct {
+	Terms []Term
+}
+
+// ParseError describes where and why a query couldn't be parsed
+type ParseError struct {
+	// Pos is the offset of the problem in the query, in characters
+	Pos     int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s (at position %d)", e.Message, e.Pos)
+}
+
+// Parse parses a query. Relative dates such as due<7d are resolved against
+// the current time, so a stored query should be parsed again each time it's
+// run.
+func Parse(input string) (*Query, error) {
+	p := &parser{input: []rune(input), now: time.Now().UTC()}
+	return p.parse()
+}
+
+type parser struct {
+	input []rune
+	pos   int
+	now   time.Time
+}
+
+func (p *parser) parse() (*Query, error) {
+	query := &Query{Terms: make([]Term, 0)}
+
+	for {
+		p.skipSpace()
+		if p.done() {
+			return query, nil
+		}
+		if len(query.Terms) == maxTerms {
+			return nil, p.errorf(p.pos, "too many terms; a query can have at most %d", maxTerms)
+		}
+
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		query.Terms = append(query.Terms, *term)
+	}
+}
+
+// parseTerm parses one term starting at the current position
+func (p *parser) parseTerm() (*Term, error) {
+	term := &Term{Pos: p.pos}
+
+	if p.peek() == '-' && p.pos+1 < len(p.input) && !unicode.IsSpace(p.input[p.pos+1]) {
+		term.Negated = true
+		p.pos++
+	}
+
+	if p.peek() == '"' {
+		text, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, p.errorf(term.Pos, "empty quoted text")
+		}
+		term.Field = FieldText
+		term.Op = OpEqual
+		term.Values = []string{text}
+		term.Phrase = true
+		return term, nil
+	}
+
+	// A field name is a known field followed by an operator; anything else
+	// is a word of free text
+	nameStart := p.pos
+	for !p.done() && (unicode.IsLetter(p.peek()) || p.peek() == '_') {
+		p.pos++
+	}
+	field := Field(strings.ToLower(string(p.input[nameStart:p.pos])))
+
+	op, hasOp := p.parseOp()
+	if !hasOp || !fields[field] {
+		p.pos = nameStart
+		word := p.readUntilSpace()
+		term.Field = FieldText
+		term.Op = OpEqual
+		term.Values = []string{word}
+		return term, nil
+	}
+	term.Field = field
+	term.Op = op
+
+	if op != OpEqual && !field.IsDate() {
+		return nil, p.errorf(nameStart, "%s can't be compared with %s; use %s:value", field, op, field)
+	}
+
+	valuesPos := p.pos
+	values, err := p.parseValues()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, p.errorf(valuesPos, "missing value for %s", field)
+	}
+
+	if field.IsDate() {
+		if len(values) > 1 {
+			return nil, p.errorf(valuesPos, "%s takes a single value", field)
+		}
+		if err := p.resolveDate(term, values[0], valuesPos); err != nil {
+			return nil, err
+		}
+		return term, nil
+	}
+
+	for i, value := range values {
+		values[i] = strings.ToLower(value)
+		if err := validateValue(field, values[i]); err != nil {
+			return nil, p.errorf(valuesPos, "%s", err)
+		}
+	}
+	term.Values = values
+
+	return term, nil
+}
+
+// parseOp reads the operator after a field name, if there is one
+func (p *parser) parseOp() (Op, bool) {
+	switch p.peek() {
+	case ':':
+		p.pos++
+		return OpEqual, true
+	case '<', '>':
+		op := Op(p.input[p.pos])
+		p.pos++
+		if p.peek() == '=' {
+			op += "="
+			p.pos++
+		}
+		return op, true
+	}
+	return "", false
+}
+
+// parseValues reads a comma-separated list of values, each bare or quoted
+func (p *parser) parseValues() ([]string, error) {
+	var values []string
+	for {
+		var value string
+		if p.peek() == '"' {
+			quoted, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			value = quoted
+		} else {
+			start := p.pos
+			for !p.done() && !unicode.IsSpace(p.peek()) && p.peek() != ',' {
+				p.pos++
+			}
+			value = string(p.input[start:p.pos])
+		}
+		if value == "" {
+			if len(values) > 0 {
+				return nil, p.errorf(p.pos, "missing value after comma")
+			}
+			return nil, nil
+		}
+		values = append(values, value)
+
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+
+	if !p.done() && !unicode.IsSpace(p.peek()) {
+		return nil, p.errorf(p.pos, "unexpected %q after value", p.peek())
+	}
+	return values, nil
+}
+
+// parseQuoted reads a double-quoted string. A backslash escapes the next
+// character.
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+
+	var text strings.Builder
+	for !p.done() {
+		r := p.input[p.pos]
+		p.pos++
+		switch r {
+		case '\\':
+			if p.done() {
+				return "", p.errorf(start, "unterminated quote")
+			}
+			text.WriteRune(p.input[p.pos])
+			p.pos++
+		case '"':
+			return text.String(), nil
+		default:
+			text.WriteRune(r)
+		}
+	}
+	return "", p.errorf(start, "unterminated quote")
+}
+
+// readUntilSpace reads the rest of a word
+func (p *parser) readUntilSpace() string {
+	start := p.pos
+	for !p.done() && !unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// resolveDate sets a date term's range from its value: none, a date
+// (2025-01-31), today, yesterday or tomorrow, or for comparisons now or an
+// offset from now such as 7d, -2w or 12h
+func (p *parser) resolveDate(term *Term, value string, pos int) error {
+	value = strings.ToLower(value)
+
+	if value == None {
+		if term.Field != FieldDue || term.Op != OpEqual {
+			return p.errorf(pos, "%s%snone isn't supported; use due:none for tasks without a due date", term.Field, term.Op)
+		}
+		term.Values = []string{None}
+		return nil
+	}
+
+	if day, ok := p.parseDay(value); ok {
+		term.From = day
+		term.To = day.AddDate(0, 0, 1)
+		term.Values = []string{value}
+		return nil
+	}
+
+	instant, ok := p.parseInstant(value)
+	if !ok {
+		return p.errorf(pos, "invalid date %q; use a date like 2025-01-31, today, or an offset like 7d, -2w or 12h", value)
+	}
+	if term.Op == OpEqual {
+		return p.errorf(pos, "%s: needs a date like 2025-01-31 or today; use %s<%s or %s>%s to compare with %s", term.Field, term.Field, value, term.Field, value, value)
+	}
+
+	// Postgres keeps microseconds, so this is the instant as a range
+	term.From = instant
+	term.To = instant.Add(time.Microsecond)
+	term.Values = []string{value}
+	return nil
+}
+
+// parseDay parses a calendar date into the start of that day in UTC
+func (p *parser) parseDay(value string) (time.Time, bool) {
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, time.UTC)
+	switch value {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// parseInstant parses now or an offset from now: a signed whole number of
+// hours (h), days (d) or weeks (w)
+func (p *parser) parseInstant(value string) (time.Time, bool) {
+	if value == "now" {
+		return p.now, true
+	}
+	if len(value) < 2 {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < -10000 || n > 10000 {
+		return time.Time{}, false
+	}
+	switch value[len(value)-1] {
+	case 'h':
+		return p.now.Add(time.Duration(n) * time.Hour), true
+	case 'd':
+		return p.now.AddDate(0, 0, n), true
+	case 'w':
+		return p.now.AddDate(0, 0, 7*n), true
+	}
+	return time.Time{}, false
+}
+
+// validateValue checks a lowercased value for a field that isn't a date
+func validateValue(field Field, value string) error {
+	switch field {
+	case FieldCategory:
+		if value != "todo" && value != "in_progress" && value != "done" {
+			return fmt.Errorf("invalid category %q; use todo, in_progress or done", value)
+		}
+	case FieldBoard, FieldParent:
+		if value == None {
+			return nil
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			return fmt.Errorf("invalid %s ID %q", field, value)
+		}
+	case FieldOwner:
+		if value == None {
+			return fmt.Errorf("every task has an owner; owner:none never matches")
+		}
+	case FieldStatus, FieldPriority, FieldType:
+		if value == None {
+			return fmt.Errorf("every task has a %s; %s:none never matches", field, field)
+		}
+	}
+	return nil
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) *ParseError {
+	return &ParseError{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,207 @@
+package taskquery
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// now is the time relative dates are resolved against in these tests
+var now = time.Date(2025, 3, 14, 15, 30, 0, 0, time.UTC)
+
+func parseAt(input string) (*Query, error) {
+	p := &parser{input: []rune(input), now: now}
+	return p.parse()
+}
+
+func TestParse(t *testing.T) {
+	today := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		input string
+		want  []Term
+	}{
+		{
+			name:  "empty",
+			input: "   ",
+			want:  []Term{},
+		},
+		{
+			name:  "field",
+			input: "status:in_progress",
+			want:  []Term{{Field: FieldStatus, Op: OpEqual, Values: []string{"in_progress"}}},
+		},
+		{
+			name:  "field names and values are case-insensitive",
+			input: "Priority:HIGH",
+			want:  []Term{{Field: FieldPriority, Op: OpEqual, Values: []string{"high"}}},
+		},
+		{
+			name:  "alternatives",
+			input: "priority:high,critical",
+			want:  []Term{{Field: FieldPriority, Op: OpEqual, Values: []string{"high", "critical"}}},
+		},
+		{
+			name:  "quoted value",
+			input: `label:"Needs Review",backend`,
+			want:  []Term{{Field: FieldLabel, Op: OpEqual, Values: []string{"needs review", "backend"}}},
+		},
+		{
+			name:  "me and none",
+			input: "assignee:me,none",
+			want:  []Term{{Field: FieldAssignee, Op: OpEqual, Values: []string{"me", "none"}}},
+		},
+		{
+			name:  "free text keeps its case",
+			input: "Login bug",
+			want: []Term{
+				{Field: FieldText, Op: OpEqual, Values: []string{"Login"}},
+				{Field: FieldText, Op: OpEqual, Values: []string{"bug"}, Pos: 6},
+			},
+		},
+		{
+			name:  "phrase",
+			input: `"login page" status:done`,
+			want: []Term{
+				{Field: FieldText, Op: OpEqual, Values: []string{"login page"}, Phrase: true},
+				{Field: FieldStatus, Op: OpEqual, Values: []string{"done"}, Pos: 13},
+			},
+		},
+		{
+			name:  "escaped quote",
+			input: `"say \"hi\""`,
+			want:  []Term{{Field: FieldText, Op: OpEqual, Values: []string{`say "hi"`}, Phrase: true}},
+		},
+		{
+			name:  "unknown field is free text",
+			input: "fix: login",
+			want: []Term{
+				{Field: FieldText, Op: OpEqual, Values: []string{"fix:"}},
+				{Field: FieldText, Op: OpEqual, Values: []string{"login"}, Pos: 5},
+			},
+		},
+		{
+			name:  "URL is free text",
+			input: "http://x.com",
+			want:  []Term{{Field: FieldText, Op: OpEqual, Values: []string{"http://x.com"}}},
+		},
+		{
+			name:  "comparison on an unknown field is free text",
+			input: "size<10",
+			want:  []Term{{Field: FieldText, Op: OpEqual, Values: []string{"size<10"}}},
+		},
+		{
+			name:  "negation applies to one term",
+			input: "-type:chore label:backend",
+			want: []Term{
+				{Field: FieldType, Op: OpEqual, Values: []string{"chore"}, Negated: true},
+				{Field: FieldLabel, Op: OpEqual, Values: []string{"backend"}, Pos: 12},
+			},
+		},
+		{
+			name:  "negated text and phrase",
+			input: `-wontfix -"out of scope"`,
+			want: []Term{
+				{Field: FieldText, Op: OpEqual, Values: []string{"wontfix"}, Negated: true},
+				{Field: FieldText, Op: OpEqual, Values: []string{"out of scope"}, Negated: true, Phrase: true, Pos: 9},
+			},
+		},
+		{
+			name:  "lone dash is text",
+			input: "a - b",
+			want: []Term{
+				{Field: FieldText, Op: OpEqual, Values: []string{"a"}},
+				{Field: FieldText, Op: OpEqual, Values: []string{"-"}, Pos: 2},
+				{Field: FieldText, Op: OpEqual, Values: []string{"b"}, Pos: 4},
+			},
+		},
+		{
+			name:  "whole day",
+			input: "due:today",
+			want: []Term{{
+				Field: FieldDue, Op: OpEqual, Values: []string{"today"},
+				From: today, To: today.AddDate(0, 0, 1),
+			}},
+		},
+		{
+			name:  "date",
+			input: "created>=2025-01-31",
+			want: []Term{{
+				Field: FieldCreated, Op: OpGreaterEqual, Values: []string{"2025-01-31"},
+				From: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name:  "offset",
+			input: "due<7d updated>-2w",
+			want: []Term{
+				{
+					Field: FieldDue, Op: OpLess, Values: []string{"7d"},
+					From: now.AddDate(0, 0, 7), To: now.AddDate(0, 0, 7).Add(time.Microsecond),
+				},
+				{
+					Field: FieldUpdated, Op: OpGreater, Values: []string{"-2w"},
+					From: now.AddDate(0, 0, -14), To: now.AddDate(0, 0, -14).Add(time.Microsecond), Pos: 7,
+				},
+			},
+		},
+		{
+			name:  "no due date",
+			input: "due:none",
+			want:  []Term{{Field: FieldDue, Op: OpEqual, Values: []string{"none"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := parseAt(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(query.Terms, tt.want) {
+				t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", tt.input, query.Terms, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		pos     int
+		message string
+	}{
+		{`"unterminated`, 0, "unterminated quote"},
+		{`label:"x`, 6, "unterminated quote"},
+		{`""`, 0, "empty quoted text"},
+		{"status:", 7, "missing value for status"},
+		{"label:a,", 8, "missing value after comma"},
+		{`label:"a"b`, 9, `unexpected 'b' after value`},
+		{"priority>high", 0, "priority can't be compared with >; use priority:value"},
+		{"category:open", 9, `invalid category "open"; use todo, in_progress or done`},
+		{"board:abc", 6, `invalid board ID "abc"`},
+		{"owner:none", 6, "every task has an owner; owner:none never matches"},
+		{"x status:none", 9, "every task has a status; status:none never matches"},
+		{"due:soon", 4, `invalid date "soon"; use a date like 2025-01-31, today, or an offset like 7d, -2w or 12h`},
+		{"due:7d", 4, "due: needs a date like 2025-01-31 or today; use due<7d or due>7d to compare with 7d"},
+		{"due<today,tomorrow", 4, "due takes a single value"},
+		{"created:none", 8, "created:none isn't supported; use due:none for tasks without a due date"},
+		{strings.Repeat("a ", maxTerms+1), 2 * maxTerms, "too many terms; a query can have at most 50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseAt(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want a ParseError", tt.input, err)
+			}
+			if parseErr.Pos != tt.pos || parseErr.Message != tt.message {
+				t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.input, parseErr.Message, parseErr.Pos, tt.message, tt.pos)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+-- Create task comments table
+CREATE TABLE task_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES task_comments(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create task comment edits table
+CREATE TABLE task_comment_edits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    comment_id UUID NOT NULL REFERENCES task_comments(id) ON DELETE CASCADE,
+    previous_body TEXT NOT NULL,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_task_comments_task_id ON task_comments(task_id);
+
+CREATE INDEX idx_task_comments_parent_id ON task_comments(parent_id);
+
+CREATE INDEX idx_task_comments_author_id ON task_comments(author_id);
+
+CREATE INDEX idx_task_comment_edits_comment_id ON task_comment_edits(comment_id);
+
+-- Create triggers
+CREATE TRIGGER update_task_comments_updated_at BEFORE
+UPDATE
+    ON task_comments FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,28 @@
+-- Restore free-text task labels
+ALTER TABLE
+    task_labels DROP CONSTRAINT IF EXISTS unique_task_label,
+ADD
+    COLUMN label VARCHAR(50);
+
+UPDATE
+    task_labels tl
+SET
+    label = bl.name
+FROM
+    board_labels bl
+WHERE
+    bl.id = tl.label_id;
+
+ALTER TABLE
+    task_labels
+ALTER COLUMN
+    label
+SET
+    NOT NULL,
+    DROP COLUMN label_id;
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_board_labels_updated_at ON board_labels;
+
+-- Drop tables
+DROP TABLE IF EXISTS board_labels;
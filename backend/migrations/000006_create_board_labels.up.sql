@@ -0,0 +1,83 @@
+-- Create board labels table
+CREATE TABLE board_labels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#6B7280',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Label names are unique per board regardless of case, the same way the
+-- label filter matches them
+CREATE UNIQUE INDEX unique_board_label_name ON board_labels(board_id, LOWER(name));
+
+-- Create label definitions for the free-text labels already in use. Labels
+-- differing only in case become one label, keeping the spelling that sorts
+-- first.
+INSERT INTO
+    board_labels (board_id, name)
+SELECT
+    DISTINCT ON (t.board_id, LOWER(tl.label)) t.board_id,
+    tl.label
+FROM
+    task_labels tl
+    JOIN tasks t ON t.id = tl.task_id
+WHERE
+    t.board_id IS NOT NULL
+ORDER BY
+    t.board_id,
+    LOWER(tl.label),
+    tl.label ON CONFLICT DO NOTHING;
+
+-- Point task labels at their definitions
+ALTER TABLE
+    task_labels
+ADD
+    COLUMN label_id UUID REFERENCES board_labels(id) ON DELETE CASCADE;
+
+UPDATE
+    task_labels tl
+SET
+    label_id = bl.id
+FROM
+    tasks t,
+    board_labels bl
+WHERE
+    t.id = tl.task_id
+    AND bl.board_id = t.board_id
+    AND LOWER(bl.name) = LOWER(tl.label);
+
+-- Labels on tasks without a board have no definition to point at
+DELETE FROM
+    task_labels
+WHERE
+    label_id IS NULL;
+
+-- Remove duplicate assignments before adding the unique constraint
+DELETE FROM
+    task_labels a USING task_labels b
+WHERE
+    a.task_id = b.task_id
+    AND a.label_id = b.label_id
+    AND a.id > b.id;
+
+ALTER TABLE
+    task_labels
+ALTER COLUMN
+    label_id
+SET
+    NOT NULL,
+    DROP COLUMN label,
+ADD
+    CONSTRAINT unique_task_label UNIQUE (task_id, label_id);
+
+-- Create indexes
+CREATE INDEX idx_board_labels_board_id ON board_labels(board_id);
+
+CREATE INDEX idx_task_labels_label_id ON task_labels(label_id);
+
+-- Create triggers
+CREATE TRIGGER update_board_labels_updated_at BEFORE
+UPDATE
+    ON board_labels FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_tasks_parent_id;
+
+ALTER TABLE
+    tasks DROP CONSTRAINT IF EXISTS check_task_not_own_parent;
+
+ALTER TABLE
+    tasks DROP CONSTRAINT IF EXISTS tasks_parent_id_fkey,
+ADD
+    CONSTRAINT tasks_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES tasks(id);
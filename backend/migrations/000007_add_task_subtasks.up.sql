@@ -0,0 +1,15 @@
+-- Promote subtasks to top-level tasks when their parent is deleted
+ALTER TABLE
+    tasks DROP CONSTRAINT IF EXISTS tasks_parent_id_fkey,
+ADD
+    CONSTRAINT tasks_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES tasks(id) ON DELETE
+SET
+    NULL;
+
+-- A task can't be its own parent
+ALTER TABLE
+    tasks
+ADD
+    CONSTRAINT check_task_not_own_parent CHECK (parent_id <> id);
+
+CREATE INDEX idx_tasks_parent_id ON tasks(parent_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS done_gate_overrides;
+
+DROP TABLE IF EXISTS done_gates;
+
+ALTER TABLE
+    task_statuses DROP COLUMN IF EXISTS category;
@@ -0,0 +1,64 @@
+-- Group statuses into categories so "done" doesn't depend on a status code
+ALTER TABLE
+    task_statuses
+ADD
+    COLUMN category VARCHAR(20) NOT NULL DEFAULT 'todo' CHECK (category IN ('todo', 'in_progress', 'done'));
+
+UPDATE
+    task_statuses
+SET
+    category = CASE
+        WHEN code = 'done' THEN 'done'
+        WHEN code IN (
+            'in-progress',
+            'in_progress',
+            'review',
+            'in_review',
+            'blocked'
+        ) THEN 'in_progress'
+        ELSE 'todo'
+    END;
+
+-- Create definition-of-done gates table. A gate without a board is the global
+-- default for boards that don't configure their own.
+CREATE TABLE done_gates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id UUID REFERENCES boards(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    require_criteria BOOLEAN NOT NULL DEFAULT true,
+    require_dependencies BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create done gate overrides table
+CREATE TABLE done_gate_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    board_id UUID REFERENCES boards(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    from_status_id INTEGER REFERENCES task_statuses(id),
+    to_status_id INTEGER REFERENCES task_statuses(id),
+    reason TEXT,
+    unmet_criteria JSONB NOT NULL DEFAULT '[]',
+    blocking_tasks JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_done_gates_board_id ON done_gates(board_id)
+WHERE
+    board_id IS NOT NULL;
+
+CREATE UNIQUE INDEX idx_done_gates_global ON done_gates((board_id IS NULL))
+WHERE
+    board_id IS NULL;
+
+CREATE INDEX idx_done_gate_overrides_board_id ON done_gate_overrides(board_id);
+
+CREATE INDEX idx_done_gate_overrides_task_id ON done_gate_overrides(task_id);
+
+-- Create triggers
+CREATE TRIGGER update_done_gates_updated_at BEFORE
+UPDATE
+    ON done_gates FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
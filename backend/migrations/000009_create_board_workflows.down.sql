@@ -0,0 +1,62 @@
+DROP TRIGGER IF EXISTS update_task_statuses_updated_at ON task_statuses;
+
+DROP TABLE IF EXISTS board_status_transitions;
+
+DROP INDEX IF EXISTS idx_task_statuses_board_code;
+
+DROP INDEX IF EXISTS idx_task_statuses_global_code;
+
+-- Board statuses can't survive without their board, so move their tasks to the
+-- global status with the same code before dropping them
+UPDATE
+    tasks t
+SET
+    status_id = g.id
+FROM
+    task_statuses s,
+    task_statuses g
+WHERE
+    t.status_id = s.id
+    AND s.board_id IS NOT NULL
+    AND g.board_id IS NULL
+    AND g.code = s.code;
+
+UPDATE
+    tasks t
+SET
+    status_id = (
+        SELECT
+            g.id
+        FROM
+            task_statuses g
+        WHERE
+            g.board_id IS NULL
+            AND g.category = s.category
+        ORDER BY
+            g.display_order
+        LIMIT
+            1
+    )
+FROM
+    task_statuses s
+WHERE
+    t.status_id = s.id
+    AND s.board_id IS NOT NULL;
+
+DELETE FROM
+    task_statuses
+WHERE
+    board_id IS NOT NULL;
+
+ALTER TABLE
+    done_gate_overrides DROP CONSTRAINT done_gate_overrides_from_status_id_fkey,
+    DROP CONSTRAINT done_gate_overrides_to_status_id_fkey,
+ADD
+    CONSTRAINT done_gate_overrides_from_status_id_fkey FOREIGN KEY (from_status_id) REFERENCES task_statuses(id),
+ADD
+    CONSTRAINT done_gate_overrides_to_status_id_fkey FOREIGN KEY (to_status_id) REFERENCES task_statuses(id);
+
+ALTER TABLE
+    task_statuses DROP COLUMN IF EXISTS board_id,
+ADD
+    CONSTRAINT task_statuses_code_key UNIQUE (code);
@@ -0,0 +1,41 @@
+-- Statuses with a board belong to that board's workflow. Statuses without one
+-- are the global defaults, used by boards that don't define their own.
+ALTER TABLE
+    task_statuses
+ADD
+    COLUMN board_id UUID REFERENCES boards(id) ON DELETE CASCADE,
+    DROP CONSTRAINT task_statuses_code_key;
+
+-- Deleting a board status shouldn't be blocked by the gate override history
+ALTER TABLE
+    done_gate_overrides DROP CONSTRAINT done_gate_overrides_from_status_id_fkey,
+    DROP CONSTRAINT done_gate_overrides_to_status_id_fkey,
+ADD
+    CONSTRAINT done_gate_overrides_from_status_id_fkey FOREIGN KEY (from_status_id) REFERENCES task_statuses(id) ON DELETE SET NULL,
+ADD
+    CONSTRAINT done_gate_overrides_to_status_id_fkey FOREIGN KEY (to_status_id) REFERENCES task_statuses(id) ON DELETE SET NULL;
+
+-- Create board status transitions table. A board without any transitions
+-- allows moving tasks between any two of its statuses.
+CREATE TABLE board_status_transitions (
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    from_status_id INTEGER NOT NULL REFERENCES task_statuses(id) ON DELETE CASCADE,
+    to_status_id INTEGER NOT NULL REFERENCES task_statuses(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (board_id, from_status_id, to_status_id),
+    CONSTRAINT check_transition_changes_status CHECK (from_status_id <> to_status_id)
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_task_statuses_global_code ON task_statuses(code)
+WHERE
+    board_id IS NULL;
+
+CREATE UNIQUE INDEX idx_task_statuses_board_code ON task_statuses(board_id, code)
+WHERE
+    board_id IS NOT NULL;
+
+-- Create triggers
+CREATE TRIGGER update_task_statuses_updated_at BEFORE
+UPDATE
+    ON task_statuses FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
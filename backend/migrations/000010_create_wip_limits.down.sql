@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tasks_board_status;
+
+DROP TABLE IF EXISTS board_wip_limits;
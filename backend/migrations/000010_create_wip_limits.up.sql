@@ -0,0 +1,19 @@
+-- Create board WIP limits table. A soft limit only warns when a column goes
+-- over it; a hard limit blocks tasks from entering a full column.
+CREATE TABLE board_wip_limits (
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    status_id INTEGER NOT NULL REFERENCES task_statuses(id) ON DELETE CASCADE,
+    limit_count INTEGER NOT NULL CHECK (limit_count > 0),
+    mode VARCHAR(10) NOT NULL DEFAULT 'soft' CHECK (mode IN ('soft', 'hard')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (board_id, status_id)
+);
+
+-- Create indexes
+CREATE INDEX idx_tasks_board_status ON tasks(board_id, status_id);
+
+-- Create triggers
+CREATE TRIGGER update_board_wip_limits_updated_at BEFORE
+UPDATE
+    ON board_wip_limits FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,31 @@
+DROP INDEX IF EXISTS idx_tasks_column_order;
+
+CREATE INDEX idx_tasks_board_status ON tasks(board_id, status_id);
+
+-- Collapse ranks back to consecutive positions
+UPDATE
+    tasks t
+SET
+    order_index = ranked.position - 1
+FROM
+    (
+        SELECT
+            id,
+            ROW_NUMBER() OVER (
+                PARTITION BY board_id,
+                status_id
+                ORDER BY
+                    order_index,
+                    created_at,
+                    id
+            ) AS position
+        FROM
+            tasks
+    ) ranked
+WHERE
+    t.id = ranked.id;
+
+ALTER TABLE
+    tasks
+ALTER COLUMN
+    order_index TYPE INTEGER USING order_index::integer;
@@ -0,0 +1,37 @@
+-- Rank tasks within their column with gaps so a move only rewrites the moved task
+ALTER TABLE
+    tasks
+ALTER COLUMN
+    order_index TYPE DOUBLE PRECISION,
+ALTER COLUMN
+    order_index
+SET
+    DEFAULT 0;
+
+-- Spread existing tasks out, keeping their current order within each column
+UPDATE
+    tasks t
+SET
+    order_index = ranked.position * 1024
+FROM
+    (
+        SELECT
+            id,
+            ROW_NUMBER() OVER (
+                PARTITION BY board_id,
+                status_id
+                ORDER BY
+                    order_index,
+                    created_at,
+                    id
+            ) AS position
+        FROM
+            tasks
+    ) ranked
+WHERE
+    t.id = ranked.id;
+
+-- Create indexes. The column order index also covers column counts.
+DROP INDEX IF EXISTS idx_tasks_board_status;
+
+CREATE INDEX idx_tasks_column_order ON tasks(board_id, status_id, order_index);
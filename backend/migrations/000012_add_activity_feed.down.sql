@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_activity_logs_task_feed;
+
+DROP INDEX IF EXISTS idx_activity_logs_board_feed;
+
+ALTER TABLE
+    activity_logs DROP COLUMN IF EXISTS task_id,
+    DROP COLUMN IF EXISTS board_id,
+    DROP CONSTRAINT activity_logs_actor_id_fkey,
+ADD
+    CONSTRAINT activity_logs_actor_id_fkey FOREIGN KEY (actor_id) REFERENCES users(id);
@@ -0,0 +1,20 @@
+-- Scope activity events to a board and task so feeds don't have to look up
+-- every entity. Events outlive the task they describe.
+ALTER TABLE
+    activity_logs
+ADD
+    COLUMN board_id UUID REFERENCES boards(id) ON DELETE SET NULL,
+ADD
+    COLUMN task_id UUID,
+    DROP CONSTRAINT activity_logs_actor_id_fkey,
+ADD
+    CONSTRAINT activity_logs_actor_id_fkey FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX idx_activity_logs_board_feed ON activity_logs(board_id, created_at DESC, id DESC)
+WHERE
+    board_id IS NOT NULL;
+
+CREATE INDEX idx_activity_logs_task_feed ON activity_logs(task_id, created_at DESC, id DESC)
+WHERE
+    task_id IS NOT NULL;
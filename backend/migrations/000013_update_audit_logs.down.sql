@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_audit_logs_feed;
+
+DROP INDEX IF EXISTS idx_audit_logs_action;
+
+ALTER TABLE
+    audit_logs DROP CONSTRAINT audit_logs_admin_id_fkey,
+ADD
+    CONSTRAINT audit_logs_admin_id_fkey FOREIGN KEY (admin_id) REFERENCES users(id);
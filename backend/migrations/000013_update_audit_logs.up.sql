@@ -0,0 +1,10 @@
+-- Keep the audit trail when an admin's account is deleted
+ALTER TABLE
+    audit_logs DROP CONSTRAINT audit_logs_admin_id_fkey,
+ADD
+    CONSTRAINT audit_logs_admin_id_fkey FOREIGN KEY (admin_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX idx_audit_logs_action ON audit_logs(action);
+
+CREATE INDEX idx_audit_logs_feed ON audit_logs(created_at DESC, id DESC);
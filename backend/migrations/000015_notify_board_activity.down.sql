@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS notify_activity_logs_board_activity ON activity_logs;
+
+DROP FUNCTION IF EXISTS notify_board_activity();
+
+DROP INDEX IF EXISTS idx_activity_logs_board_seq;
+
+ALTER TABLE
+    activity_logs DROP COLUMN IF EXISTS board_seq;
+
+DROP TABLE IF EXISTS board_activity_sequences;
@@ -0,0 +1,74 @@
+-- Number each board's activity in commit order so event streams can resume
+-- without missing events. created_at is taken when an event is written, so a
+-- transaction that writes first but commits last would sort before events a
+-- client has already been sent. Writers take the next number by updating the
+-- board's counter row, which holds its lock until they commit, so numbers
+-- become visible in order and without gaps.
+CREATE TABLE board_activity_sequences (
+    board_id UUID PRIMARY KEY REFERENCES boards(id) ON DELETE CASCADE,
+    last_seq BIGINT NOT NULL
+);
+
+ALTER TABLE
+    activity_logs
+ADD
+    COLUMN board_seq BIGINT;
+
+-- Number existing events in the order they were written
+UPDATE
+    activity_logs a
+SET
+    board_seq = numbered.seq
+FROM
+    (
+        SELECT
+            id,
+            ROW_NUMBER() OVER (
+                PARTITION BY board_id
+                ORDER BY
+                    created_at,
+                    id
+            ) AS seq
+        FROM
+            activity_logs
+        WHERE
+            board_id IS NOT NULL
+    ) numbered
+WHERE
+    a.id = numbered.id;
+
+INSERT INTO
+    board_activity_sequences (board_id, last_seq)
+SELECT
+    board_id,
+    MAX(board_seq)
+FROM
+    activity_logs
+WHERE
+    board_id IS NOT NULL
+GROUP BY
+    board_id;
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_activity_logs_board_seq ON activity_logs(board_id, board_seq)
+WHERE
+    board_id IS NOT NULL;
+
+-- Announce new board activity so every API replica can push it to the
+-- board's event streams. NOTIFY is only delivered once the transaction that
+-- wrote the activity commits. The payload is just the board ID; listeners
+-- read the events themselves.
+CREATE
+OR REPLACE FUNCTION notify_board_activity() RETURNS TRIGGER AS '
+BEGIN
+    PERFORM pg_notify(''board_activity'', NEW.board_id::text);
+    RETURN NEW;
+END;
+' LANGUAGE plpgsql;
+
+-- Create triggers
+CREATE TRIGGER notify_activity_logs_board_activity
+AFTER
+INSERT
+    ON activity_logs FOR EACH ROW
+    WHEN (NEW.board_id IS NOT NULL) EXECUTE FUNCTION notify_board_activity();
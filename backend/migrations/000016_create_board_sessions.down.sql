@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS notify_task_edit_locks_presence ON task_edit_locks;
+
+DROP TRIGGER IF EXISTS notify_board_sessions_presence ON board_sessions;
+
+DROP FUNCTION IF EXISTS notify_board_presence();
+
+DROP TABLE IF EXISTS task_edit_locks;
+
+DROP TABLE IF EXISTS board_sessions;
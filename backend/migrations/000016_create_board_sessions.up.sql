@@ -0,0 +1,64 @@
+-- Create board sessions table. Each row is one open collaboration socket:
+-- who is viewing the board and which task they have open. Rows are kept
+-- fresh by a heartbeat, so sessions left behind by a crashed replica expire.
+CREATE TABLE board_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    task_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    connected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create task edit locks table. Locks are advisory: they warn other editors
+-- but don't block updates. They expire unless renewed and go away with the
+-- session that holds them.
+CREATE TABLE task_edit_locks (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    field VARCHAR(50) NOT NULL,
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    session_id UUID NOT NULL REFERENCES board_sessions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (task_id, field)
+);
+
+-- Create indexes
+CREATE INDEX idx_board_sessions_board_id ON board_sessions(board_id, last_seen_at);
+
+CREATE INDEX idx_task_edit_locks_board_id ON task_edit_locks(board_id);
+
+CREATE INDEX idx_task_edit_locks_session_id ON task_edit_locks(session_id);
+
+-- Announce presence changes so every API replica can push the board's new
+-- presence to its sockets. Heartbeats only touch last_seen_at and aren't
+-- announced.
+CREATE
+OR REPLACE FUNCTION notify_board_presence() RETURNS TRIGGER AS '
+BEGIN
+    IF TG_OP = ''DELETE'' THEN
+        PERFORM pg_notify(''board_presence'', OLD.board_id::text);
+        RETURN OLD;
+    END IF;
+    PERFORM pg_notify(''board_presence'', NEW.board_id::text);
+    RETURN NEW;
+END;
+' LANGUAGE plpgsql;
+
+-- Create triggers
+CREATE TRIGGER notify_board_sessions_presence
+AFTER
+INSERT
+    OR DELETE
+    OR
+UPDATE
+    OF task_id ON board_sessions FOR EACH ROW EXECUTE FUNCTION notify_board_presence();
+
+CREATE TRIGGER notify_task_edit_locks_presence
+AFTER
+INSERT
+    OR DELETE
+    OR
+UPDATE
+    ON task_edit_locks FOR EACH ROW EXECUTE FUNCTION notify_board_presence();
@@ -0,0 +1,36 @@
+-- Create notifications table. Notifications are written in the same
+-- transaction as the change that caused them.
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    board_id UUID REFERENCES boards(id) ON DELETE CASCADE,
+    task_id UUID REFERENCES tasks(id) ON DELETE CASCADE,
+    data JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create notification preferences table. A missing row means the user gets
+-- that type of notification.
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type)
+);
+
+-- Create indexes
+CREATE INDEX idx_notifications_feed ON notifications(user_id, created_at DESC, id DESC);
+
+CREATE INDEX idx_notifications_unread ON notifications(user_id)
+WHERE
+    read_at IS NULL;
+
+-- Create triggers
+CREATE TRIGGER update_notification_preferences_updated_at BEFORE
+UPDATE
+    ON notification_preferences FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
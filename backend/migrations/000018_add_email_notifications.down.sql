@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS notification_digests;
+
+DROP INDEX IF EXISTS idx_notifications_task_id;
+
+DROP INDEX IF EXISTS idx_notifications_email_due;
+
+ALTER TABLE
+    notification_preferences DROP COLUMN IF EXISTS email;
+
+ALTER TABLE
+    notifications DROP COLUMN IF EXISTS emailed_at,
+    DROP COLUMN IF EXISTS email_next_attempt_at,
+    DROP COLUMN IF EXISTS email_attempts,
+    DROP COLUMN IF EXISTS email_status;
@@ -0,0 +1,34 @@
+-- Track emailing notifications. Only types that are emailed, for users who
+-- want them by email, start out pending; the rest have no email status.
+ALTER TABLE
+    notifications
+ADD
+    COLUMN email_status VARCHAR(20) CHECK (email_status IN ('pending', 'sent', 'failed')),
+ADD
+    COLUMN email_attempts INTEGER NOT NULL DEFAULT 0,
+ADD
+    COLUMN email_next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ADD
+    COLUMN emailed_at TIMESTAMP WITH TIME ZONE;
+
+-- Let users turn email off per notification type
+ALTER TABLE
+    notification_preferences
+ADD
+    COLUMN email BOOLEAN NOT NULL DEFAULT true;
+
+-- Create notification digests table. A row claims a user's daily digest so
+-- it's only sent once a day however many replicas are running.
+CREATE TABLE notification_digests (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sent_on DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, sent_on)
+);
+
+-- Create indexes
+CREATE INDEX idx_notifications_email_due ON notifications(email_next_attempt_at)
+WHERE
+    email_status = 'pending';
+
+CREATE INDEX idx_notifications_task_id ON notifications(task_id, type);
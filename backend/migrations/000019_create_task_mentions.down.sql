@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS task_mentions;
+
+DROP TRIGGER IF EXISTS set_users_handle ON users;
+
+DROP FUNCTION IF EXISTS set_user_handle();
+
+DROP FUNCTION IF EXISTS generate_user_handle(TEXT, UUID);
+
+ALTER TABLE
+    users DROP CONSTRAINT IF EXISTS users_handle_key,
+    DROP COLUMN IF EXISTS handle;
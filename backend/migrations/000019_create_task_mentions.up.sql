@@ -0,0 +1,108 @@
+-- Give every user a handle so they can be @mentioned without spelling out
+-- their email. Handles come from the local part of the email address, with a
+-- number added when that's taken.
+ALTER TABLE
+    users
+ADD
+    COLUMN handle VARCHAR(50);
+
+CREATE
+OR REPLACE FUNCTION generate_user_handle(email TEXT, user_id UUID) RETURNS TEXT AS '
+DECLARE
+    base TEXT;
+    candidate TEXT;
+    suffix INTEGER := 1;
+BEGIN
+    base := LOWER(SPLIT_PART(email, ''@'', 1));
+    base := REGEXP_REPLACE(base, ''[^a-z0-9._-]'', '''', ''g'');
+    base := TRIM(BOTH ''.-'' FROM LEFT(base, 40));
+    IF base = '''' THEN
+        base := ''user'';
+    END IF;
+
+    candidate := base;
+    WHILE EXISTS (
+        SELECT 1 FROM users WHERE handle = candidate AND id <> user_id
+    ) LOOP
+        suffix := suffix + 1;
+        candidate := base || suffix::text;
+    END LOOP;
+
+    RETURN candidate;
+END;
+' LANGUAGE plpgsql;
+
+-- Backfill one user at a time so each handle sees the ones before it
+DO '
+DECLARE
+    u RECORD;
+BEGIN
+    FOR u IN SELECT id, email FROM users ORDER BY created_at, id LOOP
+        UPDATE users SET handle = generate_user_handle(u.email, u.id) WHERE id = u.id;
+    END LOOP;
+END;
+';
+
+ALTER TABLE
+    users
+ALTER COLUMN
+    handle
+SET
+    NOT NULL;
+
+ALTER TABLE
+    users
+ADD
+    CONSTRAINT users_handle_key UNIQUE (handle);
+
+CREATE
+OR REPLACE FUNCTION set_user_handle() RETURNS TRIGGER AS '
+BEGIN
+    IF NEW.handle IS NULL THEN
+        NEW.handle := generate_user_handle(NEW.email, NEW.id);
+    END IF;
+    RETURN NEW;
+END;
+' LANGUAGE plpgsql;
+
+-- Create task mentions table. Each row is a resolved @mention in a task's
+-- description, notes or implementation details, or in one of its comments.
+-- Offsets count Unicode code points into the text the mention came from.
+CREATE TABLE task_mentions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES task_comments(id) ON DELETE CASCADE,
+    field VARCHAR(50) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_offset INTEGER NOT NULL,
+    end_offset INTEGER NOT NULL,
+    mention_text VARCHAR(320) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT task_mentions_field_check CHECK (
+        field IN (
+            'description',
+            'notes',
+            'implementation_details',
+            'comment'
+        )
+    ),
+    CONSTRAINT task_mentions_comment_check CHECK ((field = 'comment') = (comment_id IS NOT NULL)),
+    CONSTRAINT task_mentions_offsets_check CHECK (
+        start_offset >= 0
+        AND end_offset > start_offset
+    )
+);
+
+-- Create indexes
+CREATE INDEX idx_task_mentions_task_id ON task_mentions(task_id, field);
+
+CREATE INDEX idx_task_mentions_comment_id ON task_mentions(comment_id)
+WHERE
+    comment_id IS NOT NULL;
+
+CREATE INDEX idx_task_mentions_user_id ON task_mentions(user_id);
+
+-- Create triggers
+CREATE TRIGGER set_users_handle BEFORE
+INSERT
+    ON users FOR EACH ROW EXECUTE FUNCTION set_user_handle();
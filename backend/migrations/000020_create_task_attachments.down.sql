@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS queue_task_attachments_blob_deletion ON task_attachments;
+
+DROP FUNCTION IF EXISTS queue_blob_deletion();
+
+ALTER TABLE
+    boards DROP COLUMN IF EXISTS attachment_quota_bytes;
+
+DROP TABLE IF EXISTS blob_deletions;
+
+DROP TABLE IF EXISTS task_attachments;
@@ -0,0 +1,51 @@
+-- Create task attachments table. The file itself lives in blob storage under
+-- storage_key; this row records its metadata.
+CREATE TABLE task_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    storage_key VARCHAR(512) NOT NULL UNIQUE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    sha256 CHAR(64) NOT NULL,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create blob deletions table. Attachments can disappear with their task or
+-- board, so deleting a row queues its blob here and a background worker
+-- removes it from storage.
+CREATE TABLE blob_deletions (
+    id BIGSERIAL PRIMARY KEY,
+    storage_key VARCHAR(512) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Boards can be given their own attachment quota; NULL uses the server's
+-- default
+ALTER TABLE
+    boards
+ADD
+    COLUMN attachment_quota_bytes BIGINT CHECK (attachment_quota_bytes >= 0);
+
+-- Create indexes
+CREATE INDEX idx_task_attachments_task_id ON task_attachments(task_id, created_at);
+
+CREATE INDEX idx_task_attachments_uploaded_by ON task_attachments(uploaded_by);
+
+CREATE INDEX idx_blob_deletions_due ON blob_deletions(next_attempt_at);
+
+CREATE
+OR REPLACE FUNCTION queue_blob_deletion() RETURNS TRIGGER AS '
+BEGIN
+    INSERT INTO blob_deletions (storage_key) VALUES (OLD.storage_key);
+    RETURN OLD;
+END;
+' LANGUAGE plpgsql;
+
+-- Create triggers
+CREATE TRIGGER queue_task_attachments_blob_deletion
+AFTER
+    DELETE ON task_attachments FOR EACH ROW EXECUTE FUNCTION queue_blob_deletion();
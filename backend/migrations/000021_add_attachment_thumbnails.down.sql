@@ -0,0 +1,18 @@
+CREATE
+OR REPLACE FUNCTION queue_blob_deletion() RETURNS TRIGGER AS '
+BEGIN
+    INSERT INTO blob_deletions (storage_key) VALUES (OLD.storage_key);
+    RETURN OLD;
+END;
+' LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_task_attachments_thumbnail_due;
+
+ALTER TABLE
+    task_attachments DROP COLUMN IF EXISTS thumbnail_next_attempt_at,
+    DROP COLUMN IF EXISTS thumbnail_attempts,
+    DROP COLUMN IF EXISTS thumbnail_height,
+    DROP COLUMN IF EXISTS thumbnail_width,
+    DROP COLUMN IF EXISTS thumbnail_content_type,
+    DROP COLUMN IF EXISTS thumbnail_key,
+    DROP COLUMN IF EXISTS thumbnail_status;
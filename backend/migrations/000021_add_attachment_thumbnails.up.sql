@@ -0,0 +1,46 @@
+-- Image attachments get a thumbnail, generated in the background and stored
+-- next to the original under thumbnail_key. Attachments that can't have one
+-- stay 'none'.
+ALTER TABLE
+    task_attachments
+ADD
+    COLUMN thumbnail_status VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (
+        thumbnail_status IN ('none', 'pending', 'ready', 'failed')
+    ),
+ADD
+    COLUMN thumbnail_key VARCHAR(512),
+ADD
+    COLUMN thumbnail_content_type VARCHAR(255),
+ADD
+    COLUMN thumbnail_width INTEGER,
+ADD
+    COLUMN thumbnail_height INTEGER,
+ADD
+    COLUMN thumbnail_attempts INTEGER NOT NULL DEFAULT 0,
+ADD
+    COLUMN thumbnail_next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Queue thumbnails for images uploaded before thumbnails existed
+UPDATE
+    task_attachments
+SET
+    thumbnail_status = 'pending'
+WHERE
+    content_type IN ('image/jpeg', 'image/png', 'image/gif');
+
+-- Create indexes
+CREATE INDEX idx_task_attachments_thumbnail_due ON task_attachments(thumbnail_next_attempt_at)
+WHERE
+    thumbnail_status = 'pending';
+
+-- Deleting an attachment queues its thumbnail's blob as well
+CREATE
+OR REPLACE FUNCTION queue_blob_deletion() RETURNS TRIGGER AS '
+BEGIN
+    INSERT INTO blob_deletions (storage_key) VALUES (OLD.storage_key);
+    IF OLD.thumbnail_key IS NOT NULL THEN
+        INSERT INTO blob_deletions (storage_key) VALUES (OLD.thumbnail_key);
+    END IF;
+    RETURN OLD;
+END;
+' LANGUAGE plpgsql;
@@ -0,0 +1,26 @@
+DROP TRIGGER IF EXISTS update_task_comments_search ON task_comments;
+
+DROP TRIGGER IF EXISTS update_acceptance_criteria_search ON acceptance_criteria;
+
+DROP TRIGGER IF EXISTS update_task_contents_search ON task_contents;
+
+DROP TRIGGER IF EXISTS update_tasks_search ON tasks;
+
+DROP TRIGGER IF EXISTS update_tasks_updated_at ON tasks;
+
+CREATE TRIGGER update_tasks_updated_at BEFORE
+UPDATE
+    ON tasks FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP FUNCTION IF EXISTS update_task_updated_at();
+
+DROP FUNCTION IF EXISTS update_task_search_from_task();
+
+DROP FUNCTION IF EXISTS update_task_search();
+
+DROP FUNCTION IF EXISTS task_search_vector(UUID, TEXT, TEXT);
+
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+
+ALTER TABLE
+    tasks DROP COLUMN IF EXISTS search_vector;
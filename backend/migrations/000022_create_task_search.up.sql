@@ -0,0 +1,119 @@
+-- Add a weighted tsvector of each task's text. It also covers the task's
+-- content, acceptance criteria and comments, which live in other tables, so
+-- it can't be a generated column; triggers rebuild it whenever any of them
+-- change.
+ALTER TABLE
+    tasks
+ADD
+    COLUMN search_vector TSVECTOR NOT NULL DEFAULT ''::TSVECTOR;
+
+-- Titles weigh most, then descriptions, then notes, implementation details and
+-- acceptance criteria, then comments
+CREATE
+OR REPLACE FUNCTION task_search_vector(p_task_id UUID, p_title TEXT, p_description TEXT) RETURNS TSVECTOR AS '
+    SELECT
+        setweight(to_tsvector(''english'', COALESCE(p_title, '''')), ''A'') ||
+        setweight(to_tsvector(''english'', COALESCE(p_description, '''')), ''B'') ||
+        setweight(to_tsvector(''english'', COALESCE(
+            (SELECT COALESCE(tc.notes, '''') || '' '' || COALESCE(tc.implementation_details, '''') FROM task_contents tc WHERE tc.task_id = p_task_id),
+            ''''
+        )), ''C'') ||
+        setweight(to_tsvector(''english'', COALESCE(
+            (SELECT string_agg(ac.description, '' '') FROM acceptance_criteria ac WHERE ac.task_id = p_task_id),
+            ''''
+        )), ''C'') ||
+        setweight(to_tsvector(''english'', COALESCE(
+            (SELECT string_agg(c.body, '' '') FROM task_comments c WHERE c.task_id = p_task_id),
+            ''''
+        )), ''D'')
+' LANGUAGE sql STABLE;
+
+CREATE
+OR REPLACE FUNCTION update_task_search_from_task() RETURNS TRIGGER AS '
+BEGIN
+    NEW.search_vector = task_search_vector(NEW.id, NEW.title, NEW.description);
+    RETURN NEW;
+END;
+' LANGUAGE plpgsql;
+
+CREATE
+OR REPLACE FUNCTION update_task_search() RETURNS TRIGGER AS '
+DECLARE
+    v_task_id UUID;
+BEGIN
+    IF TG_OP = ''DELETE'' THEN
+        v_task_id = OLD.task_id;
+    ELSE
+        v_task_id = NEW.task_id;
+    END IF;
+
+    -- Matches nothing for rows removed along with their task
+    UPDATE tasks
+    SET search_vector = task_search_vector(id, title, description)
+    WHERE id = v_task_id;
+
+    IF TG_OP = ''DELETE'' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+' LANGUAGE plpgsql;
+
+-- Reindexing a task for a new comment or criterion isn't an edit to the task,
+-- so it leaves updated_at alone
+CREATE
+OR REPLACE FUNCTION update_task_updated_at() RETURNS TRIGGER AS '
+BEGIN
+    IF NEW.search_vector IS DISTINCT FROM OLD.search_vector
+        AND to_jsonb(NEW) - ''search_vector'' - ''updated_at'' = to_jsonb(OLD) - ''search_vector'' - ''updated_at'' THEN
+        RETURN NEW;
+    END IF;
+
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+' LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS update_tasks_updated_at ON tasks;
+
+CREATE TRIGGER update_tasks_updated_at BEFORE
+UPDATE
+    ON tasks FOR EACH ROW EXECUTE FUNCTION update_task_updated_at();
+
+-- Index existing tasks
+UPDATE
+    tasks
+SET
+    search_vector = task_search_vector(id, title, description);
+
+-- Create indexes
+CREATE INDEX idx_tasks_search_vector ON tasks USING GIN(search_vector);
+
+-- Create triggers
+CREATE TRIGGER update_tasks_search BEFORE
+INSERT
+    OR
+UPDATE
+    OF title,
+    description ON tasks FOR EACH ROW EXECUTE FUNCTION update_task_search_from_task();
+
+CREATE TRIGGER update_task_contents_search
+AFTER
+INSERT
+    OR
+UPDATE
+    OR DELETE ON task_contents FOR EACH ROW EXECUTE FUNCTION update_task_search();
+
+CREATE TRIGGER update_acceptance_criteria_search
+AFTER
+INSERT
+    OR
+UPDATE
+    OR DELETE ON acceptance_criteria FOR EACH ROW EXECUTE FUNCTION update_task_search();
+
+CREATE TRIGGER update_task_comments_search
+AFTER
+INSERT
+    OR
+UPDATE
+    OR DELETE ON task_comments FOR EACH ROW EXECUTE FUNCTION update_task_search();
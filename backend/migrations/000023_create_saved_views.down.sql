@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_saved_views_updated_at ON saved_views;
+
+DROP TABLE IF EXISTS saved_view_pins;
+
+DROP TABLE IF EXISTS saved_views;
@@ -0,0 +1,42 @@
+-- Create saved views table. A view is a named task query with a sort and the
+-- columns to show. Private views are seen only by their owner; board views
+-- are shared with everyone who can see the board.
+CREATE TABLE saved_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    board_id UUID REFERENCES boards(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    sort VARCHAR(50) NOT NULL DEFAULT '',
+    columns JSONB NOT NULL DEFAULT '[]',
+    visibility VARCHAR(20) NOT NULL DEFAULT 'private' CHECK (visibility IN ('private', 'board')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT saved_views_board_visibility CHECK (
+        visibility = 'private'
+        OR board_id IS NOT NULL
+    )
+);
+
+-- Create saved view pins table. Each user pins views for themselves,
+-- including views shared by others.
+CREATE TABLE saved_view_pins (
+    view_id UUID NOT NULL REFERENCES saved_views(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (view_id, user_id)
+);
+
+-- Create indexes
+CREATE INDEX idx_saved_views_owner_id ON saved_views(owner_id);
+
+CREATE INDEX idx_saved_views_board_id ON saved_views(board_id)
+WHERE
+    visibility = 'board';
+
+CREATE INDEX idx_saved_view_pins_user_id ON saved_view_pins(user_id);
+
+-- Create triggers
+CREATE TRIGGER update_saved_views_updated_at BEFORE
+UPDATE
+    ON saved_views FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1 @@
+-- Merged statuses can't be told apart again, so the codes stay as they are
@@ -0,0 +1,125 @@
+-- Use one set of global status codes: backlog, in-progress, review and done,
+-- the ones seeded by 000003 and used by the frontend. Databases seeded from
+-- db/seed.sql have in_progress and in_review instead, and todo and blocked
+-- as well, which the frontend doesn't know. Statuses with the old codes are
+-- renamed where the new code is free, and otherwise merged into the status
+-- with the new code, which has the same category.
+UPDATE
+    task_statuses
+SET
+    code = 'in-progress'
+WHERE
+    board_id IS NULL
+    AND code = 'in_progress'
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            task_statuses
+        WHERE
+            board_id IS NULL
+            AND code = 'in-progress'
+    );
+
+UPDATE
+    task_statuses
+SET
+    code = 'review'
+WHERE
+    board_id IS NULL
+    AND code = 'in_review'
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            task_statuses
+        WHERE
+            board_id IS NULL
+            AND code = 'review'
+    );
+
+CREATE TEMPORARY TABLE status_merges AS
+SELECT
+    old_status.id AS old_id,
+    new_status.id AS new_id
+FROM
+    (
+        VALUES
+            ('in_progress', 'in-progress'),
+            ('in_review', 'review'),
+            ('todo', 'backlog'),
+            ('blocked', 'in-progress')
+    ) AS codes(old_code, new_code)
+    JOIN task_statuses old_status ON old_status.board_id IS NULL
+    AND old_status.code = codes.old_code
+    JOIN task_statuses new_status ON new_status.board_id IS NULL
+    AND new_status.code = codes.new_code;
+
+UPDATE
+    tasks t
+SET
+    status_id = m.new_id
+FROM
+    status_merges m
+WHERE
+    t.status_id = m.old_id;
+
+UPDATE
+    done_gate_overrides o
+SET
+    from_status_id = m.new_id
+FROM
+    status_merges m
+WHERE
+    o.from_status_id = m.old_id;
+
+UPDATE
+    done_gate_overrides o
+SET
+    to_status_id = m.new_id
+FROM
+    status_merges m
+WHERE
+    o.to_status_id = m.old_id;
+
+-- WIP limits and transitions on the old statuses carry over unless the new
+-- status already has its own; the rest go when the old statuses are deleted
+INSERT INTO
+    board_wip_limits (board_id, status_id, limit_count, mode)
+SELECT
+    w.board_id,
+    m.new_id,
+    w.limit_count,
+    w.mode
+FROM
+    board_wip_limits w
+    JOIN status_merges m ON m.old_id = w.status_id ON CONFLICT DO NOTHING;
+
+INSERT INTO
+    board_status_transitions (board_id, from_status_id, to_status_id)
+SELECT
+    t.board_id,
+    COALESCE(mf.new_id, t.from_status_id),
+    COALESCE(mt.new_id, t.to_status_id)
+FROM
+    board_status_transitions t
+    LEFT JOIN status_merges mf ON mf.old_id = t.from_status_id
+    LEFT JOIN status_merges mt ON mt.old_id = t.to_status_id
+WHERE
+    (
+        mf.old_id IS NOT NULL
+        OR mt.old_id IS NOT NULL
+    )
+    AND COALESCE(mf.new_id, t.from_status_id) <> COALESCE(mt.new_id, t.to_status_id) ON CONFLICT DO NOTHING;
+
+DELETE FROM
+    task_statuses
+WHERE
+    id IN (
+        SELECT
+            old_id
+        FROM
+            status_merges
+    );
+
+DROP TABLE status_merges;
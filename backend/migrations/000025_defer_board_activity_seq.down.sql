@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS assign_activity_logs_board_seq ON activity_logs;
+
+DROP FUNCTION IF EXISTS assign_board_activity_seq();
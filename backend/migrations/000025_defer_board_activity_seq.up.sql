@@ -0,0 +1,34 @@
+-- Number board activity as the writing transaction commits instead of when
+-- the event is written. Taking a number locks the board's counter row until
+-- commit; a deferred trigger keeps that lock to the commit itself rather than
+-- the rest of the transaction, and numbers still become visible in commit
+-- order.
+CREATE
+OR REPLACE FUNCTION assign_board_activity_seq() RETURNS TRIGGER AS '
+DECLARE
+    v_seq BIGINT;
+BEGIN
+    -- Skip events whose board was deleted later in the same transaction
+    IF NOT EXISTS (
+        SELECT 1 FROM activity_logs WHERE id = NEW.id AND board_id = NEW.board_id
+    ) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO board_activity_sequences (board_id, last_seq)
+    VALUES (NEW.board_id, 1)
+    ON CONFLICT (board_id) DO UPDATE
+    SET last_seq = board_activity_sequences.last_seq + 1
+    RETURNING last_seq INTO v_seq;
+
+    UPDATE activity_logs SET board_seq = v_seq WHERE id = NEW.id;
+    RETURN NULL;
+END;
+' LANGUAGE plpgsql;
+
+-- Create triggers
+CREATE CONSTRAINT TRIGGER assign_activity_logs_board_seq
+AFTER
+INSERT
+    ON activity_logs DEFERRABLE INITIALLY DEFERRED FOR EACH ROW
+    WHEN (NEW.board_id IS NOT NULL) EXECUTE FUNCTION assign_board_activity_seq();